@@ -0,0 +1,226 @@
+package ci
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	"github.com/gabyx/githooks/githooks/runner"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+)
+
+type ciOptions struct {
+	FromRef   string
+	ToRef     string
+	PerCommit bool
+	HookNames []string
+}
+
+// ciRun is one run of a hook over a set of changed files.
+type ciRun struct {
+	HookName string
+	Commit   string // The commit for a per-commit run, otherwise empty.
+	Files    string // The changed files delimited by `\x00`.
+}
+
+func (r *ciRun) title() string {
+	if strs.IsEmpty(r.Commit) {
+		return strs.Fmt("'%s'", r.HookName)
+	}
+
+	return strs.Fmt("'%s' [commit: '%s']", r.HookName, r.Commit)
+}
+
+// getCommits gets all commits in `(fromRef, toRef]` in topological order,
+// oldest first.
+func getCommits(gitx *git.Context, fromRef string, toRef string) ([]string, error) {
+	out, err := gitx.Get("rev-list", "--reverse", "--topo-order", fromRef+".."+toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return strs.Filter(strs.SplitLines(out), strs.IsNotEmpty), nil
+}
+
+func getRuns(ctx *ccm.CmdContext, opts *ciOptions) (runs []ciRun) {
+	if !opts.PerCommit {
+		files, err := hooks.GetChangedFiles(ctx.GitX, opts.FromRef, opts.ToRef)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not get changed files between '%s' and '%s'.",
+			opts.FromRef, opts.ToRef)
+
+		for _, hookName := range opts.HookNames {
+			runs = append(runs, ciRun{HookName: hookName, Files: files})
+		}
+
+		return
+	}
+
+	commits, err := getCommits(ctx.GitX, opts.FromRef, opts.ToRef)
+	ctx.Log.AssertNoErrorPanicF(err, "Could not get commits between '%s' and '%s'.",
+		opts.FromRef, opts.ToRef)
+
+	for _, commit := range commits {
+		files, err := hooks.GetChangedFilesOfCommit(ctx.GitX, commit)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not get changed files of commit '%s'.", commit)
+
+		for _, hookName := range opts.HookNames {
+			runs = append(runs, ciRun{HookName: hookName, Commit: commit, Files: files})
+		}
+	}
+
+	return
+}
+
+// getCommitMsgFile writes the message of `commit` to a temporary file
+// which is passed to `commit-msg` hooks.
+func getCommitMsgFile(ctx *ccm.CmdContext, commit string) (file string, cleanUp func()) {
+	msg, err := ctx.GitX.Get("log", "-1", "--format=%B", commit)
+	ctx.Log.AssertNoErrorPanicF(err, "Could not get commit message of '%s'.", commit)
+
+	f, err := os.CreateTemp("", "githooks-ci-commit-msg-*")
+	ctx.Log.AssertNoErrorPanic(err, "Could not create temporary file.")
+	defer func() { _ = f.Close() }()
+
+	_, err = f.WriteString(msg + "\n")
+	ctx.Log.AssertNoErrorPanicF(err, "Could not write commit message to '%s'.", f.Name())
+
+	return f.Name(), func() { _ = os.Remove(f.Name()) }
+}
+
+// execute runs all hooks for `run` and reports
+// if all hooks succeeded.
+func execute(ctx *ccm.CmdContext, repoDir string, hooksDir string, run *ciRun) (success bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		} else if _, ok := r.(cm.GithooksFailure); !ok {
+			panic(r)
+		}
+
+		// The failure has already been logged.
+		success = false
+	}()
+
+	var args []string
+	switch run.HookName {
+	case "commit-msg":
+		file, cleanUp := getCommitMsgFile(ctx, run.Commit)
+		defer cleanUp()
+
+		args = []string{file}
+	case "prepare-commit-msg":
+		file, cleanUp := getCommitMsgFile(ctx, run.Commit)
+		defer cleanUp()
+
+		args = []string{file, "commit", run.Commit}
+	}
+
+	files := run.Files
+	ctx.Log.InfoF("Running hooks %s for '%v' changed file(s).",
+		run.title(), len(strs.Filter(strings.Split(files, "\x00"), strs.IsNotEmpty)))
+
+	runner.Run(ctx.Log, repoDir,
+		&runner.Options{
+			HookPath:           path.Join(hooksDir, run.HookName),
+			Args:               args,
+			Simulate:           true,
+			Stdin:              []byte{},
+			StagedFiles:        &files,
+			NonInteractive:     true,
+			SkipUntrustedHooks: true})
+
+	return true
+}
+
+func runCI(ctx *ccm.CmdContext, opts *ciOptions) error {
+	for _, hookName := range opts.HookNames {
+		ctx.Log.PanicIfF(!strs.Includes(hooks.StagedFilesHookNames[:], hookName),
+			"Hook name '%s' is not supported in CI mode.\nSupported hooks are: '%q'.",
+			hookName, hooks.StagedFilesHookNames)
+
+		ctx.Log.PanicIfF(hookName != "pre-commit" && !opts.PerCommit,
+			"Hook '%s' can only be run with '--per-commit'.", hookName)
+	}
+
+	repoDir, gitDir, _ := ccm.AssertRepoRoot(ctx)
+	hooksDir := ccm.GetHooksDir(ctx, repoDir, gitDir)
+
+	runs := getRuns(ctx, opts)
+	if len(runs) == 0 {
+		ctx.Log.InfoF("No commits between '%s' and '%s'.", opts.FromRef, opts.ToRef)
+
+		return nil
+	}
+
+	var failed []string
+	for i := range runs {
+		if !execute(ctx, repoDir, hooksDir, &runs[i]) {
+			failed = append(failed, runs[i].title())
+		}
+	}
+
+	if len(failed) != 0 {
+		return ctx.NewCmdExit(1, "Hooks failed for:\n%s",
+			strings.Join(strs.Map(failed, func(s string) string {
+				return strs.Fmt("%s %s", cm.ListItemLiteral, s)
+			}), "\n"))
+	}
+
+	ctx.Log.InfoF("All hooks succeeded for '%v' run(s).", len(runs))
+
+	return nil
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	opts := ciOptions{}
+
+	ciCmd := &cobra.Command{
+		Use:   "ci [flags]",
+		Short: "Runs hooks over a range of commits (CI mode).",
+		Long: `Runs hooks over the commit range '(from, to]', e.g. in CI.
+
+The files changed in the range, i.e. since the merge base of the two
+commits (or with '--per-commit' the files changed in each commit) are exported as 'STAGED_FILES'
+(or 'STAGED_FILES_FILE') and all hooks of the given types
+are run in the current worktree.
+
+The commits are not checked out: the hooks see the files of the
+working tree (usually at 'to'), also with '--per-commit'.
+
+The hooks run non-interactively and active, untrusted hooks are skipped.
+No update checks and shared hooks updates are performed.
+All runs are executed and the command fails if any hook failed.
+
+The hooks 'prepare-commit-msg' and 'commit-msg' are run with a file
+containing the message of each commit and need '--per-commit'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCI(ctx, &opts)
+		},
+	}
+
+	ciCmd.Flags().StringVar(&opts.FromRef, "from", "",
+		"The base commit (exclusive).")
+	ciCmd.Flags().StringVar(&opts.ToRef, "to", git.HEAD,
+		"The head commit (inclusive).")
+	ciCmd.Flags().BoolVar(&opts.PerCommit, "per-commit", false,
+		"Run the hooks for each commit with its own changed files\n"+
+			"(the hooks see the working tree, not the tree of each commit).")
+	ciCmd.Flags().StringSliceVar(&opts.HookNames, "hook", []string{"pre-commit"},
+		"The hook types to run.")
+
+	_ = ciCmd.MarkFlagRequired("from")
+
+	ciCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, ciCmd)
+}
@@ -1,9 +1,12 @@
 package ccm
 
 import (
+	"path"
+	"path/filepath"
 	"strings"
 
 	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
 	"github.com/gabyx/githooks/githooks/hooks"
 	strs "github.com/gabyx/githooks/githooks/strings"
 
@@ -44,9 +47,25 @@ func AssertRepoRoot(ctx *CmdContext) (repoRoot, gitDir, gitDirWorktree string) {
 	return
 }
 
+// GetHooksDir gets the directory where Git invokes the hooks from
+// for the repository `repoDir` with Git directory `gitDir`.
+func GetHooksDir(ctx *CmdContext, repoDir string, gitDir string) string {
+	hooksDir, exists := ctx.GitX.LookupConfig(git.GitCKCoreHooksPath, git.Traverse)
+	if !exists || strs.IsEmpty(hooksDir) {
+		return path.Join(gitDir, "hooks")
+	}
+
+	hooksDir = filepath.ToSlash(hooksDir)
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = path.Join(repoDir, hooksDir)
+	}
+
+	return hooksDir
+}
+
 // GetFormattedHookList gets a list of formatted hook names.
 func GetFormattedHookList(indent string) string {
-	return strings.Join(strs.Map(hooks.ManagedHookNames,
+	return strings.Join(strs.Map(hooks.SupportedHookNames,
 		func(s string) string {
 			return strs.Fmt("%s%s '%s'", indent, cm.ListItemLiteral, s)
 		}),
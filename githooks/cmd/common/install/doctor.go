@@ -0,0 +1,340 @@
+package install
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// IssueType is the type of a detected drift of an installation.
+type IssueType int
+type issueType struct {
+	MissingRepo       IssueType
+	MissingRunWrapper IssueType
+	StaleRunWrapper   IssueType
+	ForeignHook       IssueType
+	ReplacedLFSHook   IssueType
+	MissingLink       IssueType
+	WrongLink         IssueType
+	BrokenSharedClone IssueType
+}
+
+// IssueTypeV enumerates all types of detected drifts.
+var IssueTypeV = &issueType{ // nolint: mnd
+	MissingRepo:       0,
+	MissingRunWrapper: 1,
+	StaleRunWrapper:   2,
+	ForeignHook:       3,
+	ReplacedLFSHook:   4,
+	MissingLink:       5,
+	WrongLink:         6,
+	BrokenSharedClone: 7,
+}
+
+// Name gets the name of the issue type.
+func (i IssueType) Name() string {
+	switch i {
+	case IssueTypeV.MissingRepo:
+		return "missing repository"
+	case IssueTypeV.MissingRunWrapper:
+		return "missing run-wrapper"
+	case IssueTypeV.StaleRunWrapper:
+		return "stale run-wrapper"
+	case IssueTypeV.ForeignHook:
+		return "foreign hook"
+	case IssueTypeV.ReplacedLFSHook:
+		return "replaced by LFS hook"
+	case IssueTypeV.MissingLink:
+		return "missing run-wrapper link"
+	case IssueTypeV.WrongLink:
+		return "wrong run-wrapper link"
+	case IssueTypeV.BrokenSharedClone:
+		return "broken shared clone"
+	default:
+		return "unknown"
+	}
+}
+
+// Issue is a detected drift of an installation.
+type Issue struct {
+	Type IssueType
+	Path string // The affected file or directory.
+
+	// The affected shared repository for `BrokenSharedClone`.
+	SharedRepo *hooks.SharedRepo
+	SharedType hooks.SharedHookType
+}
+
+// Diagnosis contains all detected issues of a hooks directory or a repository.
+type Diagnosis struct {
+	// The Git directory of the repository or
+	// empty for the global hooks directory.
+	GitDir   string
+	HooksDir string
+
+	Issues []Issue
+}
+
+// IsHealthy tells if no issues are detected.
+func (d *Diagnosis) IsHealthy() bool {
+	return len(d.Issues) == 0
+}
+
+// diagnoseRunWrappers checks that all run-wrappers for `hookNames` in `hooksDir`
+// are existing and up to date.
+func diagnoseRunWrappers(
+	hooksDir string,
+	hookNames []string,
+	lfsHooksCache hooks.LFSHooksCache) (issues []Issue, err error) {
+	// Report in a stable order.
+	hookNames = append([]string{}, hookNames...)
+	sort.Strings(hookNames)
+
+	for _, hookName := range hookNames {
+		hookFile := path.Join(hooksDir, hookName)
+
+		if !cm.IsFile(hookFile) {
+			issues = append(issues, Issue{Type: IssueTypeV.MissingRunWrapper, Path: hookFile})
+
+			continue
+		}
+
+		isWrapper, e := hooks.IsRunWrapper(hookFile)
+		if e != nil {
+			err = cm.CombineErrors(err, e)
+
+			continue
+		}
+
+		if isWrapper {
+			upToDate, e := hooks.IsRunWrapperUpToDate(hookFile)
+			if e != nil {
+				err = cm.CombineErrors(err, e)
+			} else if !upToDate {
+				issues = append(issues, Issue{Type: IssueTypeV.StaleRunWrapper, Path: hookFile})
+			}
+
+			continue
+		}
+
+		isLFS := false
+		if lfsHooksCache != nil {
+			isLFS, e = lfsHooksCache.IsIdentical(hookFile)
+			err = cm.CombineErrors(err, e)
+		}
+
+		if isLFS {
+			issues = append(issues, Issue{Type: IssueTypeV.ReplacedLFSHook, Path: hookFile})
+		} else {
+			issues = append(issues, Issue{Type: IssueTypeV.ForeignHook, Path: hookFile})
+		}
+	}
+
+	return
+}
+
+// diagnoseSharedClones checks that all cloned shared repositories are valid.
+func diagnoseSharedClones(
+	sharedRepos []hooks.SharedRepo,
+	sharedType hooks.SharedHookType) (issues []Issue) {
+	for i := range sharedRepos {
+		repo := &sharedRepos[i]
+		if !repo.IsCloned || !cm.IsDirectory(repo.RepositoryDir) {
+			// Not yet cloned clones are pending and not broken.
+			continue
+		}
+
+		if !git.NewCtxAt(repo.RepositoryDir).IsGitRepo() ||
+			!repo.IsCloneValid() {
+			issues = append(issues,
+				Issue{
+					Type:       IssueTypeV.BrokenSharedClone,
+					Path:       repo.RepositoryDir,
+					SharedRepo: repo,
+					SharedType: sharedType})
+		}
+	}
+
+	return
+}
+
+// DiagnoseGlobal checks the global hooks directory maintained by Githooks and
+// all global shared repositories.
+func DiagnoseGlobal(
+	gitx *git.Context,
+	installDir string,
+	lfsHooksCache hooks.LFSHooksCache) (diag Diagnosis, err error) {
+	diag.HooksDir = gitx.GetConfig(hooks.GitCKPathForUseCoreHooksPath, git.GlobalScope)
+
+	if strs.IsNotEmpty(diag.HooksDir) {
+		hookNames, _, _, e := hooks.GetMaintainedHooks(gitx, git.GlobalScope)
+		err = cm.CombineErrors(err, e)
+
+		issues, e := diagnoseRunWrappers(diag.HooksDir, hookNames, lfsHooksCache)
+		err = cm.CombineErrors(err, e)
+		diag.Issues = append(diag.Issues, issues...)
+	}
+
+	shared, e := hooks.LoadConfigSharedHooks(installDir, gitx, git.GlobalScope)
+	err = cm.CombineErrors(err, e)
+	diag.Issues = append(diag.Issues, diagnoseSharedClones(shared, hooks.SharedHookTypeV.Global)...)
+
+	return
+}
+
+// DiagnoseRepo checks the installed run-wrappers (or the run-wrapper link)
+// and the shared repositories of the repository in `gitDir`.
+func DiagnoseRepo(
+	gitx *git.Context,
+	installDir string,
+	gitDir string,
+	lfsHooksCache hooks.LFSHooksCache) (diag Diagnosis, err error) {
+	diag.GitDir = gitDir
+	diag.HooksDir = path.Join(gitDir, "hooks")
+
+	if !cm.IsDirectory(gitDir) {
+		diag.Issues = append(diag.Issues, Issue{Type: IssueTypeV.MissingRepo, Path: gitDir})
+
+		return
+	}
+
+	repoGitx := git.NewCtxAt(gitDir)
+	isBare := repoGitx.IsBareRepo()
+
+	// Decide if run-wrappers or the link are used,
+	// the same way as `InstallIntoRepo`.
+	hookNames, _, isSet, e := hooks.GetMaintainedHooks(repoGitx, git.LocalScope)
+	err = cm.CombineErrors(err, e)
+	hasMarker, _ := cm.IsPathExisting(path.Join(diag.HooksDir, hooks.RunWrapperMarkerFileName))
+
+	_, installMode := GetInstallMode(gitx)
+
+	switch {
+	case installMode == InstallModeTypeV.Centralized:
+		// The global `core.hooksPath` is used.
+	case hasMarker || isSet:
+		if isBare {
+			hookNames = strs.Filter(hookNames, hooks.IsServerHookName)
+			lfsHooksCache = nil
+		}
+
+		issues, e := diagnoseRunWrappers(diag.HooksDir, hookNames, lfsHooksCache)
+		err = cm.CombineErrors(err, e)
+		diag.Issues = append(diag.Issues, issues...)
+	default:
+		pathToUse := gitx.GetConfig(hooks.GitCKPathForUseCoreHooksPath, git.GlobalScope)
+		lcp, lcpSet := repoGitx.LookupConfig(git.GitCKCoreHooksPath, git.LocalScope)
+
+		if !lcpSet {
+			diag.Issues = append(diag.Issues, Issue{Type: IssueTypeV.MissingLink, Path: diag.HooksDir})
+		} else if lcp != pathToUse {
+			diag.Issues = append(diag.Issues, Issue{Type: IssueTypeV.WrongLink, Path: lcp})
+		}
+	}
+
+	shared, e := hooks.LoadConfigSharedHooks(installDir, repoGitx, git.LocalScope)
+	err = cm.CombineErrors(err, e)
+	diag.Issues = append(diag.Issues, diagnoseSharedClones(shared, hooks.SharedHookTypeV.Local)...)
+
+	if !isBare && path.Base(gitDir) == ".git" {
+		shared, e = hooks.LoadRepoSharedHooks(installDir, path.Dir(gitDir))
+		err = cm.CombineErrors(err, e)
+		diag.Issues = append(diag.Issues, diagnoseSharedClones(shared, hooks.SharedHookTypeV.Repo)...)
+	}
+
+	return
+}
+
+// fixSharedClones removes broken shared clones and clones them again.
+func fixSharedClones(log cm.ILogContext, diag *Diagnosis) (fixed bool) {
+	fixed = true
+
+	for i := range diag.Issues {
+		issue := &diag.Issues[i]
+		if issue.Type != IssueTypeV.BrokenSharedClone {
+			continue
+		}
+
+		err := os.RemoveAll(issue.SharedRepo.RepositoryDir)
+		if !log.AssertNoErrorF(err, "Could not remove broken shared clone '%s'.", issue.Path) {
+			fixed = false
+
+			continue
+		}
+
+		_, err = hooks.UpdateSharedHooks(log, []hooks.SharedRepo{*issue.SharedRepo}, issue.SharedType, nil)
+		fixed = fixed && err == nil
+	}
+
+	return
+}
+
+// hasIssue tells if any issue in `diag` is one of `types`.
+func (d *Diagnosis) hasIssue(types ...IssueType) bool {
+	for i := range d.Issues {
+		for _, t := range types {
+			if d.Issues[i].Type == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FixGlobal repairs all issues found by `DiagnoseGlobal`.
+func FixGlobal(
+	log cm.ILogContext,
+	gitx *git.Context,
+	diag *Diagnosis,
+	lfsHooksCache hooks.LFSHooksCache,
+	uiSettings *UISettings) bool {
+	fixed := true
+
+	if diag.hasIssue(
+		IssueTypeV.MissingRunWrapper,
+		IssueTypeV.StaleRunWrapper,
+		IssueTypeV.ForeignHook,
+		IssueTypeV.ReplacedLFSHook) {
+		hookNames, _, _, err := hooks.GetMaintainedHooks(gitx, git.GlobalScope)
+		log.AssertNoErrorF(err, "Could not get maintained hooks.")
+
+		_, err = hooks.InstallRunWrappers(
+			diag.HooksDir, hookNames,
+			nil,
+			GetHookDisableCallback(log, gitx, true, uiSettings),
+			lfsHooksCache,
+			log)
+		fixed = log.AssertNoErrorF(err, "Could not install run-wrappers into '%s'.", diag.HooksDir)
+	}
+
+	return fixSharedClones(log, diag) && fixed
+}
+
+// FixRepo repairs all issues found by `DiagnoseRepo` except `MissingRepo`,
+// by reinstalling the run-wrappers (or the run-wrapper link) and
+// the broken shared clones.
+func FixRepo(
+	log cm.ILogContext,
+	diag *Diagnosis,
+	lfsHooksCache hooks.LFSHooksCache,
+	uiSettings *UISettings) bool {
+	fixed := true
+
+	if diag.hasIssue(
+		IssueTypeV.MissingRunWrapper,
+		IssueTypeV.StaleRunWrapper,
+		IssueTypeV.ForeignHook,
+		IssueTypeV.ReplacedLFSHook,
+		IssueTypeV.MissingLink,
+		IssueTypeV.WrongLink) {
+		fixed = InstallIntoRepo(log, diag.GitDir, lfsHooksCache, nil, true, false, true, uiSettings)
+	}
+
+	return fixSharedClones(log, diag) && fixed
+}
@@ -0,0 +1,84 @@
+package install
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getIssueTypes(diag *Diagnosis) (types []IssueType) {
+	for i := range diag.Issues {
+		types = append(types, diag.Issues[i].Type)
+	}
+
+	return
+}
+
+func TestDoctor(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+
+	installDir := t.TempDir()
+	gitx := git.NewCtx()
+
+	repoDir := t.TempDir()
+	require.NoError(t, git.NewCtxAt(repoDir).Check("init", "-q"))
+	gitDir := path.Join(repoDir, ".git")
+	hooksDir := path.Join(gitDir, "hooks")
+	repoGitx := git.NewCtxAt(gitDir)
+
+	// Run-wrapper mode.
+	require.NoError(t, repoGitx.SetConfig(hooks.GitCKMaintainedHooks, "!all, pre-commit, pre-push", git.LocalScope))
+	require.NoError(t, hooks.WriteRunWrapper(path.Join(hooksDir, "pre-commit")))
+	require.NoError(t, os.WriteFile(path.Join(hooksDir, "pre-push"), []byte("#!/bin/sh\n"), 0700)) //nolint:mnd
+
+	diag, err := DiagnoseRepo(gitx, installDir, gitDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []IssueType{IssueTypeV.ForeignHook}, getIssueTypes(&diag))
+
+	// Make the run-wrapper stale.
+	wrapper := path.Join(hooksDir, "pre-commit")
+	require.NoError(t, os.WriteFile(wrapper,
+		[]byte("#!/bin/sh\n# https://github.com/gabyx/githooks\n"), 0700)) //nolint:mnd
+
+	diag, err = DiagnoseRepo(gitx, installDir, gitDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []IssueType{IssueTypeV.StaleRunWrapper, IssueTypeV.ForeignHook}, getIssueTypes(&diag))
+
+	assert.True(t, FixRepo(log, &diag, nil, &UISettings{}))
+	assert.FileExists(t, path.Join(hooksDir, hooks.GetHookReplacementFileName("pre-push")))
+
+	diag, err = DiagnoseRepo(gitx, installDir, gitDir, nil)
+	require.NoError(t, err)
+	assert.True(t, diag.IsHealthy())
+
+	// Link mode.
+	require.NoError(t, repoGitx.UnsetConfig(hooks.GitCKMaintainedHooks, git.LocalScope))
+	_, err = hooks.UninstallRunWrappers(hooksDir, nil)
+	require.NoError(t, err)
+	require.NoError(t, gitx.SetConfig(hooks.GitCKPathForUseCoreHooksPath, t.TempDir(), git.GlobalScope))
+
+	diag, err = DiagnoseRepo(gitx, installDir, gitDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []IssueType{IssueTypeV.MissingLink}, getIssueTypes(&diag))
+
+	assert.True(t, FixRepo(log, &diag, nil, &UISettings{}))
+	diag, err = DiagnoseRepo(gitx, installDir, gitDir, nil)
+	require.NoError(t, err)
+	assert.True(t, diag.IsHealthy())
+
+	// Missing repository.
+	diag, err = DiagnoseRepo(gitx, installDir, path.Join(installDir, "missing", ".git"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []IssueType{IssueTypeV.MissingRepo}, getIssueTypes(&diag))
+}
@@ -0,0 +1,273 @@
+package install
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ProfileVersion is the current version of the install profile format.
+const ProfileVersion = 1
+
+// Profile is an install profile which describes a whole Githooks installation
+// (the installer arguments and the global settings the runner reads)
+// to get identical setups on many machines.
+type Profile struct {
+	Version int `yaml:"version"`
+
+	Installer ProfileInstaller `yaml:"installer"`
+	Settings  ProfileSettings  `yaml:"settings"`
+}
+
+// ProfileInstaller are the installer arguments in an install profile.
+// Unset values are not applied. Command line flags take precedence.
+// Relative paths are relative to the profile file.
+type ProfileInstaller struct {
+	NonInteractive          *bool `yaml:"nonInteractive,omitempty"`
+	SkipInstallIntoExisting *bool `yaml:"skipInstallIntoExisting,omitempty"`
+
+	MaintainedHooks []string `yaml:"maintainedHooks,omitempty"`
+	Centralized     *bool    `yaml:"centralized,omitempty"`
+
+	InstallPrefix          string `yaml:"prefix,omitempty"`
+	HooksDir               string `yaml:"hooksDir,omitempty"`
+	HooksDirUseTemplateDir *bool  `yaml:"hooksDirUseTemplateDir,omitempty"`
+
+	CloneURL       string `yaml:"cloneURL,omitempty"`
+	CloneBranch    string `yaml:"cloneBranch,omitempty"`
+	DeployAPI      string `yaml:"deployAPI,omitempty"`
+	DeploySettings string `yaml:"deploySettings,omitempty"`
+
+	BuildFromSource *bool    `yaml:"buildFromSource,omitempty"`
+	BuildTags       []string `yaml:"buildTags,omitempty"`
+	UsePreRelease   *bool    `yaml:"usePreRelease,omitempty"`
+}
+
+// ProfileSettings are the global Git config settings in an install profile.
+// Unset values are not applied.
+type ProfileSettings struct {
+	SharedRepos                   []string `yaml:"sharedRepos,omitempty"`
+	AutoUpdateSharedHooksDisabled *bool    `yaml:"autoUpdateSharedHooksDisabled,omitempty"`
+	SkipNonExistingSharedHooks    *bool    `yaml:"skipNonExistingSharedHooks,omitempty"`
+
+	NumThreads *int `yaml:"numThreads,omitempty"`
+
+	ContainerizedHooksEnabled *bool  `yaml:"containerizedHooksEnabled,omitempty"`
+	ContainerManager          string `yaml:"containerManager,omitempty"`
+
+	// The trust policy.
+	SkipUntrustedHooks     *bool `yaml:"skipUntrustedHooks,omitempty"`
+	RunnerIsNonInteractive *bool `yaml:"runnerIsNonInteractive,omitempty"`
+
+	ExportStagedFilesAsFile *bool `yaml:"exportStagedFilesAsFile,omitempty"`
+
+	UpdateCheckEnabled *bool  `yaml:"updateCheckEnabled,omitempty"`
+	UpdateChannel      string `yaml:"updateChannel,omitempty"`
+}
+
+// LoadProfile loads an install profile from `file`.
+func LoadProfile(file string) (profile Profile, err error) {
+	if err = cm.LoadYAML(file, &profile); err != nil {
+		return
+	}
+
+	if profile.Version == 0 || profile.Version > ProfileVersion {
+		err = cm.ErrorF("Install profile '%s' has unsupported version '%v'.", file, profile.Version)
+
+		return
+	}
+
+	// Make relative paths relative to the profile.
+	dir := filepath.ToSlash(filepath.Dir(file))
+	makeAbs := func(p *string) {
+		if strs.IsNotEmpty(*p) && !filepath.IsAbs(*p) && !strings.HasPrefix(*p, "~") {
+			*p = path.Join(dir, *p)
+		}
+	}
+	makeAbs(&profile.Installer.HooksDir)
+	makeAbs(&profile.Installer.DeploySettings)
+
+	return
+}
+
+// StoreProfile stores an install profile to `file`.
+func StoreProfile(file string, profile *Profile) error {
+	profile.Version = ProfileVersion
+
+	return cm.StoreYAML(file, profile)
+}
+
+// FormatProfile formats the install profile as YAML.
+func FormatProfile(profile *Profile) ([]byte, error) {
+	profile.Version = ProfileVersion
+
+	return yaml.Marshal(profile)
+}
+
+// InstallerArgs returns the set installer arguments keyed by the
+// installer's config keys.
+func (p *ProfileInstaller) InstallerArgs() map[string]any {
+	args := make(map[string]any)
+
+	setBool := func(key string, v *bool) {
+		if v != nil {
+			args[key] = *v
+		}
+	}
+	setString := func(key string, v string) {
+		if strs.IsNotEmpty(v) {
+			args[key] = v
+		}
+	}
+	setList := func(key string, v []string) {
+		if len(v) != 0 {
+			args[key] = v
+		}
+	}
+
+	setBool("nonInteractive", p.NonInteractive)
+	setBool("skipInstallIntoExisting", p.SkipInstallIntoExisting)
+	setList("maintainedHooks", p.MaintainedHooks)
+	setBool("centralized", p.Centralized)
+	setString("installPrefix", p.InstallPrefix)
+	setString("hooksDir", p.HooksDir)
+	setBool("hooksDirUseTemplateDir", p.HooksDirUseTemplateDir)
+	setString("cloneURL", p.CloneURL)
+	setString("cloneBranch", p.CloneBranch)
+	setString("deployAPI", p.DeployAPI)
+	setString("deploySettings", p.DeploySettings)
+	setBool("buildFromSource", p.BuildFromSource)
+	setList("buildTags", p.BuildTags)
+	setBool("usePreRelease", p.UsePreRelease)
+
+	return args
+}
+
+// ApplyProfileSettings applies all set settings of the profile
+// to the global Git config.
+func ApplyProfileSettings(log cm.ILogContext, gitx *git.Context, s *ProfileSettings, dryRun bool) error {
+	var err error
+
+	set := func(key string, value string) {
+		if dryRun {
+			log.InfoF("[dry run] Would set global Git config '%s' to '%s'.", key, value)
+
+			return
+		}
+
+		log.InfoF("Set global Git config '%s' to '%s'.", key, value)
+		err = cm.CombineErrors(err, gitx.SetConfig(key, value, git.GlobalScope))
+	}
+	setBool := func(key string, v *bool) {
+		if v != nil {
+			set(key, strconv.FormatBool(*v))
+		}
+	}
+	setString := func(key string, v string) {
+		if strs.IsNotEmpty(v) {
+			set(key, v)
+		}
+	}
+
+	if s.SharedRepos != nil {
+		if dryRun {
+			log.InfoF("[dry run] Would set global shared repositories to '%q'.", s.SharedRepos)
+		} else {
+			log.InfoF("Set global shared repositories to '%q'.", s.SharedRepos)
+			err = cm.CombineErrors(err, gitx.UnsetConfig(hooks.GitCKShared, git.GlobalScope))
+			for _, url := range s.SharedRepos {
+				err = cm.CombineErrors(err, gitx.AddConfig(hooks.GitCKShared, url, git.GlobalScope))
+			}
+		}
+	}
+
+	setBool(hooks.GitCKAutoUpdateSharedHooksDisabled, s.AutoUpdateSharedHooksDisabled)
+	setBool(hooks.GitCKSkipNonExistingSharedHooks, s.SkipNonExistingSharedHooks)
+
+	if s.NumThreads != nil {
+		set(hooks.GitCKNumThreads, strconv.Itoa(*s.NumThreads))
+	}
+
+	setBool(hooks.GitCKContainerizedHooksEnabled, s.ContainerizedHooksEnabled)
+	setString(hooks.GitCKContainerManager, s.ContainerManager)
+
+	setBool(hooks.GitCKSkipUntrustedHooks, s.SkipUntrustedHooks)
+	setBool(hooks.GitCKRunnerIsNonInteractive, s.RunnerIsNonInteractive)
+	setBool(hooks.GitCKExportStagedFilesAsFile, s.ExportStagedFilesAsFile)
+
+	setBool(hooks.GitCKUpdateCheckEnabled, s.UpdateCheckEnabled)
+	setString(hooks.GitCKUpdateChannel, s.UpdateChannel)
+
+	return err
+}
+
+// ExportProfile creates an install profile from the current installation.
+func ExportProfile(gitx *git.Context) (profile Profile, err error) {
+	profile.Version = ProfileVersion
+
+	getBool := func(key string) *bool {
+		v, exists := gitx.LookupConfig(key, git.GlobalScope)
+		if !exists {
+			return nil
+		}
+		b := v == git.GitCVTrue
+
+		return &b
+	}
+	getString := func(key string) string {
+		return gitx.GetConfig(key, git.GlobalScope)
+	}
+
+	// Installer
+	nonInteractive := true
+	profile.Installer.NonInteractive = &nonInteractive
+
+	if haveInstall, mode := GetInstallMode(gitx); haveInstall {
+		centralized := mode == InstallModeTypeV.Centralized
+		profile.Installer.Centralized = &centralized
+	}
+
+	if _, maintained, isSet, e := hooks.GetMaintainedHooks(gitx, git.GlobalScope); e == nil && isSet {
+		profile.Installer.MaintainedHooks = maintained
+	}
+
+	profile.Installer.CloneURL = getString(hooks.GitCKCloneURL)
+	profile.Installer.CloneBranch = getString(hooks.GitCKCloneBranch)
+	profile.Installer.BuildFromSource = getBool(hooks.GitCKBuildFromSource)
+	profile.Installer.UsePreRelease = getBool(hooks.GitCKUpdateCheckUsePrerelease)
+
+	// Settings
+	profile.Settings.SharedRepos = gitx.GetConfigAll(hooks.GitCKShared, git.GlobalScope)
+	profile.Settings.AutoUpdateSharedHooksDisabled = getBool(hooks.GitCKAutoUpdateSharedHooksDisabled)
+	profile.Settings.SkipNonExistingSharedHooks = getBool(hooks.GitCKSkipNonExistingSharedHooks)
+
+	if n := getString(hooks.GitCKNumThreads); strs.IsNotEmpty(n) {
+		var threads int
+		if threads, err = strconv.Atoi(n); err != nil {
+			err = cm.CombineErrors(cm.ErrorF("Could not parse Git config '%s'.", hooks.GitCKNumThreads), err)
+
+			return
+		}
+		profile.Settings.NumThreads = &threads
+	}
+
+	profile.Settings.ContainerizedHooksEnabled = getBool(hooks.GitCKContainerizedHooksEnabled)
+	profile.Settings.ContainerManager = getString(hooks.GitCKContainerManager)
+
+	profile.Settings.SkipUntrustedHooks = getBool(hooks.GitCKSkipUntrustedHooks)
+	profile.Settings.RunnerIsNonInteractive = getBool(hooks.GitCKRunnerIsNonInteractive)
+	profile.Settings.ExportStagedFilesAsFile = getBool(hooks.GitCKExportStagedFilesAsFile)
+
+	profile.Settings.UpdateCheckEnabled = getBool(hooks.GitCKUpdateCheckEnabled)
+	profile.Settings.UpdateChannel = getString(hooks.GitCKUpdateChannel)
+
+	return
+}
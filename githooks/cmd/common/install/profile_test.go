@@ -0,0 +1,67 @@
+package install
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	file := path.Join(dir, "profile.yaml")
+
+	content := `version: 1
+installer:
+  nonInteractive: true
+  centralized: false
+  maintainedHooks: ["pre-commit", "pre-push"]
+  deploySettings: deploy.yaml
+settings:
+  sharedRepos: ["https://example.com/hooks.git"]
+  numThreads: 3
+  containerManager: podman
+  skipUntrustedHooks: true
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600)) //nolint:mnd
+
+	profile, err := LoadProfile(file)
+	require.NoError(t, err)
+	assert.Equal(t, path.Join(dir, "deploy.yaml"), profile.Installer.DeploySettings)
+
+	args := profile.Installer.InstallerArgs()
+	assert.Equal(t, true, args["nonInteractive"])
+	assert.Equal(t, false, args["centralized"])
+	assert.Equal(t, []string{"pre-commit", "pre-push"}, args["maintainedHooks"])
+	assert.NotContains(t, args, "cloneURL")
+
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+
+	gitx := git.NewCtx()
+	require.NoError(t, ApplyProfileSettings(log, gitx, &profile.Settings, false))
+
+	assert.Equal(t, "3", gitx.GetConfig(hooks.GitCKNumThreads, git.GlobalScope))
+	assert.Equal(t, "true", gitx.GetConfig(hooks.GitCKSkipUntrustedHooks, git.GlobalScope))
+
+	exported, err := ExportProfile(gitx)
+	require.NoError(t, err)
+	assert.Equal(t, profile.Settings.SharedRepos, exported.Settings.SharedRepos)
+	assert.Equal(t, 3, *exported.Settings.NumThreads)
+	assert.Equal(t, "podman", exported.Settings.ContainerManager)
+	assert.True(t, *exported.Settings.SkipUntrustedHooks)
+	assert.Nil(t, exported.Settings.RunnerIsNonInteractive)
+
+	require.NoError(t, os.WriteFile(file, []byte("version: 2\n"), 0600)) //nolint:mnd
+	_, err = LoadProfile(file)
+	assert.Error(t, err)
+}
@@ -100,7 +100,7 @@ func InstallIntoRepo(
 			// Filter out all non-relevant hooks for bare repositories.
 			hookNames = strs.Filter(
 				hookNames,
-				func(s string) bool { return strs.Includes(hooks.ManagedServerHookNames, s) },
+				hooks.IsServerHookName,
 			)
 			// LFS hooks also do not need to be reinstalled
 			lfsHooksCache = nil
@@ -157,6 +157,13 @@ func cleanArtefactsInRepo(log cm.ILogContext, gitDir string) {
 		log.AssertNoErrorF(os.RemoveAll(ignoreFile),
 			"Could not delete ignore file '%s'.", ignoreFile)
 	}
+
+	// Remove the state shared by all worktrees...
+	sharedDir := hooks.GetSharedStateDir(gitDir)
+	if cm.IsDirectory(sharedDir) {
+		log.AssertNoErrorF(os.RemoveAll(sharedDir),
+			"Could not delete shared state dir '%s'.", sharedDir)
+	}
 }
 
 func cleanGitConfigInRepo(log cm.ILogContext, gitDir string, minimal bool) {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/cmd/common/install"
 	"github.com/gabyx/githooks/githooks/cmd/disable"
 	cm "github.com/gabyx/githooks/githooks/common"
 	"github.com/gabyx/githooks/githooks/git"
@@ -250,6 +251,78 @@ func runContainerManagerTypes(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *Gi
 	}
 }
 
+func runHookTimeBudget(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
+	opt := hooks.GitCKHookTimeBudget
+	localOrGlobal := "locally" // nolint: goconst
+	if gitOpts.Global {
+		localOrGlobal = "globally" // nolint: goconst
+	}
+
+	scope := wrapToGitScope(ctx.Log, gitOpts)
+	switch {
+	case opts.Set:
+		budget, err := time.ParseDuration(opts.Values[0])
+		ctx.Log.PanicIfF(err != nil || budget <= 0,
+			"Hook time budget '%s' is not a positive duration, e.g. '2s'.", opts.Values[0])
+
+		err = ctx.GitX.SetConfig(opt, budget.String(), scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not set Git config '%s'.", opt)
+		ctx.Log.InfoF("Hook time budget is set to '%v' %s.", budget, localOrGlobal)
+
+	case opts.Reset:
+		err := ctx.GitX.UnsetConfig(opt, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not unset Git config '%s'.", opt)
+		ctx.Log.InfoF("Hook time budget is unset %s.", localOrGlobal)
+
+	case opts.Print:
+		conf := ctx.GitX.GetConfig(opt, scope)
+		if strs.IsEmpty(conf) {
+			ctx.Log.InfoF("Hook time budget is not set %s.", localOrGlobal)
+		} else {
+			ctx.Log.InfoF("Hook time budget is set to '%s' %s.", conf, localOrGlobal)
+		}
+	default:
+		cm.Panic("Wrong arguments.")
+	}
+}
+
+func runOutputMode(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
+	opt := hooks.GitCKOutputMode
+	localOrGlobal := "locally" // nolint: goconst
+	if gitOpts.Global {
+		localOrGlobal = "globally" // nolint: goconst
+	}
+
+	scope := wrapToGitScope(ctx.Log, gitOpts)
+	switch {
+	case opts.Set:
+		mode := opts.Values[0]
+		ctx.Log.PanicIfF(
+			mode != hooks.OutputModeTypeV.Buffered.Name() &&
+				mode != hooks.OutputModeTypeV.Stream.Name(),
+			"Output mode '%s' is not 'buffered' or 'stream'.", mode)
+
+		err := ctx.GitX.SetConfig(opt, mode, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not set Git config '%s'.", opt)
+		ctx.Log.InfoF("Output mode of hooks is set to '%s' %s.", mode, localOrGlobal)
+
+	case opts.Reset:
+		err := ctx.GitX.UnsetConfig(opt, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not unset Git config '%s'.", opt)
+		ctx.Log.InfoF("Output mode of hooks is unset %s.", localOrGlobal)
+
+	case opts.Print:
+		conf := ctx.GitX.GetConfig(opt, scope)
+		if strs.IsEmpty(conf) {
+			ctx.Log.InfoF("Output mode of hooks is not set %s.", localOrGlobal)
+		} else {
+			ctx.Log.InfoF("Output mode of hooks is set to '%s' %s.", conf, localOrGlobal)
+		}
+	default:
+		cm.Panic("Wrong arguments.")
+	}
+}
+
 func runContainerizedHooksEnable(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
 	opt := hooks.GitCKContainerizedHooksEnabled
 	localOrGlobal := "locally" // nolint: goconst
@@ -466,6 +539,32 @@ func RunUpdateCheck(ctx *ccm.CmdContext, opts *SetOptions) {
 	}
 }
 
+func runUpdateChannel(ctx *ccm.CmdContext, opts *SetOptions) {
+	const text = "Githooks update channel"
+
+	switch {
+	case opts.Set:
+		channel, err := updates.ParseUpdateChannel(opts.Values[0])
+		ctx.Log.AssertNoErrorPanicF(err, "Could not parse %s.", text)
+		err = updates.SetUpdateChannel(ctx.GitX, channel)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not set %s.", text)
+		ctx.Log.InfoF("%s is now set to '%s'.", text, channel.String())
+
+	case opts.Reset:
+		err := updates.ResetUpdateChannel(ctx.GitX)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not reset %s.", text)
+		ctx.Log.InfoF("%s is now unset. Using '%s'.", text, updates.StableChannel)
+
+	case opts.Print:
+		channel, err := updates.GetUpdateChannel(ctx.GitX)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not get %s.", text)
+		ctx.Log.InfoF("%s is set to '%s'.", text, channel.String())
+
+	default:
+		cm.Panic("Wrong arguments.")
+	}
+}
+
 func runRunnerNonInteractive(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
 	scope := wrapToGitScope(ctx.Log, gitOpts)
 
@@ -643,6 +742,94 @@ func runSkipUntrustedHooks(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOp
 	}
 }
 
+func runShareWorktreeState(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
+	scope := wrapToGitScope(ctx.Log, gitOpts)
+
+	localOrGlobal := "locally" //nolint:goconst
+	if gitOpts.Global {
+		localOrGlobal = "globally" //nolint:goconst
+	}
+
+	const text = "sharing trust and user ignores across worktrees"
+	switch {
+	case opts.Set:
+		err := hooks.SetShareWorktreeState(ctx.GitX, true, false, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not enable %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Enabled %s %s.", text, localOrGlobal)
+
+	case opts.Unset:
+		err := hooks.SetShareWorktreeState(ctx.GitX, false, false, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not disable %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Disabled %s %s.", text, localOrGlobal)
+
+	case opts.Reset:
+		err := hooks.SetShareWorktreeState(ctx.GitX, false, true, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not reset %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Reset %s %s.", text, localOrGlobal)
+
+	case opts.Print:
+		localOrGlobal = " " + localOrGlobal
+		if !gitOpts.Global && !gitOpts.Local {
+			scope = git.Traverse
+			localOrGlobal = ""
+		}
+
+		enabled, _ := hooks.IsWorktreeStateShared(ctx.GitX, scope)
+		if enabled {
+			ctx.Log.InfoF("Sharing trust and user ignores is enabled%s.", localOrGlobal)
+		} else {
+			ctx.Log.InfoF("Sharing trust and user ignores is disabled%s.", localOrGlobal)
+		}
+
+	default:
+		cm.Panic("Wrong arguments.")
+	}
+}
+
+func runServerMode(ctx *ccm.CmdContext, opts *SetOptions, gitOpts *GitOptions) {
+	scope := wrapToGitScope(ctx.Log, gitOpts)
+
+	localOrGlobal := "locally" //nolint:goconst
+	if gitOpts.Global {
+		localOrGlobal = "globally" //nolint:goconst
+	}
+
+	const text = "server mode"
+	switch {
+	case opts.Set:
+		err := hooks.SetServerMode(ctx.GitX, true, false, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not enable %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Enabled %s %s.", text, localOrGlobal)
+
+	case opts.Unset:
+		err := hooks.SetServerMode(ctx.GitX, false, false, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not disable %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Disabled %s %s.", text, localOrGlobal)
+
+	case opts.Reset:
+		err := hooks.SetServerMode(ctx.GitX, false, true, scope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not reset %s %s.", text, localOrGlobal)
+		ctx.Log.InfoF("Reset %s %s.", text, localOrGlobal)
+
+	case opts.Print:
+		localOrGlobal = " " + localOrGlobal
+		if !gitOpts.Global && !gitOpts.Local {
+			scope = git.Traverse
+			localOrGlobal = ""
+		}
+
+		enabled, _ := hooks.IsServerModeEnabled(ctx.GitX, scope)
+		if enabled {
+			ctx.Log.InfoF("Server mode is enabled%s.", localOrGlobal)
+		} else {
+			ctx.Log.InfoF("Server mode is disabled%s.", localOrGlobal)
+		}
+
+	default:
+		cm.Panic("Wrong arguments.")
+	}
+}
+
 func runDeleteDetectedLFSHooks(ctx *ccm.CmdContext, opts *SetOptions) {
 	opt := hooks.GitCKDeleteDetectedLFSHooksAnswer
 
@@ -878,6 +1065,30 @@ func configUpdateCheckCmd(ctx *ccm.CmdContext, configCmd *cobra.Command, setOpts
 	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, updateCmd))
 }
 
+func configUpdateChannelCmd(ctx *ccm.CmdContext, configCmd *cobra.Command, setOpts *SetOptions) {
+	updateChannelCmd := &cobra.Command{
+		Use:   "update-channel [flags] [<channel>]",
+		Short: "Changes the Githooks update channel.",
+		Long: `Changes the Githooks update channel which decides
+which versions are considered for updates.
+
+The '<channel>' is one of:
+
+- 'stable' : All releases (default). Releases with a
+             'Update-Rollout: <percentage>' trailer are only
+             considered on the given percentage of installations.
+- 'canary' : All releases including prereleases immediately.
+- 'pinned:<version>' : Only releases up to and including '<version>'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runUpdateChannel(ctx, setOpts)
+		}}
+
+	optsPSR := createOptionMap(true, false, true)
+
+	configSetOptions(updateChannelCmd, setOpts, &optsPSR, ctx.Log, 1, 1)
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, updateChannelCmd))
+}
+
 func configUpdateTimeCmd(ctx *ccm.CmdContext, configCmd *cobra.Command, setOpts *SetOptions) {
 	updateTimeCmd := &cobra.Command{
 		Use:   "update-time [flags]",
@@ -1048,6 +1259,89 @@ Mostly wanted if all hooks must be executed.`,
 	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, nonExistSharedCmd))
 }
 
+func configShareWorktreeState(
+	ctx *ccm.CmdContext,
+	configCmd *cobra.Command,
+	setOpts *SetOptions,
+	gitOpts *GitOptions) {
+	shareCmd := &cobra.Command{
+		Use:   "share-worktree-state [flags]",
+		Short: "Enable/disable sharing trust and user ignores across worktrees.",
+		Long: `Enable or disable storing the trusted hooks and the user ignore list
+in the common Git directory, such that they are shared by all worktrees.
+The state of each worktree still overrides the shared state.
+Existing per-worktree state can be merged with 'git hooks worktrees migrate'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !gitOpts.Local && !gitOpts.Global {
+				gitOpts.Local = true
+			}
+
+			if gitOpts.Local {
+				ccm.AssertRepoRoot(ctx)
+			}
+
+			runShareWorktreeState(ctx, setOpts, gitOpts)
+		}}
+
+	optsPSUR := createOptionMap(true, true, true)
+	wrapToEnableDisable(&optsPSUR)
+	optsPSUR.SetDesc = "Enable sharing trust and user ignores across worktrees."
+	optsPSUR.UnsetDesc = "Disable sharing trust and user ignores across worktrees."
+	optsPSUR.ResetDesc = "Reset sharing trust and user ignores across worktrees."
+
+	configSetOptions(shareCmd, setOpts, &optsPSUR, ctx.Log, 0, 0)
+
+	shareCmd.Flags().BoolVar(&gitOpts.Local, "local", false,
+		"Use the local Git configuration (default, except for '--print').")
+	shareCmd.Flags().BoolVar(&gitOpts.Global,
+		"global", false, "Use the global Git configuration.")
+
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, shareCmd))
+}
+
+func configServerMode(
+	ctx *ccm.CmdContext,
+	configCmd *cobra.Command,
+	setOpts *SetOptions,
+	gitOpts *GitOptions) {
+	serverModeCmd := &cobra.Command{
+		Use:   "server-mode [flags]",
+		Short: "Enable/disable running server hooks in server mode.",
+		Long: `Enable or disable running the hooks 'pre-receive', 'update' and 'post-receive'
+in server mode, e.g. in bare repositories on a Git server.
+
+In server mode the runner never prompts, active, untrusted hooks
+reject the push and the pushed reference updates are exported
+to the hooks in 'GITHOOKS_REF_UPDATES' and their changed files
+in the file 'GITHOOKS_CHANGED_FILES_FILE'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !gitOpts.Local && !gitOpts.Global {
+				gitOpts.Local = true
+			}
+
+			if gitOpts.Local {
+				ccm.AssertRepoRoot(ctx)
+			}
+
+			runServerMode(ctx, setOpts, gitOpts)
+		}}
+
+	optsPSUR := createOptionMap(true, true, true)
+	wrapToEnableDisable(&optsPSUR)
+	optsPSUR.SetDesc = "Enable server mode."
+	optsPSUR.UnsetDesc = "Disable server mode."
+	optsPSUR.ResetDesc = "Reset server mode."
+
+	configSetOptions(serverModeCmd, setOpts, &optsPSUR, ctx.Log, 0, 0)
+
+	serverModeCmd.Flags().BoolVar(&gitOpts.Local, "local", false,
+		"Use the local Git configuration (default, except for '--print').")
+	serverModeCmd.Flags().BoolVar(&gitOpts.Global,
+		"global", false, "Use the global Git configuration.")
+
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, serverModeCmd))
+}
+
 func configNonInteractiveRunner(
 	ctx *ccm.CmdContext,
 	configCmd *cobra.Command,
@@ -1086,6 +1380,72 @@ See 'git hooks config trust-all --help'.`,
 	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, nonInteracticeRunner))
 }
 
+func configHookTimeBudget(
+	ctx *ccm.CmdContext,
+	configCmd *cobra.Command,
+	setOpts *SetOptions,
+	gitOpts *GitOptions) {
+	hookTimeBudgetCmd := &cobra.Command{
+		Use:   "hook-time-budget [flags] [<duration>]",
+		Short: "Set the duration budget of a hook (see 'git hooks stats').",
+		Long: `Set the duration budget of a hook, e.g. '2s' or '500ms'.
+The runner warns about each hook which takes longer than the budget.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !gitOpts.Local && !gitOpts.Global {
+				gitOpts.Local = true
+			}
+
+			if gitOpts.Local {
+				ccm.AssertRepoRoot(ctx)
+			}
+
+			runHookTimeBudget(ctx, setOpts, gitOpts)
+		}}
+
+	optsPSR := createOptionMap(true, false, true)
+
+	configSetOptions(hookTimeBudgetCmd, setOpts, &optsPSR, ctx.Log, 1, 1)
+	hookTimeBudgetCmd.Flags().
+		BoolVar(&gitOpts.Local, "local", false, "Use the local Git configuration (default).")
+	hookTimeBudgetCmd.Flags().BoolVar(&gitOpts.Global, "global", false, "Use the global Git configuration.")
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, hookTimeBudgetCmd))
+}
+
+func configOutputMode(
+	ctx *ccm.CmdContext,
+	configCmd *cobra.Command,
+	setOpts *SetOptions,
+	gitOpts *GitOptions) {
+	outputModeCmd := &cobra.Command{
+		Use:   "output-mode [flags] [buffered|stream]",
+		Short: "Set the output mode of hooks.",
+		Long: `Set the output mode of hooks run by the runner.
+
+In 'buffered' mode (default) the output of a hook is shown after it finished.
+In 'stream' mode the output of each hook is shown live
+with each line prefixed by the hook's namespace path.
+The environment variable 'GITHOOKS_OUTPUT_MODE' overwrites this setting.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !gitOpts.Local && !gitOpts.Global {
+				gitOpts.Local = true
+			}
+
+			if gitOpts.Local {
+				ccm.AssertRepoRoot(ctx)
+			}
+
+			runOutputMode(ctx, setOpts, gitOpts)
+		}}
+
+	optsPSR := createOptionMap(true, false, true)
+
+	configSetOptions(outputModeCmd, setOpts, &optsPSR, ctx.Log, 1, 1)
+	outputModeCmd.Flags().
+		BoolVar(&gitOpts.Local, "local", false, "Use the local Git configuration (default).")
+	outputModeCmd.Flags().BoolVar(&gitOpts.Global, "global", false, "Use the global Git configuration.")
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, outputModeCmd))
+}
+
 func configDetectedLFSCmd(
 	ctx *ccm.CmdContext,
 	configCmd *cobra.Command,
@@ -1115,6 +1475,48 @@ disabled and backed up.`,
 	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, deleteDetectedLFSCmd))
 }
 
+func runExportProfile(ctx *ccm.CmdContext, output string) {
+	profile, err := install.ExportProfile(ctx.GitX)
+	ctx.Log.AssertNoErrorPanic(err, "Could not export install profile.")
+
+	if strs.IsNotEmpty(output) {
+		err = install.StoreProfile(output, &profile)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not write install profile to '%s'.", output)
+		ctx.Log.InfoF("Exported install profile to '%s'.", output)
+
+		return
+	}
+
+	content, err := install.FormatProfile(&profile)
+	ctx.Log.AssertNoErrorPanic(err, "Could not format install profile.")
+	_, err = ctx.Log.GetInfoWriter().Write(content)
+	ctx.Log.AssertNoErrorPanic(err, "Could not write install profile.")
+}
+
+func configExportProfileCmd(ctx *ccm.CmdContext, configCmd *cobra.Command) {
+	output := ""
+
+	exportCmd := &cobra.Command{
+		Use:   "export-profile [flags]",
+		Short: "Exports the current installation as an install profile.",
+		Long: `Exports the current installation as an install profile YAML file.
+
+The profile contains the installer arguments and the global
+settings (shared repositories, number of threads, container manager,
+trust policy, etc.) of the current installation and can be used
+to reproduce this setup with 'githooks-cli installer --profile <file>'.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runExportProfile(ctx, output)
+		}}
+
+	exportCmd.Flags().StringVar(&output, "output", "",
+		"Write the profile to this file instead of the standard output.")
+	cm.AssertNoErrorPanic(exportCmd.MarkFlagFilename("output"))
+
+	configCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, exportCmd))
+}
+
 // NewCmd creates this new command.
 func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 	configCmd := &cobra.Command{
@@ -1133,6 +1535,7 @@ func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 	configSearchDirCmd(ctx, configCmd, &setOpts)
 	configUpdateCheckCmd(ctx, configCmd, &setOpts)
 	configUpdateTimeCmd(ctx, configCmd, &setOpts)
+	configUpdateChannelCmd(ctx, configCmd, &setOpts)
 	configCloneURLCmd(ctx, configCmd, &setOpts)
 	configCloneBranchCmd(ctx, configCmd, &setOpts)
 
@@ -1144,11 +1547,17 @@ func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 
 	configSkipNonExistingSharedHooks(ctx, configCmd, &setOpts, &gitOpts)
 	configFailUntrustedHooks(ctx, configCmd, &setOpts, &gitOpts)
+	configShareWorktreeState(ctx, configCmd, &setOpts, &gitOpts)
 
 	configNonInteractiveRunner(ctx, configCmd, &setOpts, &gitOpts)
+	configServerMode(ctx, configCmd, &setOpts, &gitOpts)
+	configHookTimeBudget(ctx, configCmd, &setOpts, &gitOpts)
+	configOutputMode(ctx, configCmd, &setOpts, &gitOpts)
 
 	configDetectedLFSCmd(ctx, configCmd, &setOpts, &gitOpts)
 
+	configExportProfileCmd(ctx, configCmd)
+
 	configCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
 		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
 	}
@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/spf13/cobra"
+)
+
+// The host the dashboard is served on.
+const dashboardHost = "127.0.0.1"
+
+func runDashboard(ctx *ccm.CmdContext, port int) {
+	listRepos := func() (repos []string) {
+		var registered hooks.RegisterRepos
+		err := registered.Load(ctx.InstallDir, true, false)
+		ctx.Log.AssertNoErrorF(err, "Could not load registered repositories.")
+
+		for _, gitDir := range registered.GitDirs {
+			repos = append(repos, getRepoDir(gitDir))
+		}
+
+		return
+	}
+
+	loadRepo := func(repoDir string) repoState {
+		return loadRepoState(ctx, repoDir)
+	}
+
+	handler, err := newServer(ctx.InstallDir, listRepos, loadRepo)
+	ctx.Log.AssertNoErrorPanic(err, "Could not create the dashboard.")
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(dashboardHost, strconv.Itoa(port)))
+	ctx.Log.AssertNoErrorPanicF(err, "Could not listen on port '%v'.", port)
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second} //nolint:mnd
+	ctx.CleanupX.AddHandler(func() { _ = srv.Close() })
+
+	ctx.Log.InfoF("Serving the dashboard on 'http://localhost:%v'.\nPress Ctrl+C to stop.",
+		listener.Addr().(*net.TCPAddr).Port)
+
+	err = srv.Serve(listener)
+	if !errors.Is(err, http.ErrServerClosed) {
+		ctx.Log.AssertNoErrorPanic(err, "Serving the dashboard failed.")
+	}
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	port := 0
+
+	dashboardCmd := &cobra.Command{
+		Use:   "dashboard",
+		Short: "Serves a local web dashboard with the hook execution history.",
+		Long: `Serves a local web dashboard showing the recent hook runs of all repositories
+with their durations, failures and outputs, the shared repositories with their update state
+and the trust and ignore state of all hooks.
+
+The dashboard is only served on the local host and works offline.
+The runs are recorded by the runner in the install directory
+and the last runs of each repository are kept.
+Set 'git config githooks.historyOutput true' to also record the outputs of hooks
+and 'git config githooks.historyDisabled true' to not record runs.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runDashboard(ctx, port)
+		}}
+
+	dashboardCmd.Flags().IntVar(&port, "port", 0,
+		"The port to serve the dashboard on (a free port is used if '0').")
+
+	dashboardCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, dashboardCmd)
+}
@@ -0,0 +1,267 @@
+package dashboard
+
+import (
+	"embed"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// repoSummary is a repository listed on the overview page.
+type repoSummary struct {
+	Path    string
+	Runs    int
+	Failed  int
+	LastRun *hooks.HistoryRun
+}
+
+// server serves the dashboard pages.
+type server struct {
+	installDir string
+
+	// Lists the paths of all known repositories (besides the ones in the history).
+	listRepos func() []string
+	// Loads the state of a repository.
+	loadRepo func(repoDir string) repoState
+
+	pages map[string]*template.Template
+	mux   *http.ServeMux
+}
+
+func newServer(installDir string, listRepos func() []string, loadRepo func(string) repoState) (*server, error) {
+	s := &server{
+		installDir: installDir,
+		listRepos:  listRepos,
+		loadRepo:   loadRepo,
+		pages:      make(map[string]*template.Template),
+		mux:        http.NewServeMux()}
+
+	funcs := template.FuncMap{
+		"formatTime": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+		"formatDuration": func(d time.Duration) string {
+			return d.Round(time.Millisecond).String()
+		},
+		"join": strings.Join,
+	}
+
+	for _, page := range []string{"overview", "repo", "run"} {
+		t, err := template.New(page).Funcs(funcs).ParseFS(templateFiles,
+			"templates/layout.html", "templates/"+page+".html")
+		if err != nil {
+			return nil, cm.CombineErrors(err, cm.ErrorF("Could not parse template '%s'.", page))
+		}
+
+		s.pages[page] = t
+	}
+
+	s.mux.HandleFunc("/", s.serveOverview)
+	s.mux.HandleFunc("/repo", s.serveRepo)
+	s.mux.HandleFunc("/run", s.serveRun)
+	s.mux.HandleFunc("/api/history", s.serveAPIHistory)
+	s.mux.HandleFunc("/api/repo", s.serveAPIRepo)
+
+	return s, nil
+}
+
+// ServeHTTP serves all requests from the local host only.
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Reject requests to other hosts which resolve
+	// to the local host (DNS rebinding).
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	if host != "localhost" && host != "127.0.0.1" {
+		http.Error(w, "Forbidden host.", http.StatusForbidden)
+
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *server) render(w http.ResponseWriter, page string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := s.pages[page].ExecuteTemplate(w, "layout", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *server) writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := cm.WriteJSON(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// loadHistory loads the history of all known repositories.
+func (s *server) loadHistory() (map[string][]hooks.HistoryRun, error) {
+	history, err := hooks.LoadAllHistory(s.installDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.listRepos != nil {
+		for _, repo := range s.listRepos() {
+			if _, exists := history[repo]; !exists {
+				history[repo] = nil
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// loadRepoHistory loads the history of a known repository `repoDir`.
+func (s *server) loadRepoHistory(w http.ResponseWriter, repoDir string) ([]hooks.HistoryRun, bool) {
+	history, err := s.loadHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	runs, exists := history[repoDir]
+	if !exists {
+		http.Error(w, strs.Fmt("Repository '%s' not found.", repoDir), http.StatusNotFound)
+
+		return nil, false
+	}
+
+	return runs, true
+}
+
+func (s *server) serveOverview(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	history, err := s.loadHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	repos := make([]repoSummary, 0, len(history))
+	for repo, runs := range history {
+		summary := repoSummary{Path: repo, Runs: len(runs)}
+
+		for i := range runs {
+			if runs[i].Failed {
+				summary.Failed++
+			}
+		}
+
+		if len(runs) != 0 {
+			summary.LastRun = &runs[len(runs)-1]
+		}
+
+		repos = append(repos, summary)
+	}
+
+	// Most recently run repositories first.
+	sort.Slice(repos, func(i, j int) bool {
+		a, b := repos[i].LastRun, repos[j].LastRun
+		switch {
+		case a == nil || b == nil:
+			if a == nil && b == nil {
+				return repos[i].Path < repos[j].Path
+			}
+
+			return a != nil
+		default:
+			return a.Start.After(b.Start)
+		}
+	})
+
+	s.render(w, "overview", repos)
+}
+
+func (s *server) serveRepo(w http.ResponseWriter, r *http.Request) {
+	repoDir := r.URL.Query().Get("path")
+
+	runs, ok := s.loadRepoHistory(w, repoDir)
+	if !ok {
+		return
+	}
+
+	// Newest runs first.
+	sorted := make([]hooks.HistoryRun, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		sorted = append(sorted, runs[i])
+	}
+
+	s.render(w, "repo",
+		struct {
+			State repoState
+			Runs  []hooks.HistoryRun
+		}{s.loadRepo(repoDir), sorted})
+}
+
+func (s *server) serveRun(w http.ResponseWriter, r *http.Request) {
+	repoDir := r.URL.Query().Get("repo")
+	id := r.URL.Query().Get("id")
+
+	runs, ok := s.loadRepoHistory(w, repoDir)
+	if !ok {
+		return
+	}
+
+	for i := range runs {
+		if runs[i].ID == id {
+			s.render(w, "run", &runs[i])
+
+			return
+		}
+	}
+
+	http.Error(w, strs.Fmt("Run '%s' not found.", id), http.StatusNotFound)
+}
+
+func (s *server) serveAPIHistory(w http.ResponseWriter, _ *http.Request) {
+	history, err := s.loadHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	s.writeJSON(w, history)
+}
+
+func (s *server) serveAPIRepo(w http.ResponseWriter, r *http.Request) {
+	repoDir := r.URL.Query().Get("path")
+
+	runs, ok := s.loadRepoHistory(w, repoDir)
+	if !ok {
+		return
+	}
+
+	s.writeJSON(w,
+		struct {
+			repoState
+			Runs []hooks.HistoryRun `json:"runs"`
+		}{s.loadRepo(repoDir), runs})
+}
@@ -0,0 +1,84 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, s *server, target string, host string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestDashboard(t *testing.T) {
+	installDir := t.TempDir()
+
+	hook := hooks.Hook{Path: "/repo/.githooks/pre-commit/lint", NamespacePath: "ns:repo/pre-commit/lint"}
+	run := hooks.NewHistoryRun("/repo", "pre-commit", nil)
+	run.RecordOutput = true
+	run.AddResults(hooks.HookResult{
+		Hook:     &hook,
+		Output:   []byte("<lint failed>"),
+		Error:    cm.Error("exit status 1"),
+		ExitCode: 1})
+	run.Finish()
+	require.NoError(t, hooks.AppendHistory(installDir, &run))
+
+	listRepos := func() []string { return []string{"/repo", "/other"} }
+	loadRepo := func(repoDir string) repoState {
+		return repoState{
+			Path:   repoDir,
+			Exists: true,
+			Hooks: []hookState{
+				{HookName: "pre-commit", Name: "lint", NamespacePath: hook.NamespacePath, Active: true}},
+			Shared: []sharedState{{Type: "global", URL: "https://a/b.git", State: "pending"}}}
+	}
+
+	s, err := newServer(installDir, listRepos, loadRepo)
+	require.NoError(t, err)
+
+	rec := get(t, s, "/", "localhost:8080")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/repo?path=%2frepo")
+	assert.Contains(t, rec.Body.String(), "/other")
+	assert.Contains(t, rec.Body.String(), "no runs")
+
+	rec = get(t, s, "/repo?path="+url.QueryEscape("/repo"), "127.0.0.1:8080")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "ns:repo/pre-commit/lint")
+	assert.Contains(t, rec.Body.String(), "untrusted")
+	assert.Contains(t, rec.Body.String(), "pending update")
+	assert.Contains(t, rec.Body.String(), run.ID)
+
+	rec = get(t, s, "/run?repo="+url.QueryEscape("/repo")+"&id="+run.ID, "localhost")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "&lt;lint failed&gt;")
+	assert.Contains(t, rec.Body.String(), "exit code 1")
+
+	rec = get(t, s, "/api/history", "localhost")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var history map[string][]hooks.HistoryRun
+	require.NoError(t, cm.ReadJSON(rec.Body, &history))
+	assert.Len(t, history["/repo"], 1)
+	assert.Contains(t, history, "/other")
+
+	// Unknown repositories and runs.
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/repo?path=/etc", "localhost").Code)
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/run?repo=/repo&id=none", "localhost").Code)
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/unknown", "localhost").Code)
+
+	// Other hosts are rejected.
+	assert.Equal(t, http.StatusForbidden, get(t, s, "/", "evil.com").Code)
+}
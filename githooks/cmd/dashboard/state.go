@@ -0,0 +1,146 @@
+package dashboard
+
+import (
+	"path"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/cmd/list"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// hookState is the state of a hook shown in the dashboard.
+type hookState struct {
+	HookName      string `json:"hookName"`
+	Tag           string `json:"tag"`
+	Name          string `json:"name"`
+	NamespacePath string `json:"namespacePath"`
+	Path          string `json:"path"`
+	Active        bool   `json:"active"`
+	Trusted       bool   `json:"trusted"`
+}
+
+// sharedState is the state of a shared repository shown in the dashboard.
+type sharedState struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Branch string `json:"branch,omitempty"`
+	State  string `json:"state"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// repoState is the state of a repository shown in the dashboard.
+type repoState struct {
+	Path             string        `json:"path"`
+	Exists           bool          `json:"exists"`
+	GithooksDisabled bool          `json:"githooksDisabled"`
+	Trusted          bool          `json:"trusted"`
+	Hooks            []hookState   `json:"hooks"`
+	Shared           []sharedState `json:"shared"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// loadRepoState loads the state of all hooks and shared repositories
+// in repository `repoDir`.
+func loadRepoState(ctx *ccm.CmdContext, repoDir string) (s repoState) {
+	s.Path = repoDir
+
+	gitx := git.NewCtxAt(repoDir)
+	if !cm.IsDirectory(repoDir) || !gitx.IsGitRepo() {
+		return
+	}
+	s.Exists = true
+
+	// Listing hooks panics on fatal errors.
+	defer func() {
+		if r := recover(); r != nil {
+			s.Error = strs.Fmt("Could not load the state of repository '%s': %v", repoDir, r)
+		}
+	}()
+
+	_, gitDir, gitDirWorktree, err := gitx.GetRepoRoot()
+	if err != nil {
+		s.Error = strs.Fmt("Could not get the Git directory of repository '%s'.", repoDir)
+
+		return
+	}
+
+	// All state is loaded from the repository and not the current directory.
+	repoCtx := *ctx
+	repoCtx.Cwd = repoDir
+	repoCtx.GitX = gitx
+
+	repoHooksDir := hooks.GetGithooksDir(repoDir)
+	state, shared, _ := list.PrepareListHookState(
+		&repoCtx, repoDir, repoHooksDir,
+		hooks.GetUserStateDirs(gitx, gitDir, gitDirWorktree, false),
+		hooks.SupportedHookNames)
+
+	s.GithooksDisabled = state.IsGithooksDisabled()
+	s.Trusted = state.IsRepoTrusted()
+
+	for _, hookName := range hooks.SupportedHookNames {
+		sections, _ := list.CollectHooksForName(
+			ctx.Log, hookName, repoDir, gitDir, repoHooksDir, shared, state)
+
+		for _, section := range sections {
+			for i := range section.Hooks {
+				hook := &section.Hooks[i]
+				s.Hooks = append(s.Hooks,
+					hookState{
+						HookName:      hookName,
+						Tag:           section.Tag,
+						Name:          list.GetHookDisplayName(hook),
+						NamespacePath: hook.NamespacePath,
+						Path:          hook.Path,
+						Active:        hook.Active,
+						Trusted:       hook.Trusted})
+			}
+		}
+	}
+
+	for t := range shared {
+		for i := range shared[t] {
+			s.Shared = append(s.Shared,
+				getSharedState(hooks.GetSharedHookTypeString(hooks.SharedHookType(t)), &shared[t][i]))
+		}
+	}
+
+	return s
+}
+
+// getSharedState gets the update state of the shared repository `repo`.
+func getSharedState(sharedType string, repo *hooks.SharedRepo) (s sharedState) {
+	s.Type = sharedType
+	s.URL = repo.OriginalURL
+	s.Branch = repo.Branch
+
+	switch {
+	case repo.IsLocal:
+		s.State = "local"
+	case !cm.IsDirectory(repo.RepositoryDir):
+		s.State = "pending"
+
+		return
+	default:
+		s.State = "cloned"
+	}
+
+	commit, err := git.NewCtxAt(repo.RepositoryDir).Get("log", "-1", "--format=%h (%cs)")
+	if err == nil {
+		s.Commit = commit
+	}
+
+	return
+}
+
+// getRepoDir gets the repository directory of the registered Git directory `gitDir`.
+func getRepoDir(gitDir string) string {
+	if path.Base(gitDir) == ".git" {
+		return path.Dir(gitDir)
+	}
+
+	return gitDir
+}
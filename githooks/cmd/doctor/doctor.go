@@ -0,0 +1,148 @@
+package doctor
+
+import (
+	"strings"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	inst "github.com/gabyx/githooks/githooks/cmd/common/install"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+)
+
+func formatDiagnosis(diag *inst.Diagnosis) string {
+	var title string
+	if strs.IsEmpty(diag.GitDir) {
+		title = strs.Fmt("Global installation '%s':", diag.HooksDir)
+	} else {
+		title = strs.Fmt("Repository '%s':", diag.GitDir)
+	}
+
+	lst := []string{title}
+	if diag.IsHealthy() {
+		lst = append(lst, strs.Fmt(" %s healthy", cm.ListItemLiteral))
+	}
+
+	for i := range diag.Issues {
+		issue := &diag.Issues[i]
+		line := strs.Fmt(" %s %s: '%s'", cm.ListItemLiteral, issue.Type.Name(), issue.Path)
+
+		if issue.SharedRepo != nil {
+			line += strs.Fmt(" [url: '%s']", issue.SharedRepo.OriginalURL)
+		}
+
+		lst = append(lst, line)
+	}
+
+	return strings.Join(lst, "\n")
+}
+
+func runDoctor(ctx *ccm.CmdContext, fix bool) error {
+	lfsHooksCache, err := hooks.NewLFSHooksCache(hooks.GetTemporaryDir(ctx.InstallDir))
+	ctx.Log.AssertNoErrorPanicF(err, "Could not create LFS hooks cache.")
+
+	var registered hooks.RegisterRepos
+	err = registered.Load(ctx.InstallDir, false, false)
+	ctx.Log.AssertNoErrorPanicF(err, "Could not load register file in '%s'.", ctx.InstallDir)
+
+	diags := make([]inst.Diagnosis, 0, 1+len(registered.GitDirs))
+
+	diag, err := inst.DiagnoseGlobal(ctx.GitX, ctx.InstallDir, lfsHooksCache)
+	ctx.Log.AssertNoErrorF(err, "Could not fully diagnose the global installation.")
+	diags = append(diags, diag)
+
+	for _, gitDir := range registered.GitDirs {
+		diag, err = inst.DiagnoseRepo(ctx.GitX, ctx.InstallDir, gitDir, lfsHooksCache)
+		ctx.Log.AssertNoErrorF(err, "Could not fully diagnose repository '%s'.", gitDir)
+		diags = append(diags, diag)
+	}
+
+	nIssues := 0
+	for i := range diags {
+		ctx.Log.Info(formatDiagnosis(&diags[i]))
+		nIssues += len(diags[i].Issues)
+	}
+
+	if nIssues == 0 {
+		ctx.Log.Info("No issues found.")
+
+		return nil
+	} else if !fix {
+		ctx.Log.InfoF("Found '%v' issue(s).\n"+
+			"Run 'git hooks doctor --fix' to repair them.", nIssues)
+
+		return ctx.NewCmdExit(1, "Found '%v' issue(s).", nIssues)
+	}
+
+	uiSettings := inst.UISettings{PromptCtx: ctx.PromptCtx}
+	allFixed := true
+	registeredChanged := false
+
+	for i := range diags {
+		diag := &diags[i]
+
+		switch {
+		case diag.IsHealthy():
+			continue
+		case strs.IsEmpty(diag.GitDir):
+			allFixed = inst.FixGlobal(ctx.Log, ctx.GitX, diag, lfsHooksCache, &uiSettings) && allFixed
+		case diag.Issues[0].Type == inst.IssueTypeV.MissingRepo:
+			ctx.Log.InfoF("Unregistering missing repository '%s'.", diag.GitDir)
+			registered.Remove(diag.GitDir)
+			registeredChanged = true
+		default:
+			allFixed = inst.FixRepo(ctx.Log, diag, lfsHooksCache, &uiSettings) && allFixed
+		}
+	}
+
+	if registeredChanged {
+		err = registered.Store(ctx.InstallDir)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not store register file in '%s'.", ctx.InstallDir)
+	}
+
+	if !allFixed {
+		return ctx.NewCmdExit(1, "Not all issues could be repaired.")
+	}
+
+	ctx.Log.InfoF("Repaired '%v' issue(s).", nIssues)
+
+	return nil
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	fix := false
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor [flags]",
+		Short: "Checks the installed run-wrappers in all registered repositories.",
+		Long: `Checks the Githooks installation for drifts.
+
+Checks the global hooks directory maintained by Githooks and
+every registered repository and reports:
+
+- missing repositories,
+- missing, stale (not matching the current version) or foreign hooks
+  in place of run-wrappers,
+- run-wrappers replaced by Git LFS hooks,
+- a missing or wrong run-wrapper link ('core.hooksPath'),
+- broken shared hook repository clones.
+
+Use '--fix' to repair all issues by reinstalling the run-wrappers,
+recloning broken shared repositories and unregistering missing repositories.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(ctx, fix)
+		},
+	}
+
+	doctorCmd.Flags().BoolVar(&fix, "fix", false, "Repair all detected issues.")
+
+	doctorCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, doctorCmd)
+}
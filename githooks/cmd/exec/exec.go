@@ -27,8 +27,9 @@ func execPath(
 	path := path.Join(res.HooksDir, res.NamespacePath)
 
 	envs := namespaceEnvs.Get(res.Namespace)
-	cmd, err := hooks.GetHookRunCmd(
+	cmd, environment, err := hooks.GetHookRunCmd(
 		git.NewCtxAt(repoDir),
+		getHookName(res.NamespacePath),
 		path,
 		res.RepositoryRoot,
 		res.HooksDir,
@@ -41,12 +42,18 @@ func execPath(
 		return err
 	}
 
+	if environment != nil {
+		err = environment.Setup(ctx.Log)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not set up environment for '%s'.", opts.NamespacePath)
+	}
+
 	hook := hooks.Hook{
 		IExecutable:   cmd,
 		Path:          path,
 		Namespace:     res.Namespace,
 		NamespacePath: res.NamespacePath,
 		NamespaceEnvs: envs,
+		Environment:   environment,
 		Active:        true,
 		Trusted:       true,
 	}
@@ -61,6 +68,9 @@ func execPath(
 		&execx,
 		hookCmds,
 		execRes,
+		nil,
+		nil,
+		nil,
 		func(res ...hooks.HookResult) { logHookResults(ctx.Log, res...) },
 		opts.Args...,
 	)
@@ -70,6 +80,17 @@ func execPath(
 	return ctx.NewCmdExit(execRes[0].ExitCode, "Execution failed.")
 }
 
+// getHookName gets the Git hook name of the namespace path `nsPath`
+// if it is inside a hook directory, e.g. `pre-commit/check.yaml`.
+func getHookName(nsPath string) string {
+	hookName, _, _ := strings.Cut(nsPath, "/")
+	if strs.Includes(hooks.SupportedHookNames, hookName) {
+		return hookName
+	}
+
+	return ""
+}
+
 func runExec(ctx *ccm.CmdContext, opts execCmdOptions) (exitCode error) {
 	ctx.WrapPanicExitCode()
 	repoDir, _, _ := ccm.AssertRepoRoot(ctx)
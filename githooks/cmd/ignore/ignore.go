@@ -17,6 +17,7 @@ type ignoreActionOptions struct {
 	UseRepository bool   // Use repositories ignore file.
 	HookName      string // Use the subfolder 'HookName''s ignore file.
 	All           bool   // If an `--all` flags was given.
+	Worktree      bool   // Use the worktree's user ignore file (when shared across worktrees).
 }
 
 type ignoreShowOptions struct {
@@ -29,17 +30,17 @@ func loadIgnoreFile(
 	ctx *ccm.CmdContext,
 	ignAct *ignoreActionOptions,
 	repoRoot string,
-	gitDir string) (file string, patterns hooks.HookPatterns) {
+	stateDirs hooks.UserStateDirs) (file string, patterns hooks.HookPatterns) {
 	if ignAct.UseRepository {
 		ctx.Log.PanicIfF(
 			strs.IsNotEmpty(ignAct.HookName) &&
-				!strs.Includes(hooks.ManagedHookNames, ignAct.HookName),
+				!strs.Includes(hooks.SupportedHookNames, ignAct.HookName),
 			"Given hook name '%s' is not any of the hook names:\n%s", ignAct.HookName,
 			ccm.GetFormattedHookList(""))
 
 		file = hooks.GetHookIgnoreFileHooksDir(hooks.GetGithooksDir(repoRoot), ignAct.HookName)
 	} else {
-		file = hooks.GetHookIgnoreFileGitDir(gitDir)
+		file = hooks.GetHookIgnoreFileGitDir(stateDirs.Store)
 	}
 
 	var err error
@@ -55,20 +56,46 @@ func loadIgnoreFile(
 func runIgnoreAddPattern(
 	ctx *ccm.CmdContext, ignAct *ignoreActionOptions,
 	remove bool, patterns *hooks.HookPatterns) {
-	repoRoot, _, gitDirWorktree := ccm.AssertRepoRoot(ctx)
-	file, ps := loadIgnoreFile(ctx, ignAct, repoRoot, gitDirWorktree)
+	repoRoot, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
+	stateDirs := hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, ignAct.Worktree)
+	file, ps := loadIgnoreFile(ctx, ignAct, repoRoot, stateDirs)
+
+	// The worktree's user ignore file overrides the shared ones.
+	nLayers := len(stateDirs.Layers)
+	isOverride := !ignAct.UseRepository && nLayers > 1 && stateDirs.Store == stateDirs.Layers[nLayers-1]
 
 	var text string
 
 	if remove {
 		switch {
+		case ignAct.All:
+			if ps.IsEmpty() {
+				ctx.Log.WarnF("Ignore file '%s' is empty or does not exist.\nNothing to remove!", file)
+
+				return
+			}
+
+			removed := ps.RemoveAll()
+			text = strs.Fmt("Removed '%v' entries from", removed)
+
+		case isOverride:
+			// Entries in the shared ignore files are inverted in the worktree.
+			shared := getUserIgnores(ctx, stateDirs.Layers[:nLayers-1])
+			inherited := hooks.HookPatterns{
+				Patterns: strs.Filter(patterns.Patterns,
+					func(p string) bool { return strs.Includes(shared.Patterns, p) }),
+				NamespacePaths: strs.Filter(patterns.NamespacePaths,
+					func(p string) bool { return strs.Includes(shared.NamespacePaths, p) })}
+
+			removed := ps.Remove(patterns)
+			inverted := ps.AddInversionsUnique(&inherited)
+			text = strs.Fmt("Removed '%v' and inverted '%v' shared of '%v' given entries in",
+				removed, inverted, patterns.GetCount())
+
 		case ps.IsEmpty():
 			ctx.Log.WarnF("Ignore file '%s' is empty or does not exist.\nNothing to remove!", file)
 
 			return
-		case ignAct.All:
-			removed := ps.RemoveAll()
-			text = strs.Fmt("Removed '%v' entries from", removed)
 
 		default:
 			removed := ps.Remove(patterns)
@@ -82,6 +109,11 @@ func runIgnoreAddPattern(
 			}
 		}
 
+		if isOverride {
+			// Ignoring again undoes an inversion of the worktree.
+			ps.RemoveInversions(patterns)
+		}
+
 		added := ps.AddUnique(patterns)
 		text = strs.Fmt("Added '%v' of given '%v' entries to",
 			added, patterns.GetCount())
@@ -96,8 +128,24 @@ func runIgnoreAddPattern(
 	ctx.Log.InfoF("%s file '%s'.", text, file)
 }
 
+// getUserIgnores loads the combined user ignore patterns in the Git directories `gitDirs`.
+func getUserIgnores(ctx *ccm.CmdContext, gitDirs []string) (patterns hooks.HookPatterns) {
+	for _, dir := range gitDirs {
+		file := hooks.GetHookIgnoreFileGitDir(dir)
+		if !cm.IsFile(file) {
+			continue
+		}
+
+		ps, err := hooks.LoadIgnorePatterns(file)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not load ignore file '%s'.", file)
+		patterns.Add(&ps)
+	}
+
+	return
+}
+
 func runIgnoreShow(ctx *ccm.CmdContext, ignShow *ignoreShowOptions) {
-	repoRoot, _, gitDirWorktree := ccm.AssertRepoRoot(ctx)
+	repoRoot, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
 	var sb strings.Builder
 	count := 0
 
@@ -114,7 +162,13 @@ func runIgnoreShow(ctx *ccm.CmdContext, ignShow *ignoreShowOptions) {
 	}
 
 	if ignShow.User {
-		print(hooks.GetHookIgnoreFileGitDir(gitDirWorktree), "user:local")
+		for _, dir := range hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, false).Layers {
+			if dir == gitDirWorktree {
+				print(hooks.GetHookIgnoreFileGitDir(dir), "user:local")
+			} else {
+				print(hooks.GetHookIgnoreFileGitDir(dir), "user:shared")
+			}
+		}
 	}
 
 	if ignShow.Repository {
@@ -123,7 +177,7 @@ func runIgnoreShow(ctx *ccm.CmdContext, ignShow *ignoreShowOptions) {
 
 		for _, file := range hooks.GetHookIgnoreFilesHooksDir(
 			root,
-			hooks.ManagedHookNames) {
+			hooks.SupportedHookNames) {
 			print(file, "repo")
 		}
 	}
@@ -142,6 +196,13 @@ func addIgnoreOpts(c *cobra.Command, actOpts *ignoreActionOptions, addAllFlag bo
 in the subfolder '<hook-name>'.
 (only together with '--repository' flag.)`)
 
+	c.Flags().BoolVar(&actOpts.Worktree,
+		"worktree", false,
+		`The action affects the user ignore list of the current worktree
+which overrides the list shared across worktrees.
+Removed entries of the shared list are inverted in this list.
+(only if 'githooks.shareWorktreeState' is enabled.)`)
+
 	if addAllFlag {
 		c.Flags().BoolVar(&actOpts.All,
 			"all", false, `Remove all patterns in the targeted ignore file.
@@ -287,7 +348,7 @@ about the pattern syntax and namespace paths.`,
 		}}
 
 	ignoreShowCmd.Flags().BoolVar(&ignoreShowOpts.User,
-		"user", false, "Show the path of the local user ignore file.")
+		"user", false, "Show the paths of the user ignore files.")
 	ignoreShowCmd.Flags().BoolVar(&ignoreShowOpts.Repository,
 		"repository", false, "Show the paths of possible repository ignore files.")
 
@@ -2,7 +2,8 @@ package installer
 
 // Arguments represents all CLI arguments for the installer.
 type Arguments struct {
-	Config string
+	Config  string
+	Profile string // Install profile YAML file.
 
 	Log                  string // The log file.
 	InternalTempDir      string // The temporary directory to use.
@@ -60,6 +60,32 @@ func initArgs(log cm.ILogContext, args *Arguments, vi *viper.Viper) {
 
 	err := vi.Unmarshal(&args)
 	log.AssertNoErrorPanicF(err, "Could not unmarshal parameters.")
+
+	if strs.IsEmpty(args.Profile) {
+		return
+	}
+
+	args.Profile, err = filepath.Abs(args.Profile)
+	log.AssertNoErrorPanicF(err, "Could not make path '%s' absolute.", args.Profile)
+	args.Profile = filepath.ToSlash(args.Profile)
+
+	if args.InternalPostDispatch {
+		// The profile's installer arguments are already merged.
+		return
+	}
+
+	// Merge the installer arguments of the profile.
+	// Flags given on the command line take precedence.
+	profile, err := install.LoadProfile(args.Profile)
+	log.AssertNoErrorPanicF(err, "Could not load install profile '%s'.", args.Profile)
+
+	err = vi.MergeConfigMap(profile.Installer.InstallerArgs())
+	log.AssertNoErrorPanicF(err, "Could not merge install profile '%s'.", args.Profile)
+
+	profilePath := args.Profile
+	err = vi.Unmarshal(&args)
+	log.AssertNoErrorPanicF(err, "Could not unmarshal parameters.")
+	args.Profile = profilePath
 }
 
 func writeArgs(log cm.ILogContext, file string, args *Arguments) {
@@ -74,6 +100,8 @@ var MaintainedHooksDesc = "Any argument can be a hook name '<hookName>', 'all' o
 	"as first argument:\n" +
 	"  - 'all' : All hooks supported by Githooks.\n" +
 	"  - 'server' : Only server hooks supported by Githooks.\n" +
+	"The opt-in hooks 'fsmonitor-watchman', 'proc-receive' and 'p4-*'\n" +
+	"are not part of 'all' or 'server' and need to be given explicitly.\n" +
 	"You can list them separately or comma-separated in one argument."
 
 func defineArguments(cmd *cobra.Command, vi *viper.Viper) {
@@ -86,6 +114,13 @@ func defineArguments(cmd *cobra.Command, vi *viper.Viper) {
 	cmd.PersistentFlags().String("log", "", "Log file path (only for installer).")
 	cm.AssertNoErrorPanic(cmd.MarkPersistentFlagFilename("log"))
 
+	cmd.PersistentFlags().String("profile", "",
+		"Install profile YAML file which defines the installer arguments\n"+
+			"and the global settings (shared repositories, trust policy, etc.).\n"+
+			"Flags given on the command line take precedence.\n"+
+			"See 'git hooks config export-profile'.")
+	cm.AssertNoErrorPanic(cmd.MarkPersistentFlagFilename("profile"))
+
 	// User commands
 	cmd.PersistentFlags().Bool("dry-run", false,
 		"Dry run the installation showing what's being done.")
@@ -181,6 +216,8 @@ func defineArguments(cmd *cobra.Command, vi *viper.Viper) {
 		vi.BindPFlag("config", cmd.PersistentFlags().Lookup("config")))
 	cm.AssertNoErrorPanic(
 		vi.BindPFlag("log", cmd.PersistentFlags().Lookup("log")))
+	cm.AssertNoErrorPanic(
+		vi.BindPFlag("profile", cmd.PersistentFlags().Lookup("profile")))
 	cm.AssertNoErrorPanic(
 		vi.BindPFlag("dryRun", cmd.PersistentFlags().Lookup("dry-run")))
 	cm.AssertNoErrorPanic(
@@ -1129,6 +1166,28 @@ func setupSharedRepositories(
 	}
 }
 
+func setupProfileSettings(
+	log cm.ILogContext,
+	gitx *git.Context,
+	installDir string,
+	profileFile string,
+	dryRun bool) install.Profile {
+	profile, err := install.LoadProfile(profileFile)
+	log.AssertNoErrorPanicF(err, "Could not load install profile '%s'.", profileFile)
+
+	log.InfoF("Applying settings from install profile '%s'.", profileFile)
+	err = install.ApplyProfileSettings(log, gitx, &profile.Settings, dryRun)
+	log.AssertNoErrorF(err, "Could not apply all settings from install profile '%s'.", profileFile)
+
+	if !dryRun && len(profile.Settings.SharedRepos) != 0 {
+		updated, e := hooks.UpdateAllSharedHooks(log, gitx, installDir, "", nil)
+		log.ErrorIf(e != nil, "Could not update shared hook repositories.")
+		log.InfoF("Updated '%v' shared hook repositories.", updated)
+	}
+
+	return profile
+}
+
 func storeSettings(log cm.ILogContext, settings *Settings, uiSettings *install.UISettings) {
 	// Store cached UI values back.
 
@@ -1249,6 +1308,11 @@ func runInstaller(
 		uiSettings.PromptCtx)
 
 	if !cm.PackageManagerEnabled && len(args.InternalBinaries) != 0 {
+		if strs.IsNotEmpty(args.InternalUpdateFromVersion) && !args.DryRun {
+			err = updates.StoreRollback(settings.InstallDir, args.InternalUpdateFromVersion, build.BuildVersion)
+			log.AssertNoErrorF(err, "Could not store previous version for a rollback.")
+		}
+
 		installBinaries(
 			log,
 			settings.TempDir,
@@ -1309,7 +1373,12 @@ func runInstaller(
 			uiSettings)
 	}
 
-	if !args.NonInteractive {
+	var profile install.Profile
+	if strs.IsNotEmpty(args.Profile) {
+		profile = setupProfileSettings(log, gitx, settings.InstallDir, args.Profile, args.DryRun)
+	}
+
+	if !args.NonInteractive && profile.Settings.SharedRepos == nil {
 		setupSharedRepositories(
 			log,
 			settings.InstallDir,
@@ -1425,6 +1494,9 @@ func runInstall(cmd *cobra.Command, ctx *ccm.CmdContext, vi *viper.Viper) error
 
 	if !args.DryRun {
 		setInstallDir(log, ctx.GitX, settings.InstallDir)
+
+		_, err := updates.SetupUpdateRolloutID(ctx.GitX)
+		log.AssertNoErrorF(err, "Could not store the update rollout id.")
 	}
 
 	if !args.InternalPostDispatch {
@@ -0,0 +1,110 @@
+package list
+
+import (
+	"encoding/json"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ListFormats are the supported machine readable formats of `list`.
+var ListFormats = []string{"json", "yaml"}
+
+// HookInfo is the machine readable state of a listed hook.
+type HookInfo struct {
+	Type          string   `json:"type"                  yaml:"type"`
+	NamespacePath string   `json:"namespacePath"         yaml:"namespacePath"`
+	Path          string   `json:"path"                  yaml:"path"`
+	Tag           string   `json:"tag"                   yaml:"tag"`
+	SharedURL     string   `json:"sharedUrl,omitempty"   yaml:"sharedUrl,omitempty"`
+	Active        bool     `json:"active"                yaml:"active"`
+	Trusted       bool     `json:"trusted"               yaml:"trusted"`
+	SHA1          string   `json:"sha1"                  yaml:"sha1"`
+	BatchName     string   `json:"batchName"             yaml:"batchName"`
+	Command       string   `json:"command"               yaml:"command"`
+	Args          []string `json:"args"                  yaml:"args"`
+	Image         string   `json:"image,omitempty"       yaml:"image,omitempty"`
+	Interpreter   string   `json:"interpreter,omitempty" yaml:"interpreter,omitempty"`
+}
+
+// PendingSharedRepo is a shared repository which is not yet cloned.
+type PendingSharedRepo struct {
+	URL string `json:"url" yaml:"url"`
+	Tag string `json:"tag" yaml:"tag"`
+}
+
+// ListResult is the machine readable output of `list`.
+type ListResult struct {
+	GithooksDisabled bool                `json:"githooksDisabled" yaml:"githooksDisabled"`
+	Hooks            []HookInfo          `json:"hooks"            yaml:"hooks"`
+	PendingShared    []PendingSharedRepo `json:"pendingShared"    yaml:"pendingShared"`
+}
+
+// FormatListResult formats the listed hooks in `format` (`json` or `yaml`).
+func FormatListResult(result *ListResult, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(result, "", "  ")
+
+		return append(out, '\n'), err
+	case "yaml":
+		return yaml.Marshal(result)
+	default:
+		return nil, cm.ErrorF("Format '%s' is not supported.", format)
+	}
+}
+
+func getHookInfos(
+	log cm.ILogContext,
+	hookName string,
+	sections []HookSection,
+	onlyListActiveHooks bool) (infos []HookInfo) {
+	for _, section := range sections {
+		for i := range section.Hooks {
+			hook := &section.Hooks[i]
+			if onlyListActiveHooks && !hook.Active {
+				continue
+			}
+
+			err := hook.AssertSHA1()
+			log.AssertNoErrorF(err, "Could not compute SHA1 hash of '%s'.", hook.Path)
+
+			image, err := hook.GetRunImage(hookName)
+			log.AssertNoErrorF(err, "Could not get container image of '%s'.", hook.Path)
+
+			infos = append(infos,
+				HookInfo{
+					Type:          hookName,
+					NamespacePath: hook.NamespacePath,
+					Path:          hook.Path,
+					Tag:           section.Tag,
+					SharedURL:     section.SharedURL,
+					Active:        hook.Active,
+					Trusted:       hook.Trusted,
+					SHA1:          hook.SHA1,
+					BatchName:     hook.BatchName,
+					Command:       hook.GetCommand(),
+					Args:          hook.GetArgs(),
+					Image:         image,
+					Interpreter:   hook.Interpreter})
+		}
+	}
+
+	return
+}
+
+func getPendingSharedInfos(shared hooks.SharedRepos) []PendingSharedRepo {
+	infos := make([]PendingSharedRepo, 0, shared.GetCount())
+	tagNames := hooks.GetSharedRepoTagNames()
+
+	for i := range shared {
+		for j := range shared[i] {
+			infos = append(infos,
+				PendingSharedRepo{URL: shared[i][j].OriginalURL, Tag: tagNames[i]})
+		}
+	}
+
+	return infos
+}
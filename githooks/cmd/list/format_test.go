@@ -0,0 +1,46 @@
+package list
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatListResult(t *testing.T) {
+	result := ListResult{
+		Hooks: []HookInfo{
+			{
+				Type:          "pre-commit",
+				NamespacePath: "ns:a/pre-commit/check.yaml",
+				Tag:           "shared:repo",
+				SharedURL:     "https://example.com/a.git",
+				Active:        true,
+				Command:       "check",
+				Args:          []string{"--all"},
+				Image:         "a-check:1.0",
+			},
+		},
+		PendingShared: []PendingSharedRepo{{URL: "https://example.com/b.git", Tag: "shared:local"}},
+	}
+
+	out, err := FormatListResult(&result, "json")
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"namespacePath": "ns:a/pre-commit/check.yaml"`)
+
+	var parsed ListResult
+	require.NoError(t, json.Unmarshal(out, &parsed))
+	assert.Equal(t, result, parsed)
+
+	out, err = FormatListResult(&result, "yaml")
+	require.NoError(t, err)
+
+	parsed = ListResult{}
+	require.NoError(t, yaml.Unmarshal(out, &parsed))
+	assert.Equal(t, result, parsed)
+
+	_, err = FormatListResult(&result, "xml")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package list
 
 import (
 	"io"
+	"os"
 	"path"
 	"strings"
 
@@ -17,26 +18,36 @@ import (
 
 func runList(ctx *ccm.CmdContext,
 	hookNames []string, warnNotFound bool,
-	onlyListActiveHooks bool, withBatchName bool) {
+	onlyListActiveHooks bool, withBatchName bool,
+	format string) {
 	repoDir, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
 
 	repoHooksDir := hooks.GetGithooksDir(repoDir)
-	state, shared, _ := PrepareListHookState(ctx, repoDir, repoHooksDir, gitDirWorktree, hookNames)
+	stateDirs := hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, false)
+	state, shared, _ := PrepareListHookState(ctx, repoDir, repoHooksDir, stateDirs, hookNames)
+
+	var result ListResult
+	if strs.IsNotEmpty(format) {
+		result.GithooksDisabled = state.isGithooksDisabled
+		result.Hooks = []HookInfo{}
+	}
 
 	total := 0
 	for _, hookName := range hookNames {
-		list, count := listHooksForName(
+		sections, count := CollectHooksForName(
 			ctx.Log,
 			hookName,
 			repoDir,
 			gitDir,
 			repoHooksDir,
 			shared,
-			state,
-			onlyListActiveHooks,
-			withBatchName)
+			state)
 
-		if count != 0 {
+		if strs.IsNotEmpty(format) {
+			result.Hooks = append(result.Hooks,
+				getHookInfos(ctx.Log, hookName, sections, onlyListActiveHooks)...)
+		} else if count != 0 {
+			list := listHooksForName(sections, state, onlyListActiveHooks, withBatchName)
 			ctx.Log.InfoF("Hook: '%s' [%v]:%s", hookName, count, list)
 		}
 
@@ -44,6 +55,18 @@ func runList(ctx *ccm.CmdContext,
 	}
 
 	pendingShared := filterPendingSharedRepos(shared)
+
+	if strs.IsNotEmpty(format) {
+		result.PendingShared = getPendingSharedInfos(pendingShared)
+
+		out, err := FormatListResult(&result, format)
+		ctx.Log.AssertNoErrorPanic(err, "Could not format listed hooks.")
+		_, err = os.Stdout.Write(out)
+		ctx.Log.AssertNoErrorPanic(err, "Could not write listed hooks.")
+
+		return
+	}
+
 	printPendingShared(ctx, pendingShared)
 
 	ctx.Log.InfoF("Total listed hooks: '%v'.", total)
@@ -57,10 +80,10 @@ func PrepareListHookState(
 	ctx *ccm.CmdContext,
 	repoDir string,
 	repoHooksDir string,
-	gitDirWorktree string,
+	stateDirs hooks.UserStateDirs,
 	hookNames []string) (state *ListingState, shared hooks.SharedRepos, hookNamespace string) {
 	// Load checksum store
-	checksums, err := hooks.GetChecksumStorage(gitDirWorktree)
+	checksums, err := hooks.GetUserChecksumStorage(stateDirs)
 	ctx.Log.AssertNoErrorF(err, "Errors while loading checksum store.")
 	ctx.Log.DebugF("%s", checksums.Summary())
 
@@ -72,7 +95,7 @@ func PrepareListHookState(
 	}
 
 	// Load ignore patterns
-	ignores, err := hooks.GetIgnorePatterns(repoHooksDir, gitDirWorktree, hookNames, hookNamespace)
+	ignores, err := hooks.GetIgnorePatterns(repoHooksDir, stateDirs.Layers, hookNames, hookNamespace)
 	ctx.Log.AssertNoErrorF(err, "Errors while loading ignore patterns.")
 	ctx.Log.DebugF("User ignore patterns: '%+q'.", ignores.User)
 	ctx.Log.DebugF("Accumuldated repository ignore patterns: '%q'.", ignores.HooksDir)
@@ -122,6 +145,16 @@ type ListingState struct {
 	sharedIgnores ignoresPerHooksDir // sharedIgnores contains all ignores for the shared hooks
 }
 
+// IsGithooksDisabled returns `true` if Githooks is disabled.
+func (s *ListingState) IsGithooksDisabled() bool {
+	return s.isGithooksDisabled
+}
+
+// IsRepoTrusted returns `true` if the repository is trusted.
+func (s *ListingState) IsRepoTrusted() bool {
+	return s.isRepoTrusted
+}
+
 func filterPendingSharedRepos(shared hooks.SharedRepos) (pending hooks.SharedRepos) {
 	pending = hooks.NewSharedRepos(0)
 
@@ -180,16 +213,24 @@ func printPendingShared(ctx *ccm.CmdContext, shared hooks.SharedRepos) {
 	ctx.Log.InfoF("Pending shared hooks [%v]:%s", count, sb.String())
 }
 
-func listHooksForName(
+// HookSection is a set of hooks of the same origin.
+type HookSection struct {
+	Title     string
+	Tag       string
+	SharedURL string
+	Hooks     []hooks.Hook
+}
+
+// CollectHooksForName collects all replaced, repository and
+// shared hooks for the hook `hookName`.
+func CollectHooksForName(
 	log cm.ILogContext,
 	hookName string,
 	repoDir string,
 	gitDir string,
 	repoHooksDir string,
 	shared hooks.SharedRepos,
-	state *ListingState,
-	onlyListActiveHooks bool,
-	withBatchName bool) (string, int) {
+	state *ListingState) (sections []HookSection, count int) {
 	// List replaced hooks (normally only one)
 	gitx := git.NewCtxAt(repoDir)
 	replacedHooks := GetAllHooksIn(
@@ -203,6 +244,15 @@ func listHooksForName(
 		repoDir, repoHooksDir, hookName,
 		hooks.NamespaceRepositoryHook, state, false, false)
 
+	// List built-in hooks
+	builtinHooks, err := hooks.GetBuiltinHooks(repoHooksDir, hookName,
+		func(namespacePath string) bool {
+			ignored, _ := state.Ignores.IsIgnored(namespacePath)
+
+			return ignored
+		})
+	log.AssertNoErrorF(err, "Could not get built-in hooks in '%s'.", repoHooksDir)
+
 	// List all shared hooks
 	sharedCount := 0
 	all := make([]SharedHooks, 0, shared.GetCount())
@@ -214,49 +264,63 @@ func listHooksForName(
 		all = append(all, coll...)
 	}
 
+	sections = make([]HookSection, 0, 3+len(all)) //nolint:mnd
+	sections = append(sections,
+		HookSection{Title: "Replaced:", Tag: "replaced", Hooks: replacedHooks},
+		HookSection{Title: "Repository:", Tag: "repo", Hooks: repoHooks},
+		HookSection{Title: "Built-in:", Tag: "builtin", Hooks: builtinHooks})
+
+	tagNames := hooks.GetSharedRepoTagNames()
+	for i := range all {
+		sections = append(sections,
+			HookSection{
+				Title:     strs.Fmt("Shared '%s':", all[i].Repo.OriginalURL),
+				Tag:       tagNames[all[i].Category],
+				SharedURL: all[i].Repo.OriginalURL,
+				Hooks:     all[i].Hooks})
+	}
+
+	return sections, len(replacedHooks) + len(repoHooks) + len(builtinHooks) + sharedCount
+}
+
+func listHooksForName(
+	sections []HookSection,
+	state *ListingState,
+	onlyListActiveHooks bool,
+	withBatchName bool) string {
 	var sb strings.Builder
 	paddingMax := 60
-	printHooks := func(hooks []hooks.Hook, title string, category string) {
-		if len(hooks) == 0 {
-			return
+
+	for _, section := range sections {
+		if len(section.Hooks) == 0 {
+			continue
 		}
 
-		padding := findPaddingListHooks(hooks, paddingMax)
-		_, err := strs.FmtW(&sb, "\n %s", title)
+		padding := findPaddingListHooks(section.Hooks, paddingMax)
+		_, err := strs.FmtW(&sb, "\n %s", section.Title)
 		cm.AssertNoErrorPanicF(err, "Could not write hook state.")
 
-		for i := range hooks {
-			if onlyListActiveHooks && !hooks[i].Active {
+		for i := range section.Hooks {
+			if onlyListActiveHooks && !section.Hooks[i].Active {
 				continue
 			}
 
 			sb.WriteString("\n")
 			formatHookState(
-				&sb, &hooks[i],
-				category, withBatchName,
+				&sb, &section.Hooks[i],
+				section.Tag, withBatchName,
 				state.isGithooksDisabled, padding, "  ")
 		}
 	}
 
-	printHooks(replacedHooks, "Replaced:", "replaced")
-	printHooks(repoHooks, "Repository:", "repo")
-
-	tagNames := hooks.GetSharedRepoTagNames()
-	for i := range all {
-		printHooks(
-			all[i].Hooks,
-			strs.Fmt("Shared '%s':", all[i].Repo.OriginalURL),
-			tagNames[all[i].Category])
-	}
-
-	return sb.String(), len(replacedHooks) + len(repoHooks) + sharedCount
+	return sb.String()
 }
 
 func findPaddingListHooks(hooks []hooks.Hook, maxPadding int) int {
 	const addChars = 3
 	max := 0
 	for i := range hooks {
-		max = math.MaxInt(len(path.Base(hooks[i].Path))+addChars, max)
+		max = math.MaxInt(len(GetHookDisplayName(&hooks[i]))+addChars, max)
 	}
 
 	return math.MinInt(max, maxPadding)
@@ -321,12 +385,12 @@ func GetAllHooksIn(
 	state *ListingState,
 	addInternalIgnores bool,
 	isReplacedHook bool) []hooks.Hook {
-	isTrusted := func(hookPath string) (bool, string) {
+	isTrusted := func(hookPath string, override string) (bool, string) {
 		if state.isRepoTrusted {
 			return true, ""
 		}
 
-		trusted, sha, e := state.Checksums.IsTrusted(hookPath)
+		trusted, sha, e := state.Checksums.IsTrusted(hookPath, override)
 		log.AssertNoErrorF(e, "Could not check trust status '%s'.", hookPath)
 
 		return trusted, sha
@@ -382,6 +446,16 @@ func GetAllHooksIn(
 	return allHooks
 }
 
+// GetHookDisplayName gets the file name of the hook or
+// `hooks.yaml#<entry>` for hooks declared in a hooks manifest.
+func GetHookDisplayName(hook *hooks.Hook) string {
+	if strs.IsNotEmpty(hook.Entry) {
+		return path.Base(hook.Path) + "#" + hook.Entry
+	}
+
+	return path.Base(hook.Path)
+}
+
 func formatHookState(
 	w io.Writer,
 	hook *hooks.Hook,
@@ -396,8 +470,9 @@ func formatHookState(
 	const categeoryFmt = ", type: '%[4]s'"
 	const namespaceFmt = ", ns-path: '%[5]s'"
 	const batchIDFmt = ", batch: '%[6]s'"
+	const interpreterFmt = ", interpreter: '%[7]s'"
 
-	hookPath := strs.Fmt("'%s'", path.Base(hook.Path))
+	hookPath := strs.Fmt("'%s'", GetHookDisplayName(hook))
 	if isGithooksDisabled {
 		fmt := hooksFmt + disabledStateFmt + categeoryFmt + namespaceFmt
 		if withBatchName {
@@ -427,8 +502,12 @@ func formatHookState(
 		fmt += batchIDFmt
 	}
 
+	if strs.IsNotEmpty(hook.Interpreter) {
+		fmt += interpreterFmt
+	}
+
 	_, err := strs.FmtW(w, fmt,
-		hookPath, active, trusted, categeory, hook.NamespacePath, hook.BatchName)
+		hookPath, active, trusted, categeory, hook.NamespacePath, hook.BatchName, hook.Interpreter)
 
 	cm.AssertNoErrorPanicF(err, "Could not write hook state.")
 }
@@ -437,6 +516,7 @@ func formatHookState(
 func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 	onlyListActiveHooks := false
 	withBatchName := false
+	format := ""
 
 	listCmd := &cobra.Command{
 		Use:   "list [type]...",
@@ -446,22 +526,27 @@ func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 			"If 'type' is given, then it only lists the hooks for that trigger event.\n" +
 			"The supported hooks are:\n\n" +
 			ccm.GetFormattedHookList("") + "\n\n" +
-			"The value 'ns-path' is the namespaced path which is used for the ignore patterns.",
+			"The value 'ns-path' is the namespaced path which is used for the ignore patterns.\n\n" +
+			"With '--format json' or '--format yaml' all hooks are printed\n" +
+			"as a machine readable document for tools to consume.",
 
 		PreRun: ccm.PanicIfNotRangeArgs(ctx.Log, 0, -1),
 
 		Run: func(cmd *cobra.Command, args []string) {
+			ctx.Log.PanicIfF(strs.IsNotEmpty(format) && !strs.Includes(ListFormats, format),
+				"Format '%s' is not supported. Supported formats are '%q'.", format, ListFormats)
+
 			if len(args) != 0 {
 				args = strs.MakeUnique(args)
 
 				for _, h := range args {
-					ctx.Log.PanicIfF(!strs.Includes(hooks.ManagedHookNames, h),
+					ctx.Log.PanicIfF(!strs.Includes(hooks.SupportedHookNames, h),
 						"Hook type '%s' is not managed by Githooks.", h)
 				}
 
-				runList(ctx, args, true, onlyListActiveHooks, withBatchName)
+				runList(ctx, args, true, onlyListActiveHooks, withBatchName, format)
 			} else {
-				runList(ctx, hooks.ManagedHookNames, false, onlyListActiveHooks, withBatchName)
+				runList(ctx, hooks.SupportedHookNames, false, onlyListActiveHooks, withBatchName, format)
 			}
 		}}
 
@@ -469,6 +554,8 @@ func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
 		BoolVar(&onlyListActiveHooks, "active", false, "Only list hooks with state 'active'.")
 	listCmd.Flags().
 		BoolVar(&withBatchName, "batch-name", false, "Also show the parallel batch name.")
+	listCmd.Flags().
+		StringVar(&format, "format", "", "Output format: 'json' or 'yaml'.")
 
 	listCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
 		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
@@ -5,10 +5,13 @@ import (
 	"os"
 
 	"github.com/gabyx/githooks/githooks/build"
+	"github.com/gabyx/githooks/githooks/cmd/ci"
 	ccm "github.com/gabyx/githooks/githooks/cmd/common"
 	inst "github.com/gabyx/githooks/githooks/cmd/common/install"
 	"github.com/gabyx/githooks/githooks/cmd/config"
+	"github.com/gabyx/githooks/githooks/cmd/dashboard"
 	"github.com/gabyx/githooks/githooks/cmd/disable"
+	"github.com/gabyx/githooks/githooks/cmd/doctor"
 	"github.com/gabyx/githooks/githooks/cmd/exec"
 	"github.com/gabyx/githooks/githooks/cmd/ignore"
 	"github.com/gabyx/githooks/githooks/cmd/images"
@@ -16,10 +19,14 @@ import (
 	"github.com/gabyx/githooks/githooks/cmd/installer"
 	"github.com/gabyx/githooks/githooks/cmd/list"
 	"github.com/gabyx/githooks/githooks/cmd/readme"
+	"github.com/gabyx/githooks/githooks/cmd/run"
 	"github.com/gabyx/githooks/githooks/cmd/shared"
+	"github.com/gabyx/githooks/githooks/cmd/stats"
 	"github.com/gabyx/githooks/githooks/cmd/trust"
+	"github.com/gabyx/githooks/githooks/cmd/tui"
 	"github.com/gabyx/githooks/githooks/cmd/uninstaller"
 	"github.com/gabyx/githooks/githooks/cmd/update"
+	"github.com/gabyx/githooks/githooks/cmd/worktrees"
 	cm "github.com/gabyx/githooks/githooks/common"
 	"github.com/gabyx/githooks/githooks/git"
 	"github.com/gabyx/githooks/githooks/hooks"
@@ -62,16 +69,23 @@ func NewSettings(
 }
 
 func addSubCommands(cmd *cobra.Command, ctx *ccm.CmdContext) {
+	cmd.AddCommand(ci.NewCmd(ctx))
 	cmd.AddCommand(config.NewCmd(ctx))
+	cmd.AddCommand(dashboard.NewCmd(ctx))
 	cmd.AddCommand(disable.NewCmd(ctx))
+	cmd.AddCommand(doctor.NewCmd(ctx))
 	cmd.AddCommand(ignore.NewCmd(ctx))
 	cmd.AddCommand(install.NewCmd(ctx)...)
 	cmd.AddCommand(list.NewCmd(ctx))
 	cmd.AddCommand(readme.NewCmd(ctx))
+	cmd.AddCommand(run.NewCmd(ctx))
 	cmd.AddCommand(shared.NewCmd(ctx))
+	cmd.AddCommand(stats.NewCmd(ctx))
 	cmd.AddCommand(images.NewCmd(ctx))
 	cmd.AddCommand(trust.NewCmd(ctx))
+	cmd.AddCommand(tui.NewCmd(ctx))
 	cmd.AddCommand(update.NewCmd(ctx))
+	cmd.AddCommand(worktrees.NewCmd(ctx))
 	cmd.AddCommand(exec.NewCmd(ctx))
 
 	cmd.AddCommand(installer.NewCmd(ctx))
@@ -0,0 +1,131 @@
+package run
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	"github.com/gabyx/githooks/githooks/runner"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+)
+
+type runOptions struct {
+	HookName  string
+	Args      []string
+	DryRun    bool
+	StdinFile string
+}
+
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// getPrePushDefaults gets the default arguments and the standard input
+// Git would pass to `pre-push` when pushing the current branch to its remote.
+func getPrePushDefaults(ctx *ccm.CmdContext, args []string) ([]string, []byte) {
+	branch, err := ctx.GitX.GetCurrentBranch()
+	ctx.Log.AssertNoErrorPanic(err, "Could not get current branch.")
+
+	remote := ctx.GitX.GetConfig(strs.Fmt("branch.%s.remote", branch), git.LocalScope)
+	if strs.IsEmpty(remote) {
+		remote = "origin"
+	}
+
+	if len(args) == 0 {
+		url := ctx.GitX.GetConfig(strs.Fmt("remote.%s.url", remote), git.LocalScope)
+		args = []string{remote, url}
+	}
+
+	localSHA, err := ctx.GitX.Get("rev-parse", git.HEAD)
+	ctx.Log.AssertNoErrorPanic(err, "Could not get commit of 'HEAD'.")
+
+	remoteSHA, err := ctx.GitX.Get("rev-parse", "--verify", "-q",
+		strs.Fmt("refs/remotes/%s/%s", args[0], branch))
+	if err != nil || strs.IsEmpty(remoteSHA) {
+		remoteSHA = zeroSHA
+	}
+
+	ref := "refs/heads/" + branch
+	stdin := strs.Fmt("%s %s %s %s\n", ref, localSHA, ref, remoteSHA)
+
+	return args, []byte(stdin)
+}
+
+func runRun(ctx *ccm.CmdContext, opts *runOptions) {
+	ctx.Log.PanicIfF(!strs.Includes(hooks.SupportedHookNames, opts.HookName),
+		"Hook name '%s' is not supported by Githooks.\nSupported hooks are:\n%s",
+		opts.HookName, ccm.GetFormattedHookList(" "))
+
+	repoDir, gitDir, _ := ccm.AssertRepoRoot(ctx)
+
+	var stdin []byte
+	var err error
+
+	switch {
+	case opts.StdinFile == "-":
+		stdin, err = io.ReadAll(os.Stdin)
+		ctx.Log.AssertNoErrorPanic(err, "Could not read standard input.")
+	case strs.IsNotEmpty(opts.StdinFile):
+		stdin, err = os.ReadFile(opts.StdinFile)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not read file '%s'.", opts.StdinFile)
+	case opts.HookName == "pre-push":
+		opts.Args, stdin = getPrePushDefaults(ctx, opts.Args)
+	default:
+		stdin = []byte{}
+	}
+
+	if len(stdin) != 0 {
+		ctx.Log.InfoF("Using standard input:\n%s", strings.TrimRight(string(stdin), "\n"))
+	}
+
+	runner.Run(ctx.Log, repoDir,
+		&runner.Options{
+			HookPath: path.Join(ccm.GetHooksDir(ctx, repoDir, gitDir), opts.HookName),
+			Args:     opts.Args,
+			Simulate: true,
+			DryRun:   opts.DryRun,
+			Stdin:    stdin})
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	var opts runOptions
+
+	runCmd := &cobra.Command{
+		Use:   "run hook-name [args...]",
+		Short: "Simulates a Git hook invocation.",
+		Long: `Simulates a Git hook invocation of 'hook-name' with arguments 'args'
+in the current repository.
+
+All hooks (repository hooks and all shared hooks) are collected
+and executed the same way as Githooks does when Git runs the hook,
+honoring all ignores and the trust settings.
+In contrast to a real invocation, no update checks and shared hooks updates
+are performed and Git LFS hooks are not executed.
+
+The standard input of all hooks is given by '--stdin-file'
+('-' for the standard input). For 'pre-push' the arguments and
+the standard input default to pushing the current branch to its remote.`,
+		PreRun: ccm.PanicIfNotRangeArgs(ctx.Log, 1, -1),
+		Run: func(c *cobra.Command, args []string) {
+			opts.HookName = args[0]
+			opts.Args = args[1:]
+
+			runRun(ctx, &opts)
+		}}
+
+	runCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false,
+		"Only show which hooks would be executed.")
+	runCmd.Flags().StringVar(&opts.StdinFile, "stdin-file", "",
+		"File which is passed as standard input to all hooks ('-' for the standard input).")
+
+	runCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, runCmd)
+}
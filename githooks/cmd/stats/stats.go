@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+)
+
+func runStats(ctx *ccm.CmdContext, reset bool) {
+	_, _, gitDirWorktree := ccm.AssertRepoRoot(ctx)
+
+	if reset {
+		file := hooks.GetHookTimingsFile(gitDirWorktree)
+		if cm.IsFile(file) {
+			err := os.Remove(file)
+			ctx.Log.AssertNoErrorPanicF(err, "Could not remove hook timings '%s'.", file)
+		}
+
+		ctx.Log.Info("Hook timings have been reset.")
+
+		return
+	}
+
+	timings, err := hooks.LoadHookTimings(gitDirWorktree)
+	ctx.Log.AssertNoErrorPanic(err, "Could not load hook timings.")
+
+	budget, err := hooks.GetHookTimeBudget(ctx.GitX)
+	ctx.Log.AssertNoErrorF(err, "Could not get hook time budget.")
+
+	stats := timings.GetStats()
+	if len(stats) == 0 {
+		ctx.Log.Info("No hook timings recorded yet.")
+
+		return
+	}
+
+	for _, s := range groupByHookName(stats) {
+		ctx.Log.InfoF("Hook: '%s' [%v]:%s", s[0].HookName, len(s), formatStats(s, budget))
+	}
+
+	if budget != 0 {
+		ctx.Log.InfoF("Hook time budget: '%v'.", budget)
+	}
+}
+
+// groupByHookName groups the sorted statistics `stats` by hook name.
+func groupByHookName(stats []hooks.HookTimingStats) (groups [][]hooks.HookTimingStats) {
+	for i := range stats {
+		if i == 0 || stats[i].HookName != stats[i-1].HookName {
+			groups = append(groups, nil)
+		}
+
+		groups[len(groups)-1] = append(groups[len(groups)-1], stats[i])
+	}
+
+	return
+}
+
+// formatStats formats the statistics `stats` of hooks, marking the ones
+// over `budget` if not zero.
+func formatStats(stats []hooks.HookTimingStats, budget time.Duration) string {
+	round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+
+	var sb strings.Builder
+	for i := range stats {
+		s := &stats[i]
+
+		_, _ = strs.FmtW(&sb, "\n %s '%s' [runs: '%v', p50: '%v', p95: '%v', max: '%v']",
+			cm.ListItemLiteral, s.NamespacePath, s.Count, round(s.P50), round(s.P95), round(s.Max))
+
+		if budget != 0 && s.P95 > budget {
+			sb.WriteString(" [over budget]")
+		}
+	}
+
+	return sb.String()
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	reset := false
+
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Shows timing statistics of the hooks in the current repository.",
+		Long: `Shows the median (p50), the 95th percentile (p95) and the maximal duration
+of the last runs of each hook in the current repository, grouped by hook type.
+
+The durations are recorded by the runner in the Git directory.
+Simulated runs (e.g. 'git hooks run' or 'git hooks ci') are not recorded.
+Hooks which take longer than the budget in the Git config 'githooks.hookTimeBudget'
+(e.g. '2s', see 'git hooks config hook-time-budget') are warned about
+when they run and marked here if their p95 is over the budget.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runStats(ctx, reset)
+		}}
+
+	statsCmd.Flags().BoolVar(&reset, "reset", false, "Reset all recorded timings.")
+
+	statsCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, statsCmd)
+}
@@ -110,7 +110,8 @@ it again next time, if the repository is marked as trusted.`,
 		ccm.SetCommandDefaults(ctx.Log, trustRevokeCmd),
 		ccm.SetCommandDefaults(ctx.Log, trustForgetCmd),
 		ccm.SetCommandDefaults(ctx.Log, trustDeleteCmd),
-		ccm.SetCommandDefaults(ctx.Log, NewTrustHooksCmd(ctx)))
+		ccm.SetCommandDefaults(ctx.Log, NewTrustHooksCmd(ctx)),
+		NewTrustDiffCmd(ctx))
 
 	trustCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
 		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
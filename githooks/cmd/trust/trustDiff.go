@@ -0,0 +1,106 @@
+package trust
+
+import (
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/cmd/ignore"
+	"github.com/gabyx/githooks/githooks/cmd/list"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+)
+
+func runTrustDiff(ctx *ccm.CmdContext, patterns *hooks.HookPatterns) {
+	repoDir, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
+
+	repoHooksDir := hooks.GetGithooksDir(repoDir)
+	hookNames := hooks.SupportedHookNames
+
+	state, shared, hookNamespace := list.PrepareListHookState(
+		ctx,
+		repoDir,
+		repoHooksDir,
+		hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, false),
+		hookNames,
+	)
+	allHooks := getAllHooks(ctx.Log, hookNames, repoDir, gitDir, repoHooksDir, shared, state)
+
+	patterns.MakeRelativePatternsAbsolute(hookNamespace, "")
+	usePatterns := !patterns.IsEmpty()
+
+	out := ctx.Log.GetInfoWriter()
+	shown := strs.NewStringSet(len(allHooks))
+	count := 0
+
+	for i := range allHooks {
+		hook := &allHooks[i]
+
+		if usePatterns && !patterns.Matches(hook.NamespacePath) ||
+			!usePatterns && (!hook.Active || hook.Trusted) {
+			continue
+		}
+
+		// Hooks in a hooks manifest share the same file.
+		if shown.Exists(hook.Path) {
+			continue
+		}
+		shown.Insert(hook.Path)
+		count++
+
+		diff, hasSnapshot, err := state.Checksums.GetTrustDiff(hook.Path)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not get changes of hook '%s'.", hook.Path)
+
+		_, err = strs.FmtW(out, "Hook '%s' (%s):\n", hook.NamespacePath, hook.Path)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not write output.")
+
+		switch {
+		case !hasSnapshot:
+			_, err = strs.FmtW(out, "No trusted version to compare.\n\n")
+		case strs.IsEmpty(diff):
+			_, err = strs.FmtW(out, "No changes since the last trusted version.\n\n")
+		default:
+			_, err = strs.FmtW(out, "%s\n", diff)
+		}
+		ctx.Log.AssertNoErrorPanicF(err, "Could not write output.")
+	}
+
+	ctx.Log.PanicIfF(usePatterns && count == 0,
+		"Given pattern or paths did not match any hooks '%v'.",
+		patterns)
+
+	if !usePatterns && count == 0 {
+		ctx.Log.Info("All active hooks are trusted.")
+	}
+}
+
+// NewTrustDiffCmd creates this new command.
+func NewTrustDiffCmd(ctx *ccm.CmdContext) *cobra.Command {
+	patterns := hooks.HookPatterns{}
+
+	trustDiff := &cobra.Command{
+		Use:   "diff [flags]",
+		Short: "Shows the changes of hooks since they were last trusted.",
+		Long: `Shows the changes of all active, untrusted hooks or of all hooks
+which match the glob patterns or namespace paths given by '--patterns' or '--paths'
+as unified diffs between the last trusted content and the current content.
+
+The content of a hook is stored when it is trusted.` + "\n\n" +
+			ignore.SeeHookListHelpText + "\n\n" +
+			ignore.NamespaceHelpText + "\n\n" +
+			ignore.PatternsHelpText,
+
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			runTrustDiff(ctx, &patterns)
+		},
+	}
+
+	trustDiff.Flags().StringArrayVar(&patterns.Patterns, "pattern", nil,
+		"Specified glob pattern matching hook namespace paths.")
+
+	trustDiff.Flags().StringArrayVar(&patterns.NamespacePaths, "path", nil,
+		"Specified path fully matching a hook namespace path.")
+
+	return ccm.SetCommandDefaults(ctx.Log, trustDiff)
+}
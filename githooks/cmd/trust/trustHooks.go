@@ -79,17 +79,22 @@ func apply(log cm.ILogContext, hook *hooks.Hook, checksums *hooks.ChecksumStore,
 	}
 }
 
-func runTrustPatterns(ctx *ccm.CmdContext, reset bool, all bool, patterns *hooks.HookPatterns) {
+func runTrustPatterns(
+	ctx *ccm.CmdContext,
+	reset bool,
+	all bool,
+	worktreeOnly bool,
+	patterns *hooks.HookPatterns) {
 	repoDir, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
 
 	repoHooksDir := hooks.GetGithooksDir(repoDir)
-	hookNames := hooks.ManagedHookNames
+	hookNames := hooks.SupportedHookNames
 
 	state, shared, hookNamespace := list.PrepareListHookState(
 		ctx,
 		repoDir,
 		repoHooksDir,
-		gitDirWorktree,
+		hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, worktreeOnly),
 		hookNames,
 	)
 	allHooks := getAllHooks(ctx.Log, hookNames, repoDir, gitDir, repoHooksDir, shared, state)
@@ -116,6 +121,7 @@ func runTrustPatterns(ctx *ccm.CmdContext, reset bool, all bool, patterns *hooks
 func NewTrustHooksCmd(ctx *ccm.CmdContext) *cobra.Command {
 	reset := false
 	all := false
+	worktreeOnly := false
 	patterns := hooks.HookPatterns{}
 
 	trustHooks := &cobra.Command{
@@ -142,7 +148,7 @@ by '--patterns' or '--paths'.` + "\n\n" +
 		},
 
 		Run: func(cmd *cobra.Command, args []string) {
-			runTrustPatterns(ctx, reset, all, &patterns)
+			runTrustPatterns(ctx, reset, all, worktreeOnly, &patterns)
 		},
 	}
 
@@ -159,6 +165,10 @@ by '--patterns' or '--paths'.` + "\n\n" +
 	trustHooks.Flags().BoolVar(&reset, "reset", false,
 		"If the matched hooks are set 'untrusted'.")
 
+	trustHooks.Flags().BoolVar(&worktreeOnly, "worktree", false,
+		`If the matched hooks are only trusted (or untrusted with '--reset')
+in the current worktree (when the trust is shared across worktrees).`)
+
 	trustHooks.PersistentPreRun = func(_ *cobra.Command, _ []string) {
 		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
 	}
@@ -0,0 +1,456 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gabyx/githooks/githooks/cmd/list"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// The views of the terminal UI.
+type viewMode int
+
+const (
+	listView viewMode = iota
+	textView
+	helpView
+)
+
+// The keys the terminal UI reacts on.
+type key int
+
+const (
+	keyNone key = iota
+	keyRune
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEscape
+	keyInterrupt
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// hookRow is a listed hook in the terminal UI.
+type hookRow struct {
+	HookName  string
+	Tag       string
+	SharedURL string
+	Hook      hooks.Hook
+}
+
+// listing is the loaded state of all hooks.
+type listing struct {
+	Rows             []hookRow
+	GithooksDisabled bool
+	PendingShared    int
+}
+
+// actions are the operations the terminal UI performs on the repository.
+type actions interface {
+	// Load all hooks.
+	load() (listing, error)
+	// Toggle the ignore state of a hook in the user ignore patterns.
+	toggleIgnore(row *hookRow) (string, error)
+	// Trust or untrust a hook.
+	setTrust(row *hookRow, trust bool) (string, error)
+	// Get the source of a hook.
+	source(row *hookRow) ([]string, error)
+	// Get the changes of a hook.
+	diff(row *hookRow) ([]string, error)
+	// Update all shared repositories.
+	updateShared() (string, error)
+}
+
+// model is the state of the terminal UI.
+type model struct {
+	listing
+
+	cursor int // The selected row.
+	offset int // The first shown row.
+
+	view       viewMode
+	title      string   // The title of the text view.
+	lines      []string // The lines of the text view.
+	lineOffset int      // The first shown line of the text view.
+
+	status string
+	failed bool // If the status reports an error.
+
+	width  int
+	height int
+	quit   bool
+}
+
+func newModel(a actions) *model {
+	m := &model{width: 80, height: 24} //nolint:mnd
+	m.reload(a)
+
+	return m
+}
+
+func (m *model) setStatus(status string, err error) {
+	if err != nil {
+		m.status = strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+		m.failed = true
+	} else {
+		m.status = status
+		m.failed = false
+	}
+}
+
+func (m *model) reload(a actions) {
+	l, err := a.load()
+	if err != nil {
+		m.setStatus("", err)
+
+		return
+	}
+
+	m.listing = l
+	m.cursor = min(m.cursor, max(len(m.Rows)-1, 0))
+}
+
+func (m *model) selected() *hookRow {
+	if len(m.Rows) == 0 {
+		return nil
+	}
+
+	return &m.Rows[m.cursor]
+}
+
+// pageSize is the number of rows or lines shown.
+func (m *model) pageSize() int {
+	// Header, empty line, footer with status.
+	return max(m.height-4, 1) //nolint:mnd
+}
+
+func (m *model) showText(title string, lines []string, err error) {
+	if err != nil {
+		m.setStatus("", err)
+
+		return
+	}
+
+	m.view = textView
+	m.title = title
+	m.lines = lines
+	m.lineOffset = 0
+}
+
+// handle reacts on key `k` (`r` for `keyRune`) and performs actions with `a`.
+func (m *model) handle(k key, r rune, a actions) {
+	if k == keyInterrupt {
+		m.quit = true
+
+		return
+	}
+
+	if m.view != listView {
+		m.handleText(k, r)
+
+		return
+	}
+
+	page := m.pageSize()
+
+	switch {
+	case k == keyUp || k == keyRune && r == 'k':
+		m.cursor--
+	case k == keyDown || k == keyRune && r == 'j':
+		m.cursor++
+	case k == keyPageUp:
+		m.cursor -= page
+	case k == keyPageDown:
+		m.cursor += page
+	case k == keyHome || k == keyRune && r == 'g':
+		m.cursor = 0
+	case k == keyEnd || k == keyRune && r == 'G':
+		m.cursor = len(m.Rows) - 1
+	case k == keyEscape || k == keyRune && r == 'q':
+		m.quit = true
+	case k == keyRune && r == '?':
+		m.view = helpView
+		m.lineOffset = 0
+	case k == keyRune && r == 'r':
+		m.reload(a)
+		m.setStatus("Reloaded.", nil)
+	case k == keyRune && r == 's':
+		status, err := a.updateShared()
+		m.setStatus(status, err)
+		m.reload(a)
+	default:
+		m.handleRow(k, r, a)
+	}
+
+	m.cursor = max(min(m.cursor, len(m.Rows)-1), 0)
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+page {
+		m.offset = m.cursor - page + 1
+	}
+}
+
+// handleRow performs the actions on the selected hook.
+func (m *model) handleRow(k key, r rune, a actions) {
+	row := m.selected()
+	if row == nil {
+		return
+	}
+
+	switch {
+	case k == keyEnter || k == keyRune && r == 'v':
+		lines, err := a.source(row)
+		m.showText(strs.Fmt("Source of '%s'", row.Hook.NamespacePath), lines, err)
+	case k == keyRune && r == 'd':
+		lines, err := a.diff(row)
+		m.showText(strs.Fmt("Changes of '%s'", row.Hook.NamespacePath), lines, err)
+	case k == keyRune && r == 'i':
+		status, err := a.toggleIgnore(row)
+		m.setStatus(status, err)
+		m.reload(a)
+	case k == keyRune && (r == 't' || r == 'u'):
+		status, err := a.setTrust(row, r == 't')
+		m.setStatus(status, err)
+		m.reload(a)
+	}
+}
+
+// handleText scrolls the text and help view.
+func (m *model) handleText(k key, r rune) {
+	page := m.pageSize()
+
+	switch {
+	case k == keyUp || k == keyRune && r == 'k':
+		m.lineOffset--
+	case k == keyDown || k == keyRune && r == 'j':
+		m.lineOffset++
+	case k == keyPageUp:
+		m.lineOffset -= page
+	case k == keyPageDown || k == keyRune && r == ' ':
+		m.lineOffset += page
+	case k == keyHome || k == keyRune && r == 'g':
+		m.lineOffset = 0
+	case k == keyEnd || k == keyRune && r == 'G':
+		m.lineOffset = len(m.lines)
+	case k == keyEscape || k == keyEnter || k == keyRune && (r == 'q' || r == '?'):
+		m.view = listView
+	}
+
+	m.lineOffset = max(min(m.lineOffset, len(m.lines)-page), 0)
+}
+
+// render renders the current view with lines separated by `\n`.
+func (m *model) render() string {
+	var sb strings.Builder
+
+	writeLine := func(color string, s string) {
+		s = truncate(s, m.width)
+		if strs.IsNotEmpty(color) {
+			s = color + s + ansiReset
+		}
+
+		sb.WriteString(s)
+		sb.WriteString("\n")
+	}
+
+	switch m.view {
+	case listView:
+		m.renderList(writeLine)
+	case textView:
+		writeLine(ansiBold, m.title)
+		writeLine("", "")
+		m.renderLines(writeLine, m.lines, true)
+		writeLine(ansiCyan, "↑/↓ scroll  space page  q back")
+	case helpView:
+		writeLine(ansiBold, "Githooks: Keys")
+		writeLine("", "")
+		m.renderLines(writeLine, helpLines, false)
+		writeLine(ansiCyan, "q back")
+	}
+
+	return sb.String()
+}
+
+func (m *model) renderList(writeLine func(string, string)) {
+	header := strs.Fmt("Githooks: '%v' hooks", len(m.Rows))
+	if m.GithooksDisabled {
+		header += " [githooks disabled]"
+	}
+
+	if m.PendingShared != 0 {
+		header += strs.Fmt(" [%v pending shared repositories: press 's']", m.PendingShared)
+	}
+
+	writeLine(ansiBold, header)
+	writeLine("", "")
+
+	page := m.pageSize()
+	shown := 0
+
+	for i := m.offset; i < len(m.Rows) && shown < page; i++ {
+		line := formatRow(&m.Rows[i])
+
+		if i == m.cursor {
+			writeLine(ansiReverse, "> "+line)
+		} else {
+			writeLine(getRowColor(&m.Rows[i]), "  "+line)
+		}
+
+		shown++
+	}
+
+	if len(m.Rows) == 0 {
+		writeLine("", "  No hooks found.")
+		shown++
+	}
+
+	for ; shown < page; shown++ {
+		writeLine("", "")
+	}
+
+	switch {
+	case m.failed:
+		writeLine(ansiRed, m.status)
+	case strs.IsNotEmpty(m.status):
+		writeLine(ansiGreen, m.status)
+	default:
+		writeLine(ansiCyan,
+			"enter view  d diff  i ignore  t trust  u untrust  s update shared  r reload  ? help  q quit")
+	}
+}
+
+func (m *model) renderLines(writeLine func(string, string), lines []string, colorDiff bool) {
+	page := m.pageSize() - 1
+	shown := 0
+
+	for i := m.lineOffset; i < len(lines) && shown < page; i++ {
+		color := ""
+		if colorDiff {
+			color = getDiffColor(lines[i])
+		}
+
+		writeLine(color, strings.ReplaceAll(lines[i], "\t", "    "))
+		shown++
+	}
+
+	for ; shown < page; shown++ {
+		writeLine("", "")
+	}
+}
+
+var helpLines = []string{
+	"↑/↓, j/k     : Select a hook.",
+	"PgUp/PgDn    : Select a hook one page up/down.",
+	"enter, v     : View the source of the hook.",
+	"d            : View the changes of the hook since it was last trusted",
+	"               (or to its committed version).",
+	"i            : Ignore or activate the hook (user ignore patterns).",
+	"t, u         : Trust or untrust the hook.",
+	"s            : Update all shared repositories.",
+	"r            : Reload all hooks.",
+	"q, esc       : Quit or go back.",
+}
+
+// formatRow formats the state of a hook, the same as `list` does.
+func formatRow(row *hookRow) string {
+	active := "active" //nolint:goconst
+	if !row.Hook.Active {
+		active = "ignored"
+	}
+
+	trusted := "trusted"
+	if !row.Hook.Trusted {
+		trusted = "untrusted"
+	}
+
+	return strs.Fmt("%-18s %-13s %-28s ['%s', '%s']  %s",
+		row.HookName, row.Tag,
+		strs.Fmt("'%s'", list.GetHookDisplayName(&row.Hook)),
+		active, trusted, row.Hook.NamespacePath)
+}
+
+func getRowColor(row *hookRow) string {
+	switch {
+	case !row.Hook.Active:
+		return ansiYellow
+	case !row.Hook.Trusted:
+		return ansiRed
+	default:
+		return ""
+	}
+}
+
+func getDiffColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		return ansiBold
+	case strings.HasPrefix(line, "+"):
+		return ansiGreen
+	case strings.HasPrefix(line, "-"):
+		return ansiRed
+	case strings.HasPrefix(line, "@@"):
+		return ansiCyan
+	default:
+		return ""
+	}
+}
+
+// truncate truncates `s` to `width` runes.
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	return string([]rune(s)[:width])
+}
+
+// parseKey parses the input `b` read from the terminal in raw mode.
+func parseKey(b []byte) (key, rune) {
+	switch string(b) {
+	case "":
+		return keyNone, 0
+	case "\x1b[A", "\x1bOA":
+		return keyUp, 0
+	case "\x1b[B", "\x1bOB":
+		return keyDown, 0
+	case "\x1b[5~":
+		return keyPageUp, 0
+	case "\x1b[6~":
+		return keyPageDown, 0
+	case "\x1b[H", "\x1b[1~", "\x1bOH":
+		return keyHome, 0
+	case "\x1b[F", "\x1b[4~", "\x1bOF":
+		return keyEnd, 0
+	case "\r", "\n":
+		return keyEnter, 0
+	case "\x1b":
+		return keyEscape, 0
+	case "\x03", "\x04":
+		return keyInterrupt, 0
+	}
+
+	if b[0] == '\x1b' {
+		// Unknown escape sequence.
+		return keyNone, 0
+	}
+
+	r, _ := utf8.DecodeRune(b)
+
+	return keyRune, r
+}
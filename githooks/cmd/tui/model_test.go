@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeActions struct {
+	rows    []hookRow
+	called  []string
+	failing bool
+}
+
+func (a *fakeActions) load() (listing, error) {
+	return listing{Rows: a.rows, PendingShared: 1}, nil
+}
+
+func (a *fakeActions) toggleIgnore(row *hookRow) (string, error) {
+	a.called = append(a.called, "ignore:"+row.Hook.NamespacePath)
+	row.Hook.Active = !row.Hook.Active
+
+	return "ignored", nil
+}
+
+func (a *fakeActions) setTrust(row *hookRow, trust bool) (string, error) {
+	if a.failing {
+		return "", cm.Error("trust failed\nbadly")
+	}
+
+	a.called = append(a.called, "trust:"+row.Hook.NamespacePath)
+
+	return "trusted", nil
+}
+
+func (a *fakeActions) source(row *hookRow) ([]string, error) {
+	return []string{"#!/bin/sh", "echo " + row.Hook.NamespacePath}, nil
+}
+
+func (a *fakeActions) diff(*hookRow) ([]string, error) {
+	return []string{"@@ -1 +1 @@", "-old", "+new"}, nil
+}
+
+func (a *fakeActions) updateShared() (string, error) {
+	a.called = append(a.called, "update")
+
+	return "updated", nil
+}
+
+func newFakeActions(n int) *fakeActions {
+	a := &fakeActions{}
+	for i := 0; i < n; i++ {
+		a.rows = append(a.rows,
+			hookRow{
+				HookName: "pre-commit",
+				Tag:      hooks.TagNameRepository,
+				Hook: hooks.Hook{
+					Path:          "/repo/.githooks/pre-commit/" + string(rune('a'+i)),
+					NamespacePath: "ns:gh-self/pre-commit/" + string(rune('a'+i)),
+					Active:        true,
+					Trusted:       i%2 == 0}})
+	}
+
+	return a
+}
+
+func TestParseKey(t *testing.T) {
+	tests := map[string]key{
+		"\x1b[A":  keyUp,
+		"\x1b[B":  keyDown,
+		"\x1b[5~": keyPageUp,
+		"\x1b[6~": keyPageDown,
+		"\r":      keyEnter,
+		"\x1b":    keyEscape,
+		"\x03":    keyInterrupt,
+		"\x1b[Z":  keyNone,
+	}
+
+	for in, expected := range tests {
+		k, _ := parseKey([]byte(in))
+		assert.Equal(t, expected, k, "Input: '%q'", in)
+	}
+
+	k, r := parseKey([]byte("ü"))
+	assert.Equal(t, keyRune, k)
+	assert.Equal(t, 'ü', r)
+}
+
+func TestModelNavigation(t *testing.T) {
+	a := newFakeActions(10)
+	m := newModel(a)
+	m.height = 8 // Page size 4.
+	m.width = 200
+
+	require.Len(t, m.Rows, 10)
+	assert.Equal(t, 1, m.PendingShared)
+
+	m.handle(keyUp, 0, a)
+	assert.Equal(t, 0, m.cursor)
+
+	m.handle(keyPageDown, 0, a)
+	m.handle(keyRune, 'j', a)
+	assert.Equal(t, 5, m.cursor)
+	assert.Equal(t, 2, m.offset)
+
+	m.handle(keyEnd, 0, a)
+	assert.Equal(t, 9, m.cursor)
+	assert.Equal(t, 6, m.offset)
+
+	out := m.render()
+	assert.Contains(t, out, "> pre-commit")
+	assert.Contains(t, out, "ns:gh-self/pre-commit/j")
+	assert.NotContains(t, out, "ns:gh-self/pre-commit/a")
+	assert.Contains(t, out, "1 pending shared repositories")
+	assert.Len(t, strings.Split(strings.TrimSuffix(out, "\n"), "\n"), m.height-1)
+
+	m.handle(keyHome, 0, a)
+	assert.Equal(t, 0, m.cursor)
+	assert.Equal(t, 0, m.offset)
+
+	m.handle(keyRune, 'q', a)
+	assert.True(t, m.quit)
+}
+
+func TestModelActions(t *testing.T) {
+	a := newFakeActions(3)
+	m := newModel(a)
+
+	m.handle(keyDown, 0, a)
+	m.handle(keyRune, 'i', a)
+	m.handle(keyRune, 't', a)
+	m.handle(keyRune, 's', a)
+	assert.Equal(t,
+		[]string{"ignore:ns:gh-self/pre-commit/b", "trust:ns:gh-self/pre-commit/b", "update"},
+		a.called)
+	assert.False(t, m.Rows[1].Hook.Active)
+	assert.Equal(t, "updated", m.status)
+
+	// Errors are shown on one line.
+	a.failing = true
+	m.handle(keyRune, 'u', a)
+	assert.True(t, m.failed)
+	assert.Contains(t, m.render(), "trust failed badly")
+
+	// Source and diff view.
+	m.handle(keyEnter, 0, a)
+	assert.Equal(t, textView, m.view)
+	assert.Contains(t, m.render(), "echo ns:gh-self/pre-commit/b")
+
+	m.handle(keyEscape, 0, a)
+	assert.Equal(t, listView, m.view)
+	assert.False(t, m.quit)
+
+	m.handle(keyRune, 'd', a)
+	assert.Equal(t, textView, m.view)
+	assert.Contains(t, m.render(), ansiGreen+"+new")
+
+	m.handle(keyRune, 'q', a)
+	m.handle(keyRune, '?', a)
+	assert.Equal(t, helpView, m.view)
+	m.handle(keyInterrupt, 0, a)
+	assert.True(t, m.quit)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "ab", truncate("abc", 2))
+	assert.Equal(t, "üö", truncate("üöä", 2))
+	assert.Equal(t, "abc", truncate("abc", 0))
+}
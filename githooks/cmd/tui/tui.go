@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/cmd/list"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	ansiEnterScreen = "\x1b[?1049h\x1b[?25l"
+	ansiLeaveScreen = "\x1b[?25h\x1b[?1049l"
+	ansiClearScreen = "\x1b[H\x1b[2J"
+)
+
+// repoActions performs the actions of the terminal UI in the current repository.
+type repoActions struct {
+	ctx *ccm.CmdContext
+
+	repoDir      string
+	gitDir       string
+	stateDirs    hooks.UserStateDirs
+	repoHooksDir string
+
+	state *list.ListingState
+
+	// Suspends the terminal UI to run `f` in the normal terminal.
+	suspend func(f func())
+}
+
+func (a *repoActions) load() (l listing, err error) {
+	state, shared, _ := list.PrepareListHookState(
+		a.ctx, a.repoDir, a.repoHooksDir, a.stateDirs, hooks.SupportedHookNames)
+	a.state = state
+
+	for _, hookName := range hooks.SupportedHookNames {
+		sections, _ := list.CollectHooksForName(
+			a.ctx.Log, hookName, a.repoDir, a.gitDir, a.repoHooksDir, shared, state)
+
+		for _, section := range sections {
+			for i := range section.Hooks {
+				l.Rows = append(l.Rows,
+					hookRow{
+						HookName:  hookName,
+						Tag:       section.Tag,
+						SharedURL: section.SharedURL,
+						Hook:      section.Hooks[i]})
+			}
+		}
+	}
+
+	for i := range shared {
+		for j := range shared[i] {
+			if !cm.IsDirectory(shared[i][j].RepositoryDir) {
+				l.PendingShared++
+			}
+		}
+	}
+
+	l.GithooksDisabled = state.IsGithooksDisabled()
+
+	return l, nil
+}
+
+func (a *repoActions) toggleIgnore(row *hookRow) (string, error) {
+	file := hooks.GetHookIgnoreFileGitDir(a.stateDirs.Store)
+	nsPath := row.Hook.NamespacePath
+
+	var patterns hooks.HookPatterns
+	if cm.IsFile(file) {
+		var err error
+		if patterns, err = hooks.LoadIgnorePatterns(file); err != nil {
+			return "", cm.CombineErrors(err, cm.ErrorF("Could not load ignore file '%s'.", file))
+		}
+	}
+
+	var status string
+	if row.Hook.Active {
+		patterns.AddNamespacePathsUnique(nsPath)
+		status = strs.Fmt("Ignored hook '%s'.", nsPath)
+	} else {
+		if patterns.RemoveNamespacePaths(nsPath) == 0 {
+			return "", cm.ErrorF("Hook '%s' is ignored by a pattern, "+
+				"use 'git hooks ignore' to activate it.", nsPath)
+		}
+		status = strs.Fmt("Activated hook '%s'.", nsPath)
+	}
+
+	if err := hooks.StoreHookPatternsGitDir(patterns, a.stateDirs.Store); err != nil {
+		return "", cm.CombineErrors(err, cm.ErrorF("Could not store ignore file '%s'.", file))
+	}
+
+	return status, nil
+}
+
+func (a *repoActions) setTrust(row *hookRow, trust bool) (string, error) {
+	hook := &row.Hook
+
+	if a.state.IsRepoTrusted() {
+		return "", cm.Error("The repository is trusted, all its hooks are trusted.")
+	}
+
+	if err := hook.AssertSHA1(); err != nil {
+		return "", cm.CombineErrors(err, cm.ErrorF("Could not compute SHA1 hash for hook '%s'.", hook.Path))
+	}
+
+	if !trust {
+		removed, err := a.state.Checksums.SyncChecksumRemove(hook.SHA1)
+		if err != nil {
+			return "", cm.CombineErrors(err, cm.ErrorF("Could not sync checksum for hook '%s'.", hook.Path))
+		} else if removed == 0 {
+			return strs.Fmt("No trust checksum for hook '%s'.", hook.NamespacePath), nil
+		}
+
+		return strs.Fmt("Removed trust checksum for hook '%s'.", hook.NamespacePath), nil
+	}
+
+	err := a.state.Checksums.SyncChecksumAdd(
+		hooks.ChecksumResult{
+			SHA1:          hook.SHA1,
+			Path:          hook.Path,
+			NamespacePath: hook.NamespacePath})
+	if err != nil {
+		return "", cm.CombineErrors(err, cm.ErrorF("Could not sync checksum for hook '%s'.", hook.Path))
+	}
+
+	return strs.Fmt("Set trust checksum for hook '%s'.", hook.NamespacePath), nil
+}
+
+func (a *repoActions) source(row *hookRow) ([]string, error) {
+	content, err := os.ReadFile(row.Hook.Path)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not read hook '%s'.", row.Hook.Path))
+	}
+
+	if bytes.IndexByte(content, 0) >= 0 {
+		return []string{strs.Fmt("Binary file '%s'.", row.Hook.Path)}, nil
+	}
+
+	return strs.SplitLines(strings.TrimRight(string(content), "\n")), nil
+}
+
+func (a *repoActions) diff(row *hookRow) ([]string, error) {
+	hookPath := row.Hook.Path
+
+	// Compare to the last trusted content if there is any ...
+	diff, hasSnapshot, err := a.state.Checksums.GetTrustDiff(hookPath)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not get changes of hook '%s'.", hookPath))
+	} else if hasSnapshot {
+		if strs.IsEmpty(diff) {
+			return []string{"No changes since the last trusted version."}, nil
+		}
+
+		return strs.SplitLines(strings.TrimRight(diff, "\n")), nil
+	}
+
+	// ... otherwise to the committed content.
+	gitx := git.NewCtxAt(path.Dir(hookPath))
+
+	if !gitx.IsGitRepo() || gitx.IsBareRepo() {
+		return nil, cm.ErrorF("Hook '%s' is not inside a repository.", hookPath)
+	}
+
+	if e := gitx.Check("ls-files", "--error-unmatch", "--", hookPath); e != nil {
+		return nil, cm.ErrorF("Hook '%s' is not committed.", hookPath)
+	}
+
+	out, err := gitx.Get("diff", "--no-color", "HEAD", "--", hookPath)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not get changes of hook '%s'.", hookPath))
+	}
+
+	if strs.IsEmpty(out) {
+		return []string{"No changes to the committed version."}, nil
+	}
+
+	return strs.SplitLines(out), nil
+}
+
+func (a *repoActions) updateShared() (status string, err error) {
+	a.suspend(func() {
+		containerMgr, e := hooks.NewContainerManager(a.ctx.GitX, false, nil)
+		if e != nil {
+			err = cm.CombineErrors(e, cm.Error("Could not create container manager."))
+
+			return
+		}
+
+		var updated int
+		updated, err = hooks.UpdateAllSharedHooks(
+			a.ctx.Log, a.ctx.GitX, a.ctx.InstallDir, a.repoDir, containerMgr)
+		status = strs.Fmt("Updated '%v' shared repositories.", updated)
+	})
+
+	if err != nil {
+		err = cm.CombineErrors(cm.Error("There have been errors while updating shared hooks."), err)
+	}
+
+	return
+}
+
+// terminal is the terminal the UI is drawn on in raw mode.
+type terminal struct {
+	fd    int
+	state *term.State
+}
+
+func (t *terminal) enter() (err error) {
+	if t.state, err = term.MakeRaw(t.fd); err != nil {
+		return
+	}
+
+	_, err = os.Stdout.WriteString(ansiEnterScreen)
+
+	return
+}
+
+func (t *terminal) leave() {
+	_, _ = os.Stdout.WriteString(ansiLeaveScreen)
+
+	if t.state != nil {
+		_ = term.Restore(t.fd, t.state)
+		t.state = nil
+	}
+}
+
+func (t *terminal) draw(m *model) error {
+	if w, h, err := term.GetSize(t.fd); err == nil && w > 0 && h > 0 {
+		m.width, m.height = w, h
+	}
+
+	// In raw mode each line needs a carriage return.
+	out := strings.ReplaceAll(strings.TrimSuffix(m.render(), "\n"), "\n", "\r\n")
+	_, err := os.Stdout.WriteString(ansiClearScreen + out)
+
+	return err
+}
+
+func (t *terminal) readKey() (key, rune, error) {
+	buf := make([]byte, 16) //nolint:mnd
+
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return keyNone, 0, err
+	}
+
+	k, r := parseKey(buf[:n])
+
+	return k, r, nil
+}
+
+func runTUI(ctx *ccm.CmdContext) {
+	repoDir, gitDir, gitDirWorktree := ccm.AssertRepoRoot(ctx)
+
+	t := terminal{fd: int(os.Stdin.Fd())}
+	ctx.Log.PanicIfF(!term.IsTerminal(t.fd) || !ctx.Log.IsInfoATerminal(),
+		"The terminal UI needs an interactive terminal.")
+
+	a := &repoActions{
+		ctx:          ctx,
+		repoDir:      repoDir,
+		gitDir:       gitDir,
+		stateDirs:    hooks.GetUserStateDirs(ctx.GitX, gitDir, gitDirWorktree, false),
+		repoHooksDir: hooks.GetGithooksDir(repoDir)}
+
+	a.suspend = func(f func()) {
+		t.leave()
+		f()
+
+		ctx.Log.Info("Press enter to continue.")
+		_, _, _ = t.readKey()
+
+		err := t.enter()
+		ctx.Log.AssertNoErrorPanic(err, "Could not setup the terminal.")
+	}
+
+	m := newModel(a)
+
+	err := t.enter()
+	ctx.Log.AssertNoErrorPanic(err, "Could not setup the terminal.")
+	defer t.leave()
+
+	for !m.quit {
+		err = t.draw(m)
+		ctx.Log.AssertNoErrorPanic(err, "Could not draw the terminal UI.")
+
+		k, r, e := t.readKey()
+		ctx.Log.AssertNoErrorPanic(e, "Could not read from the terminal.")
+
+		m.handle(k, r, a)
+	}
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	tuiCmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Manages hooks, trust and ignores in a terminal UI.",
+		Long: `Shows all hooks of the current repository with their state
+in an interactive terminal UI.
+
+Selected hooks can be ignored or activated (stored in the user ignore patterns
+in '.git/.githooks.ignore.yaml'), trusted or untrusted, and their source and
+changes since they were last trusted (or to the committed version) can be viewed.
+Shared repositories can be updated too.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runTUI(ctx)
+		}}
+
+	tuiCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, tuiCmd)
+}
@@ -308,6 +308,13 @@ func cleanBinaries(
 	if cm.IsDirectory(binDir) {
 		deleteDir(log, binDir, tempDir)
 	}
+
+	rollbackDir := updates.GetRollbackDir(installDir)
+	if cm.IsDirectory(rollbackDir) {
+		log.InfoF("Delete rollback directory '%s'.", rollbackDir)
+		err := os.RemoveAll(rollbackDir)
+		log.AssertNoErrorF(err, "Could not delete dir '%s'.", rollbackDir)
+	}
 }
 
 func cleanReleaseClone(
@@ -0,0 +1,96 @@
+package update
+
+import (
+	"os"
+
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/cmd/installer"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+	"github.com/gabyx/githooks/githooks/updates"
+
+	"github.com/spf13/cobra"
+)
+
+func runRollback(ctx *ccm.CmdContext, nonInteractive bool) {
+	info, exists, err := updates.LoadRollback(ctx.InstallDir)
+	ctx.Log.AssertNoErrorPanic(err, "Could not load rollback information.")
+	ctx.Log.PanicIf(!exists,
+		"There is no previous Githooks version to roll back to.",
+		"A rollback is only possible once after an update.")
+
+	if !nonInteractive {
+		answer, err := ctx.PromptCtx.ShowOptions(
+			strs.Fmt("Roll back Githooks version '%[1]s' to '%[2]s'?\n"+
+				"Updates to version '%[1]s' will be blocked afterwards.",
+				info.UpdatedToVersion, info.Version),
+			"(Yes/no)",
+			"Y/n",
+			"Yes", "No")
+		ctx.Log.AssertNoErrorF(err, "Could not show prompt.")
+
+		if answer != "y" {
+			ctx.Log.Info("Rollback declined.")
+
+			return
+		}
+	}
+
+	backupDir, err := hooks.AssertTemporaryDir(ctx.InstallDir)
+	ctx.Log.AssertNoErrorPanicF(err, "Could not create temporary directory in '%s'.", ctx.InstallDir)
+
+	err = updates.RunRollback(ctx.InstallDir, &info, backupDir)
+	ctx.Log.AssertNoErrorPanic(err, "Rollback failed.")
+
+	ctx.Log.InfoF("Restored Githooks version '%s' and blocked updates to '%s'.",
+		info.Version, info.UpdatedToVersion)
+
+	// Run the restored installer directly (no download) to
+	// reinstall its run-wrappers, also in registered repositories.
+	file, err := os.CreateTemp("", "*install-config.json")
+	ctx.Log.AssertNoErrorPanic(err, "Could not create temporary file.")
+	_ = file.Close()
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	err = cm.StoreJSON(file.Name(),
+		&installer.Arguments{
+			InternalPostDispatch:      true,
+			InternalUpdateFromVersion: info.UpdatedToVersion,
+			NonInteractive:            true,
+			SkipInstallIntoExisting:   true})
+	ctx.Log.AssertNoErrorPanicF(err, "Could not write arguments to '%s'.", file.Name())
+
+	err = updates.RunUpdateOverExecutable(
+		ctx.InstallDir,
+		&cm.ExecContext{Env: os.Environ()},
+		cm.UseStreams(nil, os.Stdout, os.Stderr),
+		"--config", file.Name())
+	ctx.Log.AssertNoErrorPanic(err, "Could not reinstall run-wrappers with the restored version.")
+
+	ctx.Log.Info("Rollback successful.")
+}
+
+func rollbackCmd(ctx *ccm.CmdContext) *cobra.Command {
+	yes := false
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Rolls back the last Githooks update.",
+		Long: `Rolls back the last Githooks update to the previously installed version.
+
+The previous binaries and the previous commit of the release clone
+are restored. The run-wrappers are reinstalled in all registered
+repositories with the restored version and any update to the
+rolled back version is blocked
+(see Git config 'githooks.updateBlockedVersions').`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runRollback(ctx, yes)
+		},
+	}
+
+	cmd.Flags().BoolVar(&yes, "yes", false, "Roll back without confirmation (non-interactive).")
+
+	return ccm.SetCommandDefaults(ctx.Log, cmd)
+}
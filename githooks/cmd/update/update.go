@@ -117,6 +117,8 @@ after a successful commit event.`,
 	updateCmd.Flags().
 		BoolVar(&setOpts.Unset, "disable-check", false, "Disable daily Githooks update checks.")
 
+	updateCmd.AddCommand(rollbackCmd(ctx))
+
 	updateCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
 		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
 	}
@@ -0,0 +1,71 @@
+package worktrees
+
+import (
+	ccm "github.com/gabyx/githooks/githooks/cmd/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/spf13/cobra"
+)
+
+func runMigrate(ctx *ccm.CmdContext, enable bool, clean bool) {
+	_, gitDir, _ := ccm.AssertRepoRoot(ctx)
+
+	checksums, patterns, err := hooks.MigrateWorktreeStates(gitDir, clean)
+	ctx.Log.AssertNoErrorPanicF(err, "Could not migrate the worktree states.")
+
+	ctx.Log.InfoF("Merged '%v' trusted checksums and '%v' ignore entries into '%s'.",
+		checksums, patterns, hooks.GetSharedStateDir(gitDir))
+
+	if enable {
+		err = hooks.SetShareWorktreeState(ctx.GitX, true, false, git.LocalScope)
+		ctx.Log.AssertNoErrorPanicF(err, "Could not enable sharing trust and user ignores.")
+		ctx.Log.Info("Enabled sharing trust and user ignores across worktrees locally.")
+	} else if shared, _ := hooks.IsWorktreeStateShared(ctx.GitX, git.Traverse); !shared {
+		ctx.Log.Warn("Sharing trust and user ignores across worktrees is not enabled.\n" +
+			"Use 'git hooks config share-worktree-state --enable'.")
+	}
+}
+
+// NewCmd creates this new command.
+func NewCmd(ctx *ccm.CmdContext) *cobra.Command {
+	enable := false
+	clean := false
+
+	worktreesCmd := &cobra.Command{
+		Use:   "worktrees",
+		Short: "Manages the hook state of worktrees.",
+		Long: `Manages the trusted hooks and the user ignore list of worktrees.
+
+If the Git config 'githooks.shareWorktreeState' is enabled
+(see 'git hooks config share-worktree-state'), the trusted hooks and
+the user ignore list are stored in the common Git directory and are
+shared by all worktrees of the repository.
+The state stored in each worktree's Git directory overrides the shared state
+(see '--worktree' on 'git hooks trust hooks' and 'git hooks ignore').`,
+		Run: ccm.PanicWrongArgs(ctx.Log)}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate [flags]",
+		Short: "Merges the hook state of all worktrees into the shared state.",
+		Long: `Merges the trusted hooks and the user ignore lists
+of the main worktree and all linked worktrees into the state
+shared by all worktrees.`,
+		PreRun: ccm.PanicIfAnyArgs(ctx.Log),
+		Run: func(cmd *cobra.Command, args []string) {
+			runMigrate(ctx, enable, clean)
+		}}
+
+	migrateCmd.Flags().BoolVar(&enable, "enable", false,
+		"Also enable sharing the state across worktrees in the local Git configuration.")
+	migrateCmd.Flags().BoolVar(&clean, "clean", false,
+		"Remove the merged state of each worktree.")
+
+	worktreesCmd.AddCommand(ccm.SetCommandDefaults(ctx.Log, migrateCmd))
+
+	worktreesCmd.PersistentPreRun = func(_ *cobra.Command, _ []string) {
+		ccm.CheckGithooksSetup(ctx.Log, ctx.GitX)
+	}
+
+	return ccm.SetCommandDefaults(ctx.Log, worktreesCmd)
+}
@@ -1,6 +1,10 @@
 package common
 
-import "time"
+import (
+	"time"
+
+	pb "github.com/schollz/progressbar/v3"
+)
 
 // ITask is a background task which
 // can be run with `RunBackgroundTask`.
@@ -20,6 +24,11 @@ type ProgressSettings struct {
 
 	ProgressUpdateInterval    time.Duration
 	ProgressStillRunningAfter time.Duration
+
+	// Optional details shown in `MaxDetails` lines below the spinner on the
+	// error output. They are updated on each progress update.
+	Details    func() []string
+	MaxDetails int
 }
 
 // CreateDefaultProgressSettings creates default progressbar settings.
@@ -34,13 +43,19 @@ func CreateDefaultProgressSettings(title string, titleStillRunning string) Progr
 // RunTaskWithProgress runs a task with a progress spinner
 // (if available) in a coroutine.
 // The returned task `taskOut` contains the output of the run.
-// If the task timed out, it will be `nil`.
+// If the task timed out, it will be `nil`. A zero `timeout` never times out.
 func RunTaskWithProgress(
 	taskIn ITask,
 	log ILogContext,
 	timeout time.Duration,
 	sett ProgressSettings) (taskOut ITask, taskError error) {
-	spinner := GetProgressBar(log, sett.Title, -1)
+	var spinner *pb.ProgressBar
+	if sett.Details != nil {
+		spinner = GetErrorProgressBar(log, sett.Title, -1, sett.MaxDetails)
+	} else {
+		spinner = GetProgressBar(log, sett.Title, -1)
+	}
+
 	if spinner == nil {
 		log.Info(sett.Title)
 	}
@@ -56,7 +71,11 @@ func RunTaskWithProgress(
 
 	spinnerT := time.NewTicker(sett.ProgressUpdateInterval)
 	stillRunningT := time.After(sett.ProgressStillRunningAfter)
-	timeoutT := time.After(timeout)
+
+	var timeoutT <-chan time.Time
+	if timeout > 0 {
+		timeoutT = time.After(timeout)
+	}
 
 	running := true
 
@@ -66,6 +85,11 @@ func RunTaskWithProgress(
 			running = false
 			if spinner != nil {
 				_ = spinner.Clear()
+
+				if sett.Details != nil {
+					// Clear the details below.
+					_, _ = log.GetErrorWriterOriginal().Write([]byte("\x1b[J"))
+				}
 			}
 
 		case <-stillRunningT:
@@ -77,6 +101,10 @@ func RunTaskWithProgress(
 		case <-spinnerT.C:
 			if spinner != nil {
 				_ = spinner.Add(1)
+
+				if sett.Details != nil {
+					setProgressDetails(spinner, sett.Details(), sett.MaxDetails)
+				}
 			}
 		case <-timeoutT:
 			running = false
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
 // IExecContext defines the context interface to execute commands.
@@ -93,9 +94,24 @@ func GetOutputFromExecutable(
 	return out, err
 }
 
+// lockedBuffer is a buffer which can be written concurrently.
+type lockedBuffer struct {
+	buffer bytes.Buffer
+	lock   sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buffer.Write(p)
+}
+
 // GetCombinedOutputFromExecutable calls an executable and
 // returns its stdout and stderr output and
 // exit code (only valid if error is nil).
+// The stdout and stderr output is additionally written live
+// to the writers of `pipeSetup` if not `nil`.
 func GetCombinedOutputFromExecutable(
 	ctx IExecContext,
 	exe IExecutable,
@@ -107,11 +123,31 @@ func GetCombinedOutputFromExecutable(
 	cmd.Env = append(cmd.Env, ctx.GetEnv()...)
 	cmd.Env = append(cmd.Env, exe.GetEnvironment()...)
 
+	var outPipe, errPipe io.Writer
 	if pipeSetup != nil {
-		cmd.Stdin, _, _ = pipeSetup()
+		cmd.Stdin, outPipe, errPipe = pipeSetup()
 	}
 
-	out, err := cmd.CombinedOutput()
+	var out []byte
+	var err error
+
+	if outPipe == nil && errPipe == nil {
+		out, err = cmd.CombinedOutput()
+	} else {
+		var buffer lockedBuffer
+		cmd.Stdout, cmd.Stderr = &buffer, &buffer
+
+		if outPipe != nil {
+			cmd.Stdout = io.MultiWriter(&buffer, outPipe)
+		}
+
+		if errPipe != nil {
+			cmd.Stderr = io.MultiWriter(&buffer, errPipe)
+		}
+
+		err = cmd.Run()
+		out = buffer.buffer.Bytes()
+	}
 
 	var t *exec.ExitError
 	exitCode := -1
@@ -3,6 +3,7 @@ package common
 import (
 	"bytes"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -103,7 +104,13 @@ func (c *CmdContext) GetSplit(args ...string) ([]string, error) {
 
 // Get executes a command and gets the stdout.
 func (c *CmdContext) Get(args ...string) (string, error) {
+	return c.GetStdin(nil, args...)
+}
+
+// GetStdin executes a command with standard input `stdin` and gets the stdout.
+func (c *CmdContext) GetStdin(stdin io.Reader, args ...string) (string, error) {
 	cmd := exec.Command(c.baseCmd, args...)
+	cmd.Stdin = stdin
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
@@ -209,8 +216,14 @@ func (c *CmdContext) GetExitCode(args ...string) (int, error) {
 
 // CheckPiped checks if a command executed successfully.
 func (c *CmdContext) CheckPiped(args ...string) (err error) {
+	return c.CheckPipedStdin(os.Stdin, args...)
+}
+
+// CheckPipedStdin checks if a command executed successfully
+// with standard input `stdin`.
+func (c *CmdContext) CheckPipedStdin(stdin io.Reader, args ...string) (err error) {
 	cmd := exec.Command(c.baseCmd, args...)
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Dir = c.cwd
@@ -78,6 +78,7 @@ type ILogContext interface {
 	IsInfoATerminal() bool
 
 	GetErrorWriter() io.Writer
+	GetErrorWriterOriginal() io.Writer
 	IsErrorATerminal() bool
 
 	AddFileWriter(file *os.File)
@@ -267,6 +268,11 @@ func (c *LogContext) GetErrorWriter() io.Writer {
 	return c.error
 }
 
+// GetErrorWriterOriginal returns the original error writer.
+func (c *LogContext) GetErrorWriterOriginal() io.Writer {
+	return c.stderr
+}
+
 // IsInfoATerminal returns `true` if the info log is connected to a terminal.
 func (c *LogContext) IsInfoATerminal() bool {
 	return c.infoIsTerminal
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// LinePrefixWriter writes each line prefixed to a writer.
+// Incomplete lines are kept until they are completed or the writer is closed.
+type LinePrefixWriter struct {
+	writer io.Writer
+	prefix []byte
+	lock   *sync.Mutex // The lock of the writer, can be shared by multiple writers.
+	line   []byte
+}
+
+// NewLinePrefixWriter creates a writer writing each line prefixed by `prefix` to `writer`.
+// Each line is written with `lock` locked (if not `nil`).
+func NewLinePrefixWriter(writer io.Writer, prefix string, lock *sync.Mutex) *LinePrefixWriter {
+	return &LinePrefixWriter{writer: writer, prefix: []byte(prefix), lock: lock}
+}
+
+// Write writes all complete lines in `p`.
+func (w *LinePrefixWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+
+	for {
+		idx := bytes.IndexByte(w.line, '\n')
+		if idx < 0 {
+			break
+		}
+
+		if err := w.writeLine(w.line[:idx+1]); err != nil {
+			return 0, err
+		}
+
+		w.line = w.line[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// Close writes the incomplete last line.
+func (w *LinePrefixWriter) Close() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+
+	line := append(w.line, '\n')
+	w.line = nil
+
+	return w.writeLine(line)
+}
+
+func (w *LinePrefixWriter) writeLine(line []byte) error {
+	if w.lock != nil {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+	}
+
+	out := make([]byte, 0, len(w.prefix)+len(line))
+	out = append(append(out, w.prefix...), line...)
+
+	_, err := w.writer.Write(out)
+
+	return err
+}
@@ -0,0 +1,221 @@
+package common
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/gookit/color"
+	"golang.org/x/term"
+)
+
+// The maximal number of tasks shown in the live status.
+const progressStatusMaxLines = 10
+
+type progressTask struct {
+	name     string
+	start    time.Time
+	duration time.Duration
+	finished bool
+	failed   bool
+}
+
+// ProgressStatus shows a live multi-line status of running tasks with their
+// elapsed time below a progress spinner (see `RunTaskWithProgress`) in a terminal.
+// When done, the status turns into a summary of all tasks.
+// All functions can be called on a `nil` status and do nothing.
+type ProgressStatus struct {
+	log      ILogContext
+	width    int
+	maxLines int
+
+	lock  sync.Mutex
+	tasks []*progressTask
+
+	done    chan bool // Closed to end the running progress.
+	stopped chan bool // Closed when the running progress ended.
+}
+
+// progressWaitTask is a task which runs until `done` is closed.
+type progressWaitTask struct {
+	done chan bool
+}
+
+func (t *progressWaitTask) Run(exitCh chan bool) error {
+	select {
+	case <-t.done:
+	case <-exitCh:
+	}
+
+	return nil
+}
+
+func (t *progressWaitTask) Clone() ITask {
+	return t
+}
+
+// NewProgressStatus creates a progress status for at most `nTasks` concurrent tasks
+// drawn on the error output of the log `log` or returns `nil` if it is not a terminal.
+func NewProgressStatus(log ILogContext, nTasks int) *ProgressStatus {
+	if !log.IsErrorATerminal() || nTasks <= 0 {
+		return nil
+	}
+
+	p := &ProgressStatus{
+		log:      log,
+		width:    80, //nolint:mnd
+		maxLines: min(nTasks, progressStatusMaxLines)}
+
+	if nTasks > progressStatusMaxLines {
+		p.maxLines++ // The line with the number of not shown tasks.
+	}
+
+	if f, ok := log.GetErrorWriterOriginal().(*os.File); ok {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 0 {
+			p.width = w
+		}
+	}
+
+	return p
+}
+
+// Start adds the running task `name` and returns its id.
+func (p *ProgressStatus) Start(name string) int {
+	if p == nil {
+		return 0
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.tasks = append(p.tasks, &progressTask{name: name, start: time.Now()})
+
+	if p.done == nil {
+		p.done = make(chan bool)
+		p.stopped = make(chan bool)
+
+		sett := CreateDefaultProgressSettings("Running hooks ...", "Still running hooks ...")
+		sett.Details = p.getDetails
+		sett.MaxDetails = p.maxLines
+
+		go func(task ITask, stopped chan bool) {
+			_, _ = RunTaskWithProgress(task, p.log, 0, sett)
+			close(stopped)
+		}(&progressWaitTask{done: p.done}, p.stopped)
+	}
+
+	return len(p.tasks) - 1
+}
+
+// Finish marks the task with id `id` as finished.
+func (p *ProgressStatus) Finish(id int, failed bool, duration time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if id < 0 || id >= len(p.tasks) {
+		return
+	}
+
+	t := p.tasks[id]
+	t.finished = true
+	t.failed = failed
+	t.duration = duration
+}
+
+// Done stops the live status and replaces it with a summary of all tasks.
+// Tasks started afterwards are shown in a new status.
+func (p *ProgressStatus) Done() {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	done, stopped := p.done, p.stopped
+	p.done, p.stopped = nil, nil
+	p.lock.Unlock()
+
+	if done != nil {
+		close(done)
+		<-stopped
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var sb strings.Builder
+	for _, t := range p.tasks {
+		sb.WriteString(p.formatTask(t))
+		sb.WriteString("\n")
+	}
+
+	_, _ = p.log.GetErrorWriterOriginal().Write([]byte(sb.String()))
+
+	// Keep the summary.
+	p.tasks = nil
+}
+
+// getDetails gets the lines of the live status with the running tasks first.
+func (p *ProgressStatus) getDetails() (lines []string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	maxTasks := min(p.maxLines, progressStatusMaxLines)
+
+	for pass := 0; pass < 2; pass++ {
+		for _, t := range p.tasks {
+			if t.finished != (pass == 1) || len(lines) >= maxTasks {
+				continue
+			}
+
+			lines = append(lines, p.formatTask(t))
+		}
+	}
+
+	if more := len(p.tasks) - len(lines); more > 0 {
+		lines = append(lines, strs.Fmt("  ... and '%v' more", more))
+	}
+
+	return
+}
+
+func (p *ProgressStatus) formatTask(t *progressTask) string {
+	var symbol string
+	var duration time.Duration
+
+	switch {
+	case !t.finished:
+		symbol = "•"
+		duration = time.Since(t.start).Round(100 * time.Millisecond) //nolint:mnd
+	case t.failed:
+		symbol = p.colorize(color.FgRed, "✗")
+		duration = t.duration.Round(time.Millisecond)
+	default:
+		symbol = p.colorize(color.FgGreen, "✓")
+		duration = t.duration.Round(time.Millisecond)
+	}
+
+	// Truncate the name such that the line does not wrap.
+	suffix := strs.Fmt(" (%v)", duration)
+	name := t.name
+	if n := p.width - 3 - utf8.RuneCountInString(suffix); utf8.RuneCountInString(name) > n { //nolint:mnd
+		name = "..." + string([]rune(name)[utf8.RuneCountInString(name)-max(n-3, 0):]) //nolint:mnd
+	}
+
+	return strs.Fmt(" %s %s%s", symbol, name, suffix)
+}
+
+func (p *ProgressStatus) colorize(c color.Color, s string) string {
+	if !p.log.HasColor() {
+		return s
+	}
+
+	return c.Render(s)
+}
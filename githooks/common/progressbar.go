@@ -1,15 +1,41 @@
 package common
 
 import (
+	"io"
+
 	pb "github.com/schollz/progressbar/v3"
 )
 
 // GetProgressBar returns a progressbar or nil (if log has no terminal attached).
 func GetProgressBar(log ILogContext, title string, length int) (bar *pb.ProgressBar) {
 	if log.IsInfoATerminal() {
-		bar = pb.NewOptions(length,
-			pb.OptionSetWriter(log.GetInfoWriter()),
-			pb.OptionEnableColorCodes(log.HasColor()),
+		bar = newProgressBar(log.GetInfoWriter(), log.HasColor(), title, length)
+	}
+
+	return
+}
+
+// GetErrorProgressBar returns a progressbar on the error output with `detailRows`
+// lines of details below it or nil (if the error output has no terminal attached).
+func GetErrorProgressBar(log ILogContext, title string, length int, detailRows int) (bar *pb.ProgressBar) {
+	if log.IsErrorATerminal() {
+		bar = newProgressBar(log.GetErrorWriterOriginal(), log.HasColor(), title, length,
+			pb.OptionSetMaxDetailRow(detailRows))
+	}
+
+	return
+}
+
+func newProgressBar(
+	writer io.Writer,
+	hasColor bool,
+	title string,
+	length int,
+	options ...pb.Option) *pb.ProgressBar {
+	return pb.NewOptions(length,
+		append([]pb.Option{
+			pb.OptionSetWriter(writer),
+			pb.OptionEnableColorCodes(hasColor),
 			pb.OptionShowBytes(false),
 			pb.OptionSetWidth(15),    //nolint:mnd
 			pb.OptionSpinnerType(69), //nolint:mnd
@@ -20,8 +46,18 @@ func GetProgressBar(log ILogContext, title string, length int) (bar *pb.Progress
 				SaucerPadding: " ",
 				BarStart:      "[",
 				BarEnd:        "]",
-			}))
-	}
+			})}, options...)...)
+}
 
-	return
+// setProgressDetails replaces the `maxDetails` lines of details
+// of the progress bar `bar` with `details`.
+func setProgressDetails(bar *pb.ProgressBar, details []string, maxDetails int) {
+	for i := 0; i < maxDetails; i++ {
+		detail := ""
+		if i < len(details) {
+			detail = details[i]
+		}
+
+		_ = bar.AddDetail(detail)
+	}
 }
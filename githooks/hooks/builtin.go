@@ -0,0 +1,660 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// BuiltinConfigFileName is the file name of the configuration
+// of the built-in hooks inside the repository's hooks directory.
+const BuiltinConfigFileName = ".builtin.yaml"
+
+const (
+	// BuiltinHookCommitMessage validates commit messages against
+	// the conventional commits specification.
+	BuiltinHookCommitMessage = "commit-message"
+	// BuiltinHookBranchName validates the names of pushed branches.
+	BuiltinHookBranchName = "branch-name"
+	// BuiltinHookFileSize limits the size of staged files.
+	BuiltinHookFileSize = "file-size"
+	// BuiltinHookForbiddenPaths rejects staged files with forbidden paths.
+	BuiltinHookForbiddenPaths = "forbidden-paths"
+	// BuiltinHookSecrets scans the staged changes for secrets.
+	BuiltinHookSecrets = "secrets"
+)
+
+// builtinHookNames maps the Git hook names to the built-in hooks they run.
+var builtinHookNames = map[string][]string{
+	"commit-msg": {BuiltinHookCommitMessage},
+	"pre-push":   {BuiltinHookBranchName},
+	"pre-commit": {BuiltinHookFileSize, BuiltinHookForbiddenPaths, BuiltinHookSecrets},
+}
+
+// The default commit types of conventional commits.
+var defaultConventionalCommitTypes = []string{
+	"build", "chore", "ci", "docs", "feat", "fix",
+	"perf", "refactor", "revert", "style", "test"}
+
+// The default secret patterns which are always scanned for
+// if not disabled.
+var defaultSecretPatterns = []string{
+	`AKIA[0-9A-Z]{16}`, // AWS access key ID.
+	`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP) )?PRIVATE KEY( BLOCK)?-----`, // Private keys.
+	`gh[pousr]_[A-Za-z0-9]{36,}`,                                       // GitHub tokens.
+	`glpat-[A-Za-z0-9_-]{20,}`,                                         // GitLab tokens.
+	`xox[abprs]-[A-Za-z0-9-]{10,}`,                                     // Slack tokens.
+}
+
+// Commit message subjects which are not validated.
+var ignoredCommitSubjectPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+var conventionalCommitRegex = regexp.MustCompile(`^([a-zA-Z]+)(\(([^()]+)\))?!?: \S`)
+
+// The configuration of the commit message validation.
+type builtinCommitMessageConfig struct {
+	// The allowed commit types. Defaults to the conventional commit types.
+	Types []string `yaml:"types"`
+	// The allowed scopes (if any).
+	Scopes []string `yaml:"scopes"`
+	// The maximal length of the subject line (if not zero).
+	MaxSubjectLength int `yaml:"max-subject-length"`
+}
+
+// The configuration of the branch name validation.
+type builtinBranchNameConfig struct {
+	// Regular expressions of which any must match the branch name.
+	Patterns []string `yaml:"patterns"`
+
+	patterns []*regexp.Regexp
+}
+
+// The configuration of the staged file size limit.
+type builtinFileSizeConfig struct {
+	// The maximal size, e.g. `500KiB`, `1MB` or `1024`.
+	Max string `yaml:"max"`
+
+	maxBytes int64
+}
+
+// The configuration of the forbidden paths.
+type builtinForbiddenPathsConfig struct {
+	// Glob patterns of forbidden files.
+	Patterns []string `yaml:"patterns"`
+}
+
+// The configuration of the secret scanning.
+type builtinSecretsConfig struct {
+	// Additional regular expressions for secrets.
+	Patterns []string `yaml:"patterns"`
+	// If the default secret patterns are not used.
+	NoDefaultPatterns bool `yaml:"no-default-patterns"`
+
+	patterns []*regexp.Regexp
+}
+
+// The data for the built-in hooks configuration file.
+// A built-in hook is only enabled if its section is present.
+type builtinConfigFile struct {
+	CommitMessage  *builtinCommitMessageConfig  `yaml:"commit-message"`
+	BranchName     *builtinBranchNameConfig     `yaml:"branch-name"`
+	FileSize       *builtinFileSizeConfig       `yaml:"file-size"`
+	ForbiddenPaths *builtinForbiddenPathsConfig `yaml:"forbidden-paths"`
+	Secrets        *builtinSecretsConfig        `yaml:"secrets"`
+
+	Version int `yaml:"version"`
+}
+
+// The current built-in hooks configuration file version.
+// Version 1: Initial file.
+var builtinConfigFileVersion int = 1
+
+// BuiltinHook is a hook which is shipped with Githooks and runs in-process.
+type BuiltinHook struct {
+	// The name of the built-in hook, e.g. `secrets`.
+	Name string
+
+	config *builtinConfigFile
+}
+
+// GetBuiltinConfigFile gets the built-in hooks configuration file in hooks directory `hooksDir`.
+func GetBuiltinConfigFile(hooksDir string) string {
+	return path.Join(hooksDir, BuiltinConfigFileName)
+}
+
+func createBuiltinConfig() builtinConfigFile {
+	return builtinConfigFile{Version: builtinConfigFileVersion}
+}
+
+// loadBuiltinConfig loads and validates the built-in hooks configuration `file`.
+func loadBuiltinConfig(file string) (data builtinConfigFile, err error) {
+	data = createBuiltinConfig()
+	err = cm.LoadYAML(file, &data)
+	if err != nil {
+		return
+	}
+
+	if data.Version < 1 || data.Version > builtinConfigFileVersion {
+		err = cm.ErrorF(
+			"File '%s' has version '%v'. "+
+				"This version of Githooks only supports version >= 1 and <= '%v'.",
+			file,
+			data.Version,
+			builtinConfigFileVersion)
+
+		return
+	}
+
+	compile := func(section string, patterns []string) (res []*regexp.Regexp) {
+		for _, p := range patterns {
+			r, e := regexp.Compile(p)
+			if e != nil {
+				err = cm.CombineErrors(err,
+					cm.ErrorF("Section '%s' in '%s' has invalid pattern '%s'.", section, file, p), e)
+
+				continue
+			}
+
+			res = append(res, r)
+		}
+
+		return
+	}
+
+	if c := data.CommitMessage; c != nil && len(c.Types) == 0 {
+		c.Types = defaultConventionalCommitTypes
+	}
+
+	if c := data.BranchName; c != nil {
+		if len(c.Patterns) == 0 {
+			err = cm.CombineErrors(err,
+				cm.ErrorF("Section '%s' in '%s' needs 'patterns'.", BuiltinHookBranchName, file))
+		}
+
+		c.patterns = compile(BuiltinHookBranchName, c.Patterns)
+	}
+
+	if c := data.FileSize; c != nil {
+		var e error
+		if c.maxBytes, e = parseByteSize(c.Max); e != nil {
+			err = cm.CombineErrors(err,
+				cm.ErrorF("Section '%s' in '%s' has invalid 'max'.", BuiltinHookFileSize, file), e)
+		}
+	}
+
+	if c := data.ForbiddenPaths; c != nil {
+		for _, p := range c.Patterns {
+			if _, e := cm.GlobMatch(p, ""); e != nil {
+				err = cm.CombineErrors(err,
+					cm.ErrorF("Section '%s' in '%s' has invalid pattern '%s'.",
+						BuiltinHookForbiddenPaths, file, p), e)
+			}
+		}
+	}
+
+	if c := data.Secrets; c != nil {
+		if !c.NoDefaultPatterns {
+			c.patterns = compile(BuiltinHookSecrets, defaultSecretPatterns)
+		}
+
+		c.patterns = append(c.patterns, compile(BuiltinHookSecrets, c.Patterns)...)
+	}
+
+	return
+}
+
+// parseByteSize parses a size like `1024`, `500KB` or `1MiB` into bytes.
+func parseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, //nolint:mnd
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, //nolint:mnd
+		{"B", 1}}
+
+	number := strings.TrimSpace(s)
+	factor := int64(1)
+
+	for _, u := range units {
+		if strings.HasSuffix(number, u.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, u.suffix))
+			factor = u.factor
+
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(number, 64) //nolint:mnd
+	if err != nil || size <= 0 {
+		return 0, cm.ErrorF("Size '%s' must be a positive number with "+
+			"an optional unit 'B', 'KB', 'MB', 'GB', 'KiB', 'MiB' or 'GiB'.", s)
+	}
+
+	return int64(size * float64(factor)), nil
+}
+
+// GetBuiltinNamespacePath gets the namespace path of the built-in hook `name`
+// for hook `hookName`, e.g. `ns:gh-builtin/pre-commit/secrets`.
+func GetBuiltinNamespacePath(hookName string, name string) string {
+	return path.Join(NamespacePrefix+NamespaceBuiltinHook, hookName, name)
+}
+
+// GetBuiltinHooks gets all built-in hooks for hook `hookName` which are
+// enabled in the configuration in hooks directory `hooksDir`.
+// Built-in hooks are always trusted and are active if not ignored by `isIgnored`.
+func GetBuiltinHooks(
+	hooksDir string,
+	hookName string,
+	isIgnored IgnoreCallback) (allHooks []Hook, err error) {
+	names := builtinHookNames[hookName]
+	file := GetBuiltinConfigFile(hooksDir)
+
+	if len(names) == 0 || !cm.IsFile(file) {
+		return
+	}
+
+	config, err := loadBuiltinConfig(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if !config.isEnabled(name) {
+			continue
+		}
+
+		nsPath := GetBuiltinNamespacePath(hookName, name)
+		active := isIgnored == nil || !isIgnored(nsPath)
+
+		allHooks = append(allHooks,
+			Hook{
+				// The executable is only for displaying, it is never launched.
+				IExecutable:   &cm.Executable{Cmd: "builtin:" + name},
+				Path:          file,
+				Namespace:     NamespaceBuiltinHook,
+				NamespacePath: nsPath,
+				Active:        active,
+				Trusted:       true,
+				BatchName:     NamespaceBuiltinHook,
+				Entry:         name,
+				Builtin:       &BuiltinHook{Name: name, config: &config},
+			})
+	}
+
+	return
+}
+
+func (c *builtinConfigFile) isEnabled(name string) bool {
+	switch name {
+	case BuiltinHookCommitMessage:
+		return c.CommitMessage != nil
+	case BuiltinHookBranchName:
+		return c.BranchName != nil
+	case BuiltinHookFileSize:
+		return c.FileSize != nil
+	case BuiltinHookForbiddenPaths:
+		return c.ForbiddenPaths != nil
+	case BuiltinHookSecrets:
+		return c.Secrets != nil
+	}
+
+	return false
+}
+
+// Execute runs the built-in hook in the working directory of `ctx`
+// with the hook arguments `args`. It returns the output, the exit code and
+// an error if the hook found any violations.
+func (b *BuiltinHook) Execute(
+	ctx cm.IExecContext,
+	pipeSetup cm.PipeSetupFunc,
+	args ...string) ([]byte, int, error) {
+	var in io.Reader
+	var buffer bytes.Buffer
+	var out io.Writer = &buffer
+
+	if pipeSetup != nil {
+		var outPipe io.Writer
+		in, outPipe, _ = pipeSetup()
+
+		if outPipe != nil {
+			out = io.MultiWriter(&buffer, outPipe)
+		}
+	}
+
+	if in == nil {
+		in = strings.NewReader("")
+	}
+
+	gitx := git.NewCtxAt(ctx.GetWorkingDir())
+	violations, err := b.check(gitx, ctx.GetWorkingDir(), in, args)
+
+	if err != nil {
+		return buffer.Bytes(), -1, cm.CombineErrors(
+			cm.ErrorF("Built-in hook '%s' failed.", b.Name), err)
+	}
+
+	for _, v := range violations {
+		_, _ = strs.FmtW(out, "%s %s\n", cm.ListItemLiteral, v)
+	}
+
+	if len(violations) != 0 {
+		return buffer.Bytes(), 1, cm.ErrorF(
+			"Built-in hook '%s' found '%v' violations.", b.Name, len(violations))
+	}
+
+	return buffer.Bytes(), 0, nil
+}
+
+func (b *BuiltinHook) check(
+	gitx *git.Context,
+	rootDir string,
+	stdin io.Reader,
+	args []string) ([]string, error) {
+	switch b.Name {
+	case BuiltinHookCommitMessage:
+		return b.checkCommitMessage(rootDir, args)
+	case BuiltinHookBranchName:
+		return b.checkBranchNames(stdin)
+	case BuiltinHookFileSize:
+		return b.checkFileSizes(gitx)
+	case BuiltinHookForbiddenPaths:
+		return b.checkForbiddenPaths(gitx)
+	case BuiltinHookSecrets:
+		return b.checkSecrets(gitx)
+	}
+
+	return nil, cm.ErrorF("Built-in hook '%s' does not exist.", b.Name)
+}
+
+// checkCommitMessage validates the commit message in the file given
+// as first argument of `commit-msg`.
+func (b *BuiltinHook) checkCommitMessage(rootDir string, args []string) (violations []string, err error) {
+	if len(args) == 0 {
+		return nil, cm.Error("No commit message file given.")
+	}
+
+	file := args[0]
+	if !filepath.IsAbs(file) {
+		file = path.Join(rootDir, file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not read commit message '%s'.", file))
+	}
+
+	lines := getCommitMessageLines(string(data))
+	if len(lines) == 0 {
+		return
+	}
+
+	subject := lines[0]
+	for _, p := range ignoredCommitSubjectPrefixes {
+		if strings.HasPrefix(subject, p) {
+			return
+		}
+	}
+
+	config := b.config.CommitMessage
+	match := conventionalCommitRegex.FindStringSubmatch(subject)
+
+	switch {
+	case match == nil:
+		violations = append(violations,
+			strs.Fmt("Subject '%s' must be of the form '<type>[(<scope>)][!]: <description>'.", subject))
+	case !strs.Includes(config.Types, match[1]):
+		violations = append(violations,
+			strs.Fmt("Commit type '%s' must be one of '%s'.", match[1], strings.Join(config.Types, "', '")))
+	case len(config.Scopes) != 0 && strs.IsNotEmpty(match[3]) && !strs.Includes(config.Scopes, match[3]):
+		violations = append(violations,
+			strs.Fmt("Commit scope '%s' must be one of '%s'.", match[3], strings.Join(config.Scopes, "', '")))
+	}
+
+	if config.MaxSubjectLength > 0 && len([]rune(subject)) > config.MaxSubjectLength {
+		violations = append(violations,
+			strs.Fmt("Subject must not be longer than '%v' characters.", config.MaxSubjectLength))
+	}
+
+	if len(lines) > 1 && strs.IsNotEmpty(lines[1]) {
+		violations = append(violations, "Subject must be followed by an empty line.")
+	}
+
+	return
+}
+
+// getCommitMessageLines gets the lines of the commit message without
+// comments and without the diff below the scissors line.
+func getCommitMessageLines(msg string) (lines []string) {
+	for _, l := range strings.Split(msg, "\n") {
+		l = strings.TrimRight(l, " \r\t")
+
+		if strings.HasPrefix(l, "# ") && strings.HasSuffix(l, " >8 ------------------------") {
+			break
+		} else if strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		lines = append(lines, l)
+	}
+
+	// Strip leading and trailing empty lines.
+	for len(lines) != 0 && strs.IsEmpty(lines[0]) {
+		lines = lines[1:]
+	}
+
+	for len(lines) != 0 && strs.IsEmpty(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+
+	return
+}
+
+// checkBranchNames validates the names of all pushed branches given on the
+// standard input of `pre-push` as `<local-ref> <local-sha> <remote-ref> <remote-sha>`.
+func (b *BuiltinHook) checkBranchNames(stdin io.Reader) (violations []string, err error) {
+	scanner := bufio.NewScanner(stdin)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 { //nolint:mnd
+			continue
+		}
+
+		localSHA, remoteRef := fields[1], fields[2]
+		branch, isBranch := strings.CutPrefix(remoteRef, "refs/heads/")
+
+		if !isBranch || localSHA == git.NullRef {
+			continue // Tags and deletions are not validated.
+		}
+
+		matched := false
+		for _, r := range b.config.BranchName.patterns {
+			if r.MatchString(branch) {
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			violations = append(violations,
+				strs.Fmt("Branch name '%s' must match any of '%s'.",
+					branch, strings.Join(b.config.BranchName.Patterns, "', '")))
+		}
+	}
+
+	if e := scanner.Err(); e != nil {
+		return nil, cm.CombineErrors(e, cm.Error("Could not read pushed references."))
+	}
+
+	return
+}
+
+func getStagedFileList(gitx *git.Context) ([]string, error) {
+	files, err := GetStagedFiles(gitx)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.Error("Could not get staged files."))
+	}
+
+	return strs.Filter(strings.Split(files, "\x00"), strs.IsNotEmpty), nil
+}
+
+// checkFileSizes validates the size of all staged files.
+func (b *BuiltinHook) checkFileSizes(gitx *git.Context) (violations []string, err error) {
+	// Get the staged blobs: `:<mode> <mode> <sha> <sha> <status>\0<path>\0`.
+	raw, err := gitx.Get("diff", "--cached", "--diff-filter=ACM", "--no-renames", "--raw", "-z")
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.Error("Could not get staged files."))
+	}
+
+	var files, blobs []string
+
+	fields := strings.Split(raw, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		info := strings.Fields(fields[i])
+		if len(info) < 5 || info[1] == "160000" { //nolint:mnd // Skip submodules.
+			continue
+		}
+
+		files = append(files, fields[i+1])
+		blobs = append(blobs, info[3])
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	// Get all sizes with one call.
+	out, err := gitx.GetStdin(strings.NewReader(strings.Join(blobs, "\n")+"\n"),
+		"cat-file", "--batch-check=%(objectsize)")
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.Error("Could not get sizes of staged files."))
+	}
+
+	sizes := strs.SplitLines(out)
+	if len(sizes) != len(files) {
+		return nil, cm.ErrorF("Could not get sizes of all '%v' staged files.", len(files))
+	}
+
+	for i, file := range files {
+		size, e := strconv.ParseInt(sizes[i], 10, 64) //nolint:mnd
+		if e != nil {
+			return nil, cm.CombineErrors(e, cm.ErrorF("Could not get size of staged file '%s'.", file))
+		}
+
+		if size > b.config.FileSize.maxBytes {
+			violations = append(violations,
+				strs.Fmt("Staged file '%s' has '%v' bytes which exceeds the maximum '%s'.",
+					file, size, b.config.FileSize.Max))
+		}
+	}
+
+	return
+}
+
+// checkForbiddenPaths validates that no staged file matches a forbidden path.
+// Patterns without a `/` match the file name only.
+func (b *BuiltinHook) checkForbiddenPaths(gitx *git.Context) (violations []string, err error) {
+	files, err := getStagedFileList(gitx)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		for _, pattern := range b.config.ForbiddenPaths.Patterns {
+			p := file
+			if !strings.Contains(pattern, "/") {
+				p = path.Base(file)
+			}
+
+			if matched, _ := cm.GlobMatch(pattern, p); matched {
+				violations = append(violations,
+					strs.Fmt("Staged file '%s' matches forbidden path '%s'.", file, pattern))
+
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// checkSecrets scans all lines added in the staged changes for secrets.
+func (b *BuiltinHook) checkSecrets(gitx *git.Context) (violations []string, err error) {
+	diff, err := gitx.Get("diff", "--cached", "--diff-filter=ACMR", "-U0",
+		"--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/")
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.Error("Could not get staged changes."))
+	}
+
+	var file string
+	var lineNr int
+	inHeader := false // File headers only appear before the first hunk of a file.
+
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			inHeader = true
+		case inHeader && strings.HasPrefix(l, "+++ "):
+			file = getDiffHeaderPath(strings.TrimPrefix(l, "+++ "))
+		case strings.HasPrefix(l, "@@ "):
+			inHeader = false
+			lineNr = getHunkStartLine(l)
+		case !inHeader && strings.HasPrefix(l, "+"):
+			for _, r := range b.config.Secrets.patterns {
+				if r.MatchString(l[1:]) {
+					violations = append(violations,
+						strs.Fmt("Staged file '%s' line '%v' matches secret pattern '%s'.",
+							file, lineNr, r.String()))
+
+					break
+				}
+			}
+
+			lineNr++
+		}
+	}
+
+	return
+}
+
+// getDiffHeaderPath gets the path of a file header `+++ b/<path>` in a diff,
+// which is C-quoted by Git if it contains special characters.
+func getDiffHeaderPath(header string) string {
+	// Git terminates paths containing spaces with a tab.
+	header = strings.TrimSuffix(header, "\t")
+
+	if strings.HasPrefix(header, `"`) {
+		if p, err := strconv.Unquote(header); err == nil {
+			header = p
+		}
+	}
+
+	return strings.TrimPrefix(header, "b/")
+}
+
+// getHunkStartLine gets the start line in the new file
+// from the hunk header `@@ -a,b +c,d @@`.
+func getHunkStartLine(header string) int {
+	for _, f := range strings.Fields(header) {
+		if s, ok := strings.CutPrefix(f, "+"); ok {
+			s, _, _ = strings.Cut(s, ",")
+			n, _ := strconv.Atoi(s)
+
+			return n
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,492 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/agext/regexp"
+)
+
+// Condition is a parsed `when` expression of a hook run configuration.
+// A hook with a condition only runs if the condition evaluates to true.
+//
+// Grammar:
+//
+//	expr    := and { '||' and }
+//	and     := unary { '&&' unary }
+//	unary   := '!' unary | primary
+//	primary := '(' expr ')' | operand [ ('==' | '!=' | '=~' | '!~') operand ]
+//	operand := string | 'true' | 'false' | variable | 'args' '[' number ']'
+//
+// Strings are single or double quoted and support the variables
+// `${env:VAR}`, `${git:VAR}` etc. as in run configurations.
+// Variables are `hook`, `branch`, `remote`, `remoteUrl`,
+// `args`, `merging` and `rebasing`.
+// Operands which are not booleans are true if not empty.
+type Condition struct {
+	Expr string
+	root condNode
+}
+
+// ConditionContext is the state a condition is evaluated with.
+type ConditionContext struct {
+	HookName string
+	Args     []string
+
+	GitX      *git.Context
+	LookupEnv func(string) (string, bool)
+
+	branch *string
+}
+
+// NewConditionContext creates a context to evaluate conditions for the hook
+// `hookName` with arguments `args` in the repository of `gitx`.
+func NewConditionContext(gitx *git.Context, hookName string, args []string) *ConditionContext {
+	return &ConditionContext{HookName: hookName, Args: args, GitX: gitx, LookupEnv: os.LookupEnv}
+}
+
+// ParseCondition parses the `when` expression `expr`.
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, cm.CombineErrors(cm.ErrorF("Could not parse condition '%s'.", expr), err)
+	}
+
+	p := condParser{tokens: tokens}
+	root, err := p.parseOr()
+
+	if err == nil && p.peek().kind != condTokenEOF {
+		err = cm.ErrorF("Unexpected '%s' at position '%v'.", p.peek().text, p.peek().pos)
+	}
+
+	if err != nil {
+		return nil, cm.CombineErrors(cm.ErrorF("Could not parse condition '%s'.", expr), err)
+	}
+
+	return &Condition{Expr: expr, root: root}, nil
+}
+
+// Evaluate evaluates the condition.
+func (c *Condition) Evaluate(ctx *ConditionContext) (bool, error) {
+	v, err := c.root.eval(ctx)
+	if err != nil {
+		return false, cm.CombineErrors(cm.ErrorF("Could not evaluate condition '%s'.", c.Expr), err)
+	}
+
+	return v.isTrue(), nil
+}
+
+func (ctx *ConditionContext) getBranch() string {
+	if ctx.branch == nil {
+		// Detached heads have no branch.
+		branch, _ := ctx.GitX.GetCurrentBranch()
+		ctx.branch = &branch
+	}
+
+	return *ctx.branch
+}
+
+func (ctx *ConditionContext) getArg(idx int) string {
+	if idx < 0 || idx >= len(ctx.Args) {
+		return ""
+	}
+
+	return ctx.Args[idx]
+}
+
+func (ctx *ConditionContext) isInGitDir(paths ...string) (bool, error) {
+	gitDir, err := ctx.GitX.GetGitDirWorktree()
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range paths {
+		if exists, _ := cm.IsPathExisting(path.Join(gitDir, p)); exists {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (ctx *ConditionContext) getVariable(name string) (condValue, error) {
+	switch name {
+	case "true", "false":
+		return boolValue(name == "true"), nil
+	case "hook":
+		return stringValue(ctx.HookName), nil
+	case "branch":
+		return stringValue(ctx.getBranch()), nil
+	case "remote":
+		if ctx.HookName != "pre-push" {
+			return stringValue(""), nil
+		}
+
+		return stringValue(ctx.getArg(0)), nil
+	case "remoteUrl":
+		if ctx.HookName != "pre-push" {
+			return stringValue(""), nil
+		}
+
+		return stringValue(ctx.getArg(1)), nil
+	case "args":
+		return stringValue(strings.Join(ctx.Args, " ")), nil
+	case "merging":
+		b, err := ctx.isInGitDir("MERGE_HEAD")
+
+		return boolValue(b), err
+	case "rebasing":
+		b, err := ctx.isInGitDir("rebase-merge", "rebase-apply")
+
+		return boolValue(b), err
+	default:
+		return condValue{}, cm.ErrorF("Unknown variable '%s'.", name)
+	}
+}
+
+// condValue is a value of an evaluated expression.
+type condValue struct {
+	isBool bool
+	b      bool
+	s      string
+}
+
+func boolValue(b bool) condValue {
+	return condValue{isBool: true, b: b}
+}
+
+func stringValue(s string) condValue {
+	return condValue{s: s}
+}
+
+func (v condValue) isTrue() bool {
+	if v.isBool {
+		return v.b
+	}
+
+	return strs.IsNotEmpty(v.s)
+}
+
+func (v condValue) String() string {
+	if v.isBool {
+		return strconv.FormatBool(v.b)
+	}
+
+	return v.s
+}
+
+type condNode interface {
+	eval(ctx *ConditionContext) (condValue, error)
+}
+
+type condOr struct{ lhs, rhs condNode }
+type condAnd struct{ lhs, rhs condNode }
+type condNot struct{ expr condNode }
+type condString struct{ s string }
+type condVariable struct{ name string }
+type condArg struct{ idx int }
+type condCompare struct {
+	op       string
+	lhs, rhs condNode
+}
+
+func (n *condOr) eval(ctx *ConditionContext) (condValue, error) {
+	l, err := n.lhs.eval(ctx)
+	if err != nil || l.isTrue() {
+		return boolValue(l.isTrue()), err
+	}
+
+	r, err := n.rhs.eval(ctx)
+
+	return boolValue(r.isTrue()), err
+}
+
+func (n *condAnd) eval(ctx *ConditionContext) (condValue, error) {
+	l, err := n.lhs.eval(ctx)
+	if err != nil || !l.isTrue() {
+		return boolValue(false), err
+	}
+
+	r, err := n.rhs.eval(ctx)
+
+	return boolValue(r.isTrue()), err
+}
+
+func (n *condNot) eval(ctx *ConditionContext) (condValue, error) {
+	v, err := n.expr.eval(ctx)
+
+	return boolValue(!v.isTrue()), err
+}
+
+func (n *condString) eval(ctx *ConditionContext) (condValue, error) {
+	subst := getVarSubstitution(ctx.LookupEnv, ctx.GitX.LookupConfig,
+		getRunConfigVars(ctx.GitX, ctx.HookName, "", ""))
+	s, err := subst(n.s)
+
+	return stringValue(s), err
+}
+
+func (n *condVariable) eval(ctx *ConditionContext) (condValue, error) {
+	return ctx.getVariable(n.name)
+}
+
+func (n *condArg) eval(ctx *ConditionContext) (condValue, error) {
+	return stringValue(ctx.getArg(n.idx)), nil
+}
+
+func (n *condCompare) eval(ctx *ConditionContext) (condValue, error) {
+	l, err := n.lhs.eval(ctx)
+	if err != nil {
+		return condValue{}, err
+	}
+
+	r, err := n.rhs.eval(ctx)
+	if err != nil {
+		return condValue{}, err
+	}
+
+	switch n.op {
+	case "==":
+		return boolValue(l.String() == r.String()), nil
+	case "!=":
+		return boolValue(l.String() != r.String()), nil
+	default:
+		re, e := regexp.Compile(r.String())
+		if e != nil {
+			return condValue{}, cm.CombineErrors(cm.ErrorF("Invalid regex '%s'.", r.String()), e)
+		}
+
+		matches := re.MatchString(l.String())
+
+		return boolValue(matches == (n.op == "=~")), nil
+	}
+}
+
+type condTokenKind int
+
+const (
+	condTokenEOF condTokenKind = iota
+	condTokenIdent
+	condTokenString
+	condTokenNumber
+	condTokenOp
+)
+
+type condToken struct {
+	kind condTokenKind
+	text string
+	pos  int
+}
+
+func tokenizeCondition(expr string) (tokens []condToken, err error) {
+	ops := []string{"||", "&&", "==", "!=", "=~", "!~", "!", "(", ")", "[", "]"}
+	rs := []rune(expr)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'' || r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				j++
+			}
+
+			if j >= len(rs) {
+				return nil, cm.ErrorF("Unterminated string at position '%v'.", i)
+			}
+
+			tokens = append(tokens, condToken{kind: condTokenString, text: string(rs[i+1 : j]), pos: i})
+			i = j + 1
+
+		case unicode.IsLetter(r):
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+
+			tokens = append(tokens, condToken{kind: condTokenIdent, text: string(rs[i:j]), pos: i})
+			i = j
+
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && unicode.IsDigit(rs[j]) {
+				j++
+			}
+
+			tokens = append(tokens, condToken{kind: condTokenNumber, text: string(rs[i:j]), pos: i})
+			i = j
+
+		default:
+			op := ""
+			for _, o := range ops {
+				if strings.HasPrefix(string(rs[i:]), o) {
+					op = o
+
+					break
+				}
+			}
+
+			if strs.IsEmpty(op) {
+				return nil, cm.ErrorF("Unexpected character '%c' at position '%v'.", r, i)
+			}
+
+			tokens = append(tokens, condToken{kind: condTokenOp, text: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, condToken{kind: condTokenEOF, text: "end", pos: len(rs)}), nil
+}
+
+type condParser struct {
+	tokens []condToken
+	pos    int
+}
+
+func (p *condParser) peek() condToken {
+	return p.tokens[p.pos]
+}
+
+func (p *condParser) next() condToken {
+	t := p.tokens[p.pos]
+	if t.kind != condTokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *condParser) isOp(op string) bool {
+	t := p.peek()
+
+	return t.kind == condTokenOp && t.text == op
+}
+
+func (p *condParser) expectOp(op string) error {
+	if !p.isOp(op) {
+		return cm.ErrorF("Expected '%s' but got '%s' at position '%v'.", op, p.peek().text, p.peek().pos)
+	}
+
+	p.next()
+
+	return nil
+}
+
+func (p *condParser) parseOr() (condNode, error) {
+	lhs, err := p.parseAnd()
+
+	for err == nil && p.isOp("||") {
+		p.next()
+
+		var rhs condNode
+		rhs, err = p.parseAnd()
+		lhs = &condOr{lhs: lhs, rhs: rhs}
+	}
+
+	return lhs, err
+}
+
+func (p *condParser) parseAnd() (condNode, error) {
+	lhs, err := p.parseUnary()
+
+	for err == nil && p.isOp("&&") {
+		p.next()
+
+		var rhs condNode
+		rhs, err = p.parseUnary()
+		lhs = &condAnd{lhs: lhs, rhs: rhs}
+	}
+
+	return lhs, err
+}
+
+func (p *condParser) parseUnary() (condNode, error) {
+	if p.isOp("!") {
+		p.next()
+		expr, err := p.parseUnary()
+
+		return &condNot{expr: expr}, err
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *condParser) parsePrimary() (condNode, error) {
+	if p.isOp("(") {
+		p.next()
+
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		return expr, p.expectOp(")")
+	}
+
+	lhs, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", "=~", "!~"} {
+		if p.isOp(op) {
+			p.next()
+
+			rhs, err := p.parseOperand()
+
+			return &condCompare{op: op, lhs: lhs, rhs: rhs}, err
+		}
+	}
+
+	return lhs, nil
+}
+
+func (p *condParser) parseOperand() (condNode, error) {
+	t := p.next()
+
+	switch t.kind {
+	case condTokenString:
+		return &condString{s: t.text}, nil
+
+	case condTokenIdent:
+		if t.text != "args" || !p.isOp("[") {
+			if !strs.Includes(conditionVariables, t.text) {
+				return nil, cm.ErrorF("Unknown variable '%s' at position '%v'.", t.text, t.pos)
+			}
+
+			return &condVariable{name: t.text}, nil
+		}
+
+		p.next()
+
+		idx := p.next()
+		if idx.kind != condTokenNumber {
+			return nil, cm.ErrorF("Expected an index but got '%s' at position '%v'.", idx.text, idx.pos)
+		}
+
+		i, err := strconv.Atoi(idx.text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &condArg{idx: i}, p.expectOp("]")
+
+	default:
+		return nil, cm.ErrorF("Unexpected '%s' at position '%v'.", t.text, t.pos)
+	}
+}
+
+// conditionVariables are all variables usable in conditions.
+var conditionVariables = []string{
+	"true", "false", "hook", "branch", "remote", "remoteUrl", "args", "merging", "rebasing"}
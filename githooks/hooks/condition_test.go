@@ -0,0 +1,99 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionParse(t *testing.T) {
+	valid := []string{
+		"true",
+		"!false",
+		"branch == 'main'",
+		"branch =~ '^release/.*' || (hook == \"pre-push\" && remote != 'upstream')",
+		"args[0] !~ 'fixup'",
+		"!merging && !rebasing",
+		"'${env:CI}'",
+	}
+
+	for _, expr := range valid {
+		_, err := ParseCondition(expr)
+		assert.NoError(t, err, "Expression: '%s'", expr)
+	}
+
+	invalid := []string{
+		"",
+		"branch ==",
+		"(branch == 'main'",
+		"branch == 'main",
+		"unknown == 'a'",
+		"args[a]",
+		"branch = 'main'",
+		"true false",
+	}
+
+	for _, expr := range invalid {
+		_, err := ParseCondition(expr)
+		assert.Error(t, err, "Expression: '%s'", expr)
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	repo, gitx := newTestRepo(t, "-b", "feature/a")
+	require.NoError(t, gitx.SetConfig("my.setting", "on", git.LocalScope))
+
+	env := map[string]string{"CI": "true"}
+	ctx := NewConditionContext(gitx, "pre-push", []string{"origin", "https://example.com/repo.git"})
+	ctx.LookupEnv = func(k string) (string, bool) {
+		v, ok := env[k]
+
+		return v, ok
+	}
+
+	tests := map[string]bool{
+		"true":                         true,
+		"!true":                        false,
+		"branch == 'feature/a'":        true,
+		"branch =~ '^feature/'":        true,
+		"branch !~ '^feature/'":        false,
+		"remote == 'origin'":           true,
+		"remoteUrl =~ 'example\\.com'": true,
+		"args[1] == 'https://example.com/repo.git'": true,
+		"args[5]": false,
+		"hook == 'pre-push' && remote != 'origin'":   false,
+		"hook == 'pre-commit' || remote == 'origin'": true,
+		"'${env:CI}' == 'true'":                      true,
+		"'${env:NOPE}'":                              false,
+		"'${git:my.setting}' == 'on'":                true,
+		"merging || rebasing":                        false,
+	}
+
+	for expr, expected := range tests {
+		cond, err := ParseCondition(expr)
+		require.NoError(t, err, "Expression: '%s'", expr)
+
+		res, err := cond.Evaluate(ctx)
+		require.NoError(t, err, "Expression: '%s'", expr)
+		assert.Equal(t, expected, res, "Expression: '%s'", expr)
+	}
+
+	// Merge in progress.
+	require.NoError(t, os.WriteFile(path.Join(repo, ".git", "MERGE_HEAD"), nil, 0600)) //nolint:mnd
+	cond, err := ParseCondition("merging")
+	require.NoError(t, err)
+	res, err := cond.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.True(t, res)
+
+	// Invalid regex.
+	cond, err = ParseCondition("branch =~ '('")
+	require.NoError(t, err)
+	_, err = cond.Evaluate(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+const (
+	// EnvironmentTypePython is a Python virtual environment
+	// with the packages of a `requirements.txt` file.
+	EnvironmentTypePython = "python"
+	// EnvironmentTypeNode is a Node environment
+	// with the modules of a `package.json` file.
+	EnvironmentTypeNode = "node"
+)
+
+// The file marking a completely set up environment.
+const environmentReadyFile = ".githooks-ready"
+
+// The environment config in a hook run configuration.
+type environmentConfig struct {
+	// The type of the environment `python` or `node`.
+	Type string `yaml:"type"`
+	// The `requirements.txt` for `python`, relative to the repository.
+	Requirements string `yaml:"requirements"`
+	// The `package.json` for `node`, relative to the repository.
+	Package string `yaml:"package"`
+}
+
+// HookEnvironment is a managed tool environment for a hook
+// which is cached under the install directory.
+type HookEnvironment struct {
+	// The type of the environment.
+	Type string
+
+	// The absolute path to the manifest file
+	// (`requirements.txt` or `package.json`).
+	Manifest string
+
+	// The directory of the environment.
+	Dir string
+}
+
+// GetEnvironmentsDir gets the directory where all hook environments are cached.
+func GetEnvironmentsDir(installDir string) string {
+	return path.Join(installDir, "environments")
+}
+
+// newHookEnvironment creates the environment from the `config` in
+// run configuration `file` in repository `rootDir`.
+// The environment directory is keyed by the hash of the manifest file.
+func newHookEnvironment(
+	installDir string,
+	rootDir string,
+	config *environmentConfig,
+	file string) (*HookEnvironment, error) {
+	var manifest string
+
+	switch config.Type {
+	case EnvironmentTypePython:
+		manifest = config.Requirements
+	case EnvironmentTypeNode:
+		manifest = config.Package
+	default:
+		return nil, cm.ErrorF("Environment type '%s' in '%s' is not supported.\n"+
+			"Use 'python' or 'node'.", config.Type, file)
+	}
+
+	if strs.IsEmpty(manifest) {
+		return nil, cm.ErrorF("Environment of type '%s' in '%s' needs a "+
+			"'requirements' (python) or 'package' (node) file.", config.Type, file)
+	}
+
+	if strs.IsEmpty(installDir) {
+		return nil, cm.ErrorF("Environment in '%s' needs an install directory.", file)
+	}
+
+	if runtime.GOOS == cm.WindowsOsName {
+		manifest = filepath.ToSlash(manifest)
+	}
+
+	if !filepath.IsAbs(manifest) {
+		manifest = path.Join(rootDir, manifest)
+	}
+
+	sha, err := cm.GetSHA1HashFile(manifest)
+	if err != nil {
+		return nil, cm.CombineErrors(err,
+			cm.ErrorF("Could not hash environment file '%s' in '%s'.", manifest, file))
+	}
+
+	return &HookEnvironment{
+		Type:     config.Type,
+		Manifest: manifest,
+		Dir:      path.Join(GetEnvironmentsDir(installDir), config.Type+"-"+sha)}, nil
+}
+
+// IsReady returns `true` if the environment is completely set up.
+func (e *HookEnvironment) IsReady() bool {
+	return cm.IsFile(path.Join(e.Dir, environmentReadyFile))
+}
+
+// GetBinDir gets the directory with the executables of the environment.
+func (e *HookEnvironment) GetBinDir() string {
+	switch {
+	case e.Type == EnvironmentTypeNode:
+		return path.Join(e.Dir, "node_modules", ".bin")
+	case runtime.GOOS == cm.WindowsOsName:
+		return path.Join(e.Dir, "Scripts")
+	default:
+		return path.Join(e.Dir, "bin")
+	}
+}
+
+// GetEnv gets the environment variables which put the environment on the `PATH`.
+func (e *HookEnvironment) GetEnv() []string {
+	env := []string{
+		"PATH=" + filepath.FromSlash(e.GetBinDir()) +
+			string(filepath.ListSeparator) + os.Getenv("PATH")}
+
+	switch e.Type {
+	case EnvironmentTypePython:
+		env = append(env, "VIRTUAL_ENV="+filepath.FromSlash(e.Dir))
+	case EnvironmentTypeNode:
+		env = append(env, "NODE_PATH="+filepath.FromSlash(path.Join(e.Dir, "node_modules")))
+	}
+
+	return env
+}
+
+// Setup sets up the environment if it is not yet ready.
+func (e *HookEnvironment) Setup(log cm.ILogContext) (err error) {
+	if e.IsReady() {
+		return nil
+	}
+
+	log.InfoF("Setting up %s environment for '%s' ...", e.Type, e.Manifest)
+
+	// Remove any partially set up environment.
+	if err = os.RemoveAll(e.Dir); err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not remove environment '%s'.", e.Dir))
+	}
+
+	if err = os.MkdirAll(e.Dir, cm.DefaultFileModeDirectory); err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not create environment '%s'.", e.Dir))
+	}
+
+	var cmds []cm.Executable
+
+	switch e.Type {
+	case EnvironmentTypePython:
+		python := path.Join(e.GetBinDir(), "python")
+		cmds = []cm.Executable{
+			{Cmd: "python3", Args: []string{"-m", "venv", e.Dir}},
+			{Cmd: python, Args: []string{"-m", "pip", "install", "--quiet", "-r", e.Manifest}}}
+	case EnvironmentTypeNode:
+		if err = cm.CopyFileOrDirectory(e.Manifest, path.Join(e.Dir, "package.json")); err != nil {
+			return cm.CombineErrors(err, cm.ErrorF("Could not copy '%s'.", e.Manifest))
+		}
+
+		cmds = []cm.Executable{
+			{Cmd: "npm", Args: []string{"install", "--ignore-scripts", "--no-audit", "--no-fund", "--silent"}}}
+	}
+
+	execx := cm.ExecContext{Cwd: e.Dir, Env: os.Environ()}
+	for i := range cmds {
+		out, _, err := cm.GetCombinedOutputFromExecutable(&execx, &cmds[i], nil)
+		if err != nil {
+			return cm.CombineErrors(
+				cm.ErrorF("Setting up environment '%s' failed:\n%s",
+					e.Dir, strings.TrimSpace(string(out))), err)
+		}
+	}
+
+	if err = cm.TouchFile(path.Join(e.Dir, environmentReadyFile), false); err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not mark environment '%s' as ready.", e.Dir))
+	}
+
+	log.InfoF("  %s Environment set up in '%s'.", cm.ListItemLiteral, e.Dir)
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookEnvironment(t *testing.T) {
+	installDir := t.TempDir()
+	repo := t.TempDir()
+
+	require.NoError(t, os.WriteFile(path.Join(repo, "requirements.txt"), []byte("black==24.1.0\n"), 0600)) //nolint:mnd
+	require.NoError(t, os.WriteFile(path.Join(repo, "other.txt"), []byte("black==24.1.0\n"), 0600))        //nolint:mnd
+
+	config := environmentConfig{Type: EnvironmentTypePython, Requirements: "requirements.txt"}
+	env, err := newHookEnvironment(installDir, repo, &config, "check.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, path.Join(repo, "requirements.txt"), env.Manifest)
+	assert.True(t, strings.HasPrefix(env.Dir, GetEnvironmentsDir(installDir)+"/python-"))
+	assert.False(t, env.IsReady())
+
+	// The same manifest content gives the same environment.
+	config.Requirements = "other.txt"
+	other, err := newHookEnvironment(installDir, repo, &config, "check.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, env.Dir, other.Dir)
+
+	vars := env.GetEnv()
+	require.Len(t, vars, 2) //nolint:mnd
+	assert.True(t, strings.HasPrefix(vars[0], "PATH="+filepath.FromSlash(env.GetBinDir())))
+	assert.Equal(t, "VIRTUAL_ENV="+filepath.FromSlash(env.Dir), vars[1])
+
+	// Ready environments are not set up again.
+	require.NoError(t, cm.TouchFile(path.Join(env.Dir, environmentReadyFile), true))
+	assert.True(t, env.IsReady())
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+	assert.NoError(t, env.Setup(log))
+
+	_, err = newHookEnvironment(installDir, repo,
+		&environmentConfig{Type: "ruby", Requirements: "requirements.txt"}, "check.yaml")
+	assert.Error(t, err)
+
+	_, err = newHookEnvironment(installDir, repo,
+		&environmentConfig{Type: EnvironmentTypeNode}, "check.yaml")
+	assert.Error(t, err)
+
+	_, err = newHookEnvironment(installDir, repo,
+		&environmentConfig{Type: EnvironmentTypeNode, Package: "package.json"}, "check.yaml")
+	assert.Error(t, err, "Manifest does not exist.")
+}
+
+func TestRunConfigEnvironment(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	installDir := t.TempDir()
+	require.NoError(t, SetInstallDir(gitx, installDir))
+
+	require.NoError(t, os.WriteFile(path.Join(repo, "package.json"), []byte("{}"), 0600)) //nolint:mnd
+
+	file := path.Join(repo, "check.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(
+		"version: 5\n"+
+			"cmd: eslint\n"+
+			"env: [A=1]\n"+
+			"environment:\n"+
+			"  type: node\n"+
+			"  package: package.json\n"), 0600)) //nolint:mnd
+
+	run, err := getHookRunCmd(gitx, "pre-commit", file, repo, repo, true, nil, "", nil)
+	require.NoError(t, err)
+	require.NotNil(t, run.environment)
+
+	assert.Equal(t, EnvironmentTypeNode, run.environment.Type)
+	assert.Equal(t, path.Join(repo, "package.json"), run.environment.Manifest)
+	assert.Equal(t, append(run.environment.GetEnv(), "A=1"), run.exec.GetEnvironment())
+
+	// A changed manifest changes the trusted checksum of the hook.
+	sha, err := GetHookSHA1(file, run.override)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path.Join(repo, "package.json"), []byte(`{"a": 1}`), 0600)) //nolint:mnd
+	run, err = getHookRunCmd(gitx, "pre-commit", file, repo, repo, true, nil, "", nil)
+	require.NoError(t, err)
+
+	shaChanged, err := GetHookSHA1(file, run.override)
+	require.NoError(t, err)
+	assert.NotEqual(t, sha, shaChanged)
+}
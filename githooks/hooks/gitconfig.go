@@ -10,6 +10,9 @@ const (
 
 	GitCKUpdateCheckEnabled       = "githooks.updateCheckEnabled"
 	GitCKUpdateCheckUsePrerelease = "githooks.updateCheckUsePrerelease"
+	GitCKUpdateChannel            = "githooks.updateChannel"
+	GitCKUpdateRolloutID          = "githooks.updateRolloutId"
+	GitCKUpdateBlockedVersions    = "githooks.updateBlockedVersions"
 
 	GitCKBugReportInfo = "githooks.bugReportInfo"
 
@@ -55,6 +58,15 @@ const (
 	GitCKContainerImageUpdateAutomatic = "githooks.containerImageUpdateAutomatic"
 
 	GitCKExportStagedFilesAsFile = "githooks.exportStagedFilesAsFile"
+
+	GitCKHistoryDisabled = "githooks.historyDisabled"
+	GitCKHistoryOutput   = "githooks.historyOutput"
+	GitCKHookTimeBudget  = "githooks.hookTimeBudget"
+	GitCKOutputMode      = "githooks.outputMode"
+	GitCKInterpreters    = "githooks.interpreters"
+
+	GitCKShareWorktreeState = "githooks.shareWorktreeState"
+	GitCKServerMode         = "githooks.serverMode"
 )
 
 // GetGlobalGitConfigKeys gets all global git config keys relevant for Githooks.
@@ -72,6 +84,9 @@ func GetGlobalGitConfigKeys() []string {
 
 		GitCKUpdateCheckEnabled,
 		GitCKUpdateCheckUsePrerelease,
+		GitCKUpdateChannel,
+		GitCKUpdateRolloutID,
+		GitCKUpdateBlockedVersions,
 
 		GitCKBugReportInfo,
 
@@ -102,6 +117,14 @@ func GetGlobalGitConfigKeys() []string {
 		GitCKExportStagedFilesAsFile,
 
 		GitCKContainerizedHooksEnabled,
+
+		GitCKHistoryDisabled,
+		GitCKHistoryOutput,
+		GitCKHookTimeBudget,
+		GitCKOutputMode,
+		GitCKInterpreters,
+		GitCKShareWorktreeState,
+		GitCKServerMode,
 	}
 }
 
@@ -126,6 +149,14 @@ func GetLocalGitConfigKeys() []string {
 		GitCKContainerizedHooksEnabled,
 
 		GitCKExportStagedFilesAsFile,
+
+		GitCKHistoryDisabled,
+		GitCKHistoryOutput,
+		GitCKHookTimeBudget,
+		GitCKOutputMode,
+		GitCKInterpreters,
+		GitCKShareWorktreeState,
+		GitCKServerMode,
 	}
 }
 
@@ -93,6 +93,23 @@ var ManagedServerHookNames = []string{
 	"push-to-checkout",
 	"pre-auto-gc"}
 
+// OptInHookNames are hook names only managed by Githooks if they
+// are explicitly listed in the maintained hooks. They are not part of 'all' or 'server'.
+var OptInHookNames = []string{
+	"fsmonitor-watchman",
+	"proc-receive",
+	"p4-changelist",
+	"p4-prepare-changelist",
+	"p4-post-changelist",
+	"p4-pre-submit"}
+
+// OptInServerHookNames are the opt-in hook names managed for bare repositories.
+var OptInServerHookNames = []string{
+	"proc-receive"}
+
+// SupportedHookNames are all hook names Githooks can manage.
+var SupportedHookNames = append(append([]string{}, ManagedHookNames...), OptInHookNames...)
+
 // LFSHookNames are the hook names of all Large File System (LFS) hooks.
 var LFSHookNames = [4]string{
 	"post-checkout",
@@ -127,7 +144,10 @@ func GetGithooksEnvVariables(newStagedFilesFile string) []string {
 		env = append(env, strs.Fmt("%s=%s", names[i], os.Getenv(names[i])))
 	}
 
-	names = []string{EnvVariableStagedFiles, EnvVariableStagedFilesFile}
+	names = []string{
+		EnvVariableStagedFiles, EnvVariableStagedFilesFile,
+		EnvVariableRefUpdates, EnvVariableChangedFilesFile,
+		EnvVariablePushUpdates, EnvVariablePushedFilesFile}
 	for i := range names {
 		if val, exists := os.LookupEnv(names[i]); exists {
 			// Modify the file name.
@@ -0,0 +1,313 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// The maximal number of runs kept in the history of a repository.
+	historyMaxRuns = 100
+	// The maximal number of output bytes kept for each hook in the history.
+	historyMaxOutput = 8 * 1024
+	// The maximal number of output bytes kept for all hooks of a run in the history.
+	historyMaxRunOutput = 64 * 1024
+	// The maximal size of a run in the history file. Longer runs are skipped on loading.
+	historyMaxLine = 4 * 1024 * 1024
+
+	// The time to wait for the lock of a history file and
+	// the age after which a lock is considered stale.
+	historyLockTimeout = 2 * time.Second
+	historyLockStale   = 10 * time.Second
+)
+
+// HistoryHook is the recorded result of an executed hook.
+type HistoryHook struct {
+	NamespacePath   string        `json:"namespacePath"`
+	Path            string        `json:"path"`
+	ExitCode        int           `json:"exitCode"`
+	Duration        time.Duration `json:"duration"`
+	Error           string        `json:"error,omitempty"`
+	Output          string        `json:"output,omitempty"`
+	OutputTruncated bool          `json:"outputTruncated,omitempty"`
+}
+
+// HistoryRun is the recorded execution of all hooks of a hook invocation.
+type HistoryRun struct {
+	ID         string        `json:"id"`
+	Repository string        `json:"repository"`
+	HookName   string        `json:"hookName"`
+	Args       []string      `json:"args,omitempty"`
+	Simulated  bool          `json:"simulated,omitempty"`
+	Start      time.Time     `json:"start"`
+	Duration   time.Duration `json:"duration"`
+	Failed     bool          `json:"failed"`
+	Hooks      []HistoryHook `json:"hooks"`
+
+	// If the outputs of the hooks are recorded.
+	RecordOutput bool `json:"-"`
+	// The number of output bytes recorded so far.
+	outputSize int
+}
+
+// NewHistoryRun creates a new history run for hook `hookName` with arguments `args`
+// in repository `repoDir` starting now.
+func NewHistoryRun(repoDir string, hookName string, args []string) HistoryRun {
+	return HistoryRun{
+		ID:         uuid.New().String(),
+		Repository: repoDir,
+		HookName:   hookName,
+		Args:       args,
+		Start:      time.Now()}
+}
+
+// AddResults adds the results of executed hooks to the run.
+// The outputs are only recorded if `RecordOutput` is set and
+// are truncated to the last bytes within the budget of the run.
+func (r *HistoryRun) AddResults(res ...HookResult) {
+	for i := range res {
+		h := HistoryHook{
+			NamespacePath: res[i].Hook.NamespacePath,
+			Path:          res[i].Hook.Path,
+			ExitCode:      res[i].ExitCode,
+			Duration:      res[i].Duration}
+
+		if res[i].Error != nil {
+			h.Error = res[i].Error.Error()
+			r.Failed = true
+		}
+
+		if r.RecordOutput {
+			output := res[i].Output
+			maxOutput := min(historyMaxOutput, historyMaxRunOutput-r.outputSize)
+
+			if len(output) > maxOutput {
+				output = output[len(output)-maxOutput:]
+				h.OutputTruncated = true
+			}
+
+			h.Output = string(output)
+			r.outputSize += len(output)
+		}
+
+		r.Hooks = append(r.Hooks, h)
+	}
+}
+
+// Finish sets the duration of the run.
+func (r *HistoryRun) Finish() {
+	r.Duration = time.Since(r.Start)
+}
+
+// GetHistoryDir gets the directory with the execution history of all repositories
+// inside the install directory.
+func GetHistoryDir(installDir string) string {
+	return path.Join(installDir, "history")
+}
+
+// getHistoryFile gets the execution history file of the repository `repoDir`.
+func getHistoryFile(installDir string, repoDir string) (string, error) {
+	key, err := cm.GetSHA1Hash(strings.NewReader(filepath.ToSlash(repoDir)))
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(GetHistoryDir(installDir), key+".jsonl"), nil
+}
+
+// AppendHistory appends the run `run` to the execution history of its repository.
+// Only the last runs are kept.
+func AppendHistory(installDir string, run *HistoryRun) error {
+	file, err := getHistoryFile(installDir, run.Repository)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(run)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not serialize history run."))
+	}
+
+	if err = os.MkdirAll(path.Dir(file), cm.DefaultFileModeDirectory); err != nil {
+		return err
+	}
+
+	// Concurrent hook runs in the same repository append to the same file.
+	unlock, err := lockHistory(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, cm.DefaultFileModeFile)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(line, '\n'))
+	err = cm.CombineErrors(err, f.Close())
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not write history file '%s'.", file))
+	}
+
+	return trimHistory(file, historyMaxRuns)
+}
+
+// lockHistory locks the history file `file` against concurrent writes.
+// A stale lock of an aborted run is removed.
+func lockHistory(file string) (unlock func(), err error) {
+	lock := file + ".lock"
+	deadline := time.Now().Add(historyLockTimeout)
+
+	for {
+		f, e := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, cm.DefaultFileModeFile)
+		if e == nil {
+			_ = f.Close()
+
+			return func() { _ = os.Remove(lock) }, nil
+		} else if !os.IsExist(e) {
+			return nil, cm.CombineErrors(e, cm.ErrorF("Could not lock history file '%s'.", file))
+		}
+
+		if info, e := os.Stat(lock); e == nil && time.Since(info.ModTime()) > historyLockStale {
+			_ = os.Remove(lock)
+
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, cm.ErrorF("Timeout while waiting for the lock '%s'.", lock)
+		}
+
+		time.Sleep(10 * time.Millisecond) //nolint:mnd
+	}
+}
+
+// trimHistory keeps only the last `maxRuns` runs in the history file `file`.
+// The history file needs to be locked.
+func trimHistory(file string, maxRuns int) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if last := len(lines) - 1; len(lines[last]) == 0 {
+		lines = lines[:last]
+	}
+
+	if len(lines) <= maxRuns {
+		return nil
+	}
+
+	temp, err := os.CreateTemp(path.Dir(file), path.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	_, err = temp.Write(bytes.Join(lines[len(lines)-maxRuns:], nil))
+	err = cm.CombineErrors(err, temp.Close())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), file)
+}
+
+// loadHistoryFile loads all runs in the history file `file`.
+// Lines which cannot be read or are too long are skipped.
+func loadHistoryFile(file string) (runs []HistoryRun, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := bufio.NewReader(f)
+
+	for {
+		line, e := reader.ReadBytes('\n')
+
+		var run HistoryRun
+		if len(line) <= historyMaxLine && json.Unmarshal(line, &run) == nil {
+			runs = append(runs, run)
+		}
+
+		if e == io.EOF {
+			return runs, nil
+		} else if e != nil {
+			return runs, e
+		}
+	}
+}
+
+// LoadHistory loads the execution history of the repository `repoDir`
+// ordered from the oldest to the newest run.
+func LoadHistory(installDir string, repoDir string) ([]HistoryRun, error) {
+	file, err := getHistoryFile(installDir, repoDir)
+	if err != nil || !cm.IsFile(file) {
+		return nil, err
+	}
+
+	runs, err := loadHistoryFile(file)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not load history file '%s'.", file))
+	}
+
+	return runs, nil
+}
+
+// LoadAllHistory loads the execution history of all repositories
+// ordered from the oldest to the newest run.
+func LoadAllHistory(installDir string) (history map[string][]HistoryRun, err error) {
+	history = make(map[string][]HistoryRun)
+
+	dir := GetHistoryDir(installDir)
+	if !cm.IsDirectory(dir) {
+		return
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not read history directory '%s'.", dir))
+	}
+
+	for _, f := range files {
+		if f.IsDir() || path.Ext(f.Name()) != ".jsonl" {
+			continue
+		}
+
+		runs, e := loadHistoryFile(path.Join(dir, f.Name()))
+		if e != nil {
+			err = cm.CombineErrors(err, e)
+
+			continue
+		}
+
+		for i := range runs {
+			if strs.IsNotEmpty(runs[i].Repository) {
+				history[runs[i].Repository] = append(history[runs[i].Repository], runs[i])
+			}
+		}
+	}
+
+	for _, runs := range history {
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].Start.Before(runs[j].Start) })
+	}
+
+	return
+}
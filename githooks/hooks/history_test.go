@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	installDir := t.TempDir()
+
+	runs, err := LoadHistory(installDir, "/repo/a")
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	hookA := Hook{Path: "/repo/a/.githooks/pre-commit/a", NamespacePath: "ns:a/pre-commit/a"}
+	hookB := Hook{Path: "/repo/a/.githooks/pre-commit/b", NamespacePath: "ns:a/pre-commit/b"}
+
+	run := NewHistoryRun("/repo/a", "pre-commit", []string{"arg"})
+	run.RecordOutput = true
+	run.AddResults(
+		HookResult{Hook: &hookA, Output: []byte("ok\n"), Duration: time.Second},
+		HookResult{Hook: &hookB, Output: []byte(strings.Repeat("x", historyMaxOutput+1)),
+			Error: cm.Error("failed"), ExitCode: 1})
+	run.Finish()
+	require.NoError(t, AppendHistory(installDir, &run))
+
+	other := NewHistoryRun("/repo/b", "pre-push", nil)
+	other.Finish()
+	require.NoError(t, AppendHistory(installDir, &other))
+
+	runs, err = LoadHistory(installDir, "/repo/a")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	r := runs[0]
+	assert.Equal(t, run.ID, r.ID)
+	assert.Equal(t, "pre-commit", r.HookName)
+	assert.Equal(t, []string{"arg"}, r.Args)
+	assert.True(t, r.Failed)
+	require.Len(t, r.Hooks, 2)
+	assert.Equal(t, "ns:a/pre-commit/a", r.Hooks[0].NamespacePath)
+	assert.Equal(t, "ok\n", r.Hooks[0].Output)
+	assert.Equal(t, time.Second, r.Hooks[0].Duration)
+	assert.Empty(t, r.Hooks[0].Error)
+	assert.Equal(t, "failed", r.Hooks[1].Error)
+	assert.Equal(t, 1, r.Hooks[1].ExitCode)
+	assert.True(t, r.Hooks[1].OutputTruncated)
+	assert.Len(t, r.Hooks[1].Output, historyMaxOutput)
+
+	all, err := LoadAllHistory(installDir)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Len(t, all["/repo/b"], 1)
+	assert.False(t, all["/repo/b"][0].Failed)
+}
+
+func TestHistoryTrim(t *testing.T) {
+	installDir := t.TempDir()
+
+	var last HistoryRun
+	for i := 0; i < 5; i++ {
+		last = NewHistoryRun("/repo", "pre-commit", nil)
+		require.NoError(t, AppendHistory(installDir, &last))
+	}
+
+	file, err := getHistoryFile(installDir, "/repo")
+	require.NoError(t, err)
+	require.NoError(t, trimHistory(file, 3)) //nolint:mnd
+
+	runs, err := LoadHistory(installDir, "/repo")
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, last.ID, runs[2].ID)
+
+	// Broken lines are skipped.
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("{broken\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	runs, err = LoadHistory(installDir, "/repo")
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+}
+
+func TestHistoryOutput(t *testing.T) {
+	installDir := t.TempDir()
+	hook := Hook{Path: "/repo/.githooks/pre-commit/a", NamespacePath: "ns:a/pre-commit/a"}
+
+	// Outputs are not recorded by default.
+	run := NewHistoryRun("/repo", "pre-commit", nil)
+	run.AddResults(HookResult{Hook: &hook, Output: []byte("secret"), ExitCode: 1})
+	assert.Empty(t, run.Hooks[0].Output)
+	assert.Equal(t, 1, run.Hooks[0].ExitCode)
+
+	// The outputs of all hooks stay within the budget of the run,
+	// even if they grow a lot in the history file (e.g. escaped colors).
+	run = NewHistoryRun("/repo", "pre-commit", nil)
+	run.RecordOutput = true
+	for i := 0; i < 60; i++ {
+		run.AddResults(HookResult{Hook: &hook, Output: bytes.Repeat([]byte("\x1b"), historyMaxOutput)})
+	}
+
+	size := 0
+	for i := range run.Hooks {
+		size += len(run.Hooks[i].Output)
+	}
+	assert.Equal(t, historyMaxRunOutput, size)
+	require.NoError(t, AppendHistory(installDir, &run))
+
+	// Too long lines are skipped.
+	file, err := getHistoryFile(installDir, "/repo")
+	require.NoError(t, err)
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"` + strings.Repeat("x", historyMaxLine) + "\"}\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	last := NewHistoryRun("/repo", "pre-commit", nil)
+	require.NoError(t, AppendHistory(installDir, &last))
+
+	runs, err := LoadHistory(installDir, "/repo")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, run.ID, runs[0].ID)
+	assert.Equal(t, last.ID, runs[1].ID)
+}
+
+func TestHistoryConcurrentAppend(t *testing.T) {
+	installDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run := NewHistoryRun("/repo", "pre-commit", nil)
+			assert.NoError(t, AppendHistory(installDir, &run))
+		}()
+	}
+	wg.Wait()
+
+	runs, err := LoadHistory(installDir, "/repo")
+	require.NoError(t, err)
+	assert.Len(t, runs, 20)
+}
@@ -14,14 +14,14 @@ const (
 )
 
 // CheckHookNames checks hook names supporting also 'all', 'server' and negation prefix '!'.
-// Additionally sanitize the names.
+// Opt-in hook names are also accepted. Additionally sanitize the names.
 func CheckHookNames(hookNames []string) ([]string, error) {
 	hookNames = strs.Map(hookNames, strings.TrimSpace)
 
 	for _, h := range hookNames {
 		h = strings.TrimPrefix(h, "!")
 
-		if h != hookNameAll && h != hookNameServer && !strs.Includes(ManagedHookNames, h) {
+		if h != hookNameAll && h != hookNameServer && !strs.Includes(SupportedHookNames, h) {
 			return hookNames, cm.ErrorF(
 				"Given value '%s' in '%q' is not a hook name supported by Githooks\n"+
 					"nor its '%s' or '%s'. ", h, hookNames, hookNameAll, hookNameServer)
@@ -33,7 +33,8 @@ func CheckHookNames(hookNames []string) ([]string, error) {
 
 // UnwrapHookNames returns a unique list of hook names built from the input.
 // Variable `hookNames` can contain hook names, 'server', 'all'
-// and negation prefix '!'. This function always returns a non-nil list.
+// and negation prefix '!'. Opt-in hooks are only contained if given explicitly
+// and '!all' removes them too. This function always returns a non-nil list.
 func UnwrapHookNames(hookNames []string) ([]string, error) {
 	var err error
 
@@ -54,7 +55,7 @@ func UnwrapHookNames(hookNames []string) ([]string, error) {
 		switch h {
 		case hookNameAll:
 			if subtract {
-				for _, m := range ManagedHookNames {
+				for _, m := range SupportedHookNames {
 					s.Remove(m)
 				}
 			} else {
@@ -73,7 +74,7 @@ func UnwrapHookNames(hookNames []string) ([]string, error) {
 				}
 			}
 		default:
-			if !strs.Includes(ManagedHookNames, h) {
+			if !strs.Includes(SupportedHookNames, h) {
 				err = cm.CombineErrors(
 					err,
 					cm.ErrorF("Given value '%s' is not a supported hook name.", h),
@@ -109,7 +110,7 @@ func SetMaintainedHooks(
 		maintainedHooks = append(maintainedHooks, "all")
 	}
 
-	err = git.NewCtx().SetConfig(GitCKMaintainedHooks, strings.Join(maintainedHooks, ", "), scope)
+	err = gitx.SetConfig(GitCKMaintainedHooks, strings.Join(maintainedHooks, ", "), scope)
 
 	if err != nil {
 		err = cm.CombineErrors(err, cm.ErrorF("Could not set Git config '%s'.",
@@ -152,7 +153,7 @@ func getMaintainedHooksFromString(maintainedHooks string) (hookNamesUnwrapped []
 func GetMaintainedHooks(
 	gitx *git.Context,
 	scope git.ConfigScope) (hookNames []string, maintainedHooks []string, isSet bool, err error) {
-	h := git.NewCtx().GetConfig(GitCKMaintainedHooks, scope)
+	h := gitx.GetConfig(GitCKMaintainedHooks, scope)
 
 	hookNames, maintainedHooks, err = getMaintainedHooksFromString(h)
 	isSet = strs.IsNotEmpty(h)
@@ -160,13 +161,19 @@ func GetMaintainedHooks(
 	return
 }
 
-// Get all other hooks from `ManagedHookNames` which are not in `hookNames`.
+// Get all other hooks from `SupportedHookNames` which are not in `hookNames`.
 func GetAllOtherHooks(hookNames []string) (other []string) {
-	for i := range ManagedHookNames {
-		if !strs.Includes(hookNames, ManagedHookNames[i]) {
-			other = append(other, ManagedHookNames[i])
+	for i := range SupportedHookNames {
+		if !strs.Includes(hookNames, SupportedHookNames[i]) {
+			other = append(other, SupportedHookNames[i])
 		}
 	}
 
 	return
 }
+
+// IsServerHookName returns `true` if the hook `hookName` is managed for bare repositories.
+func IsServerHookName(hookName string) bool {
+	return strs.Includes(ManagedServerHookNames, hookName) ||
+		strs.Includes(OptInServerHookNames, hookName)
+}
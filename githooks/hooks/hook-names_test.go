@@ -30,6 +30,11 @@ func TestCheckHookNames(t *testing.T) {
 	h, _, err = getMaintainedHooksFromString("!all,\npre-commit,    post-commit")
 	assert.NoError(t, err)
 	assert.Len(t, h, 2)
+
+	h, _, err = getMaintainedHooksFromString("all, fsmonitor-watchman, p4-pre-submit")
+	assert.NoError(t, err)
+	assert.Len(t, h, len(ManagedHookNames)+2)
+	assert.Contains(t, h, "fsmonitor-watchman")
 }
 
 func TestHookNameUnwrap(t *testing.T) {
@@ -87,3 +92,36 @@ func TestHookNameUnwrap(t *testing.T) {
 	isSame(t, res.ToList(), h)
 	assert.Error(t, err)
 }
+
+func TestOptInHookNames(t *testing.T) {
+	// Opt-in hooks are only contained if given explicitly.
+	h, err := UnwrapHookNames([]string{"all", "server"})
+	assert.NoError(t, err)
+	isSame(t, ManagedHookNames, h)
+
+	h, err = UnwrapHookNames([]string{"proc-receive"})
+	assert.NoError(t, err)
+	isSame(t, append([]string{"proc-receive"}, ManagedHookNames...), h)
+
+	h, err = UnwrapHookNames([]string{"!all", "fsmonitor-watchman", "p4-changelist", "!p4-changelist"})
+	assert.NoError(t, err)
+	isSame(t, []string{"fsmonitor-watchman"}, h)
+
+	h, err = UnwrapHookNames([]string{"proc-receive", "!all"})
+	assert.NoError(t, err)
+	assert.Empty(t, h)
+
+	assert.Equal(t, OptInHookNames, GetAllOtherHooks(ManagedHookNames))
+
+	assert.True(t, IsServerHookName("proc-receive"))
+	assert.True(t, IsServerHookName("pre-receive"))
+	assert.False(t, IsServerHookName("fsmonitor-watchman"))
+}
+
+func TestCheckProtocolHooks(t *testing.T) {
+	assert.NoError(t, CheckProtocolHooks("fsmonitor-watchman", 0))
+	assert.NoError(t, CheckProtocolHooks("proc-receive", 1))
+	assert.NoError(t, CheckProtocolHooks("p4-pre-submit", 2))    //nolint:mnd
+	assert.Error(t, CheckProtocolHooks("fsmonitor-watchman", 2)) //nolint:mnd
+	assert.Error(t, CheckProtocolHooks("proc-receive", 3))       //nolint:mnd
+}
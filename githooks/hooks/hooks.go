@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	cm "github.com/gabyx/githooks/githooks/common"
 	"github.com/gabyx/githooks/githooks/container"
@@ -44,6 +45,71 @@ type Hook struct {
 
 	// BatchName denotes the parallel batch
 	BatchName string
+
+	// The condition from the run configuration when the hook runs (if any).
+	When *Condition `json:"-"`
+
+	// The entry name if the hook is declared in the hooks manifest `Path`.
+	Entry string
+
+	// Glob patterns: the hook only runs if any staged file matches (if any).
+	Files []string
+
+	// The command of the interpreter running the hook file (if any).
+	Interpreter string
+	// The interpreter or environment override from the repository
+	// which is part of the SHA1 hash of the hook (if any).
+	Override string `json:"-"`
+
+	// The managed tool environment of the hook (if any).
+	Environment *HookEnvironment
+
+	// The built-in hook which runs in-process instead of the executable (if any).
+	Builtin *BuiltinHook `json:"-"`
+}
+
+// GetRunImage gets the container image reference of the hook
+// or empty if the hook is not configured to run containerized.
+func (h *Hook) GetRunImage(hookName string) (string, error) {
+	if h.Builtin != nil {
+		return "", nil
+	} else if strs.IsEmpty(h.Entry) {
+		return GetHookRunImage(h.Path, h.Namespace)
+	}
+
+	entry, err := getManifestEntry(h.Path, hookName, h.Entry)
+	if err != nil || strs.IsEmpty(entry.Image.Reference) {
+		return "", err
+	}
+
+	return addImageReferenceSuffix(entry.Image.Reference, h.Path, h.Namespace)
+}
+
+// MatchesFiles returns `true` if the hook has no file patterns or
+// any of the repository relative paths `files` matches one of them.
+// Patterns without a `/` are matched against the base name.
+func (h *Hook) MatchesFiles(files []string) (bool, error) {
+	if len(h.Files) == 0 {
+		return true, nil
+	}
+
+	for _, file := range files {
+		for _, pattern := range h.Files {
+			p := file
+			if !strings.Contains(pattern, "/") {
+				p = path.Base(file)
+			}
+
+			matched, err := cm.GlobMatch(pattern, p)
+			if err != nil {
+				return false, cm.CombineErrors(err, cm.ErrorF("Invalid file pattern '%s'.", pattern))
+			} else if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
 }
 
 // HookPrioList is a list of lists of executable hooks.
@@ -71,6 +137,7 @@ type HookResult struct {
 	Output   []byte
 	Error    error
 	ExitCode int
+	Duration time.Duration // The wall time of the execution.
 }
 
 // TaggedHooksIndex is the index type for hook tags.
@@ -137,7 +204,8 @@ func GetHookTagNameMappings() []string {
 type IgnoreCallback = func(namespacePath string) (ignored bool)
 
 // TrustCallback is the callback type for trusting hooks.
-type TrustCallback = func(hookPath string) (trusted bool, sha1 string)
+// The `override` is part of the checksum of the hook (see `GetHookSHA1`).
+type TrustCallback = func(hookPath string, override string) (trusted bool, sha1 string)
 
 // GetAllHooksIn gets all hooks with name `hookName`
 // in hooks dir `hookDir`.
@@ -174,13 +242,12 @@ func GetAllHooksIn(
 
 		trusted := false
 		sha := ""
-		var runCmd cm.IExecutable
+		var run hookRun
 
 		if !ignored || !lazyIfIgnored {
-			trusted, sha = isTrusted(hookPath)
-
-			runCmd, err = GetHookRunCmd(
+			run, err = getHookRunCmd(
 				gitx,
+				hookName,
 				hookPath,
 				rootDir,
 				hooksDir,
@@ -194,11 +261,13 @@ func GetAllHooksIn(
 				return cm.CombineErrors(err,
 					cm.ErrorF("Could not detect runner for hook\n'%s'", hookPath))
 			}
+
+			trusted, sha = isTrusted(hookPath, run.override)
 		}
 
 		allHooks = append(allHooks,
 			Hook{
-				IExecutable:   runCmd,
+				IExecutable:   run.exec,
 				Path:          hookPath,
 				Namespace:     hookNamespace,
 				NamespacePath: namespacedPath,
@@ -206,11 +275,28 @@ func GetAllHooksIn(
 				Active:        !ignored,
 				Trusted:       trusted,
 				SHA1:          sha,
-				BatchName:     batchName})
+				BatchName:     batchName,
+				When:          run.when,
+				Interpreter:   run.interpreter,
+				Override:      run.override,
+				Environment:   run.environment})
 
 		return nil
 	}
 
+	if parseRunnerConfig {
+		// Collect all hooks declared in `path/hooks.yaml`.
+		manifestHooks, batches, e := getManifestHooks(
+			gitx, rootDir, hooksDir, hookName, hookNamespace, namespaceEnvs,
+			isIgnored, isTrusted, lazyIfIgnored, containerMgr)
+		if e != nil {
+			return allHooks, maxBatches, e
+		}
+
+		allHooks = append(allHooks, manifestHooks...)
+		maxBatches += batches
+	}
+
 	dirOrFile := path.Join(hooksDir, hookName)
 
 	switch {
@@ -303,13 +389,25 @@ func GetAllHooksIn(
 }
 
 // ExecuteHooksParallel executes hooks in parallel over a thread pool.
+// The standard input of each hook is set up by `stdin` (can be `nil` for
+// the standard input of this process).
+// The output of each hook is written live to the writers of `output` (if not `nil`)
+// and is always reported in the results.
+// The running hooks are shown in `progress` (if not `nil`) until their results are reported.
 func ExecuteHooksParallel(
 	pool *thx.ThreadPool,
 	exec cm.IExecContext,
 	hs HookPrioList,
 	res []HookResult,
+	stdin cm.PipeSetupFunc,
+	output HookOutputFunc,
+	progress *cm.ProgressStatus,
 	outputCallback func(res ...HookResult),
 	args ...string) ([]HookResult, error) {
+	if stdin == nil {
+		stdin = cm.UseOnlyStdin(os.Stdin)
+	}
+
 	// Count number of results we need
 	nResults := 0
 	for _, hooksGroup := range hs {
@@ -324,13 +422,38 @@ func ExecuteHooksParallel(
 	}
 
 	call := func(hookRes *HookResult, hook *Hook) {
+		pipeSetup := stdin
+
+		if output != nil {
+			outWriter, errWriter := output(hook)
+			defer func() {
+				_ = outWriter.Close()
+				_ = errWriter.Close()
+			}()
+
+			pipeSetup = func() (io.Reader, io.Writer, io.Writer) {
+				in, _, _ := stdin()
+
+				return in, outWriter, errWriter
+			}
+		}
+
 		hookRes.Hook = hook
-		hookRes.Output, hookRes.ExitCode, hookRes.Error =
-			cm.GetCombinedOutputFromExecutable(
-				exec,
-				hook,
-				cm.UseOnlyStdin(os.Stdin),
-				args...)
+		id := progress.Start(hook.NamespacePath)
+		start := time.Now()
+		if hook.Builtin != nil {
+			hookRes.Output, hookRes.ExitCode, hookRes.Error =
+				hook.Builtin.Execute(exec, pipeSetup, args...)
+		} else {
+			hookRes.Output, hookRes.ExitCode, hookRes.Error =
+				cm.GetCombinedOutputFromExecutable(
+					exec,
+					hook,
+					pipeSetup,
+					args...)
+		}
+		hookRes.Duration = time.Since(start)
+		progress.Finish(id, hookRes.Error != nil, hookRes.Duration)
 	}
 
 	currIdx := 0
@@ -346,6 +469,7 @@ func ExecuteHooksParallel(
 				hookRes := &res[currIdx+idx]
 				hook := &hooksGroup[idx]
 				call(hookRes, hook)
+				progress.Done()
 				outputCallback(*hookRes)
 			}
 		} else {
@@ -364,7 +488,10 @@ func ExecuteHooksParallel(
 				return nil, err
 			}
 
-			if err = pool.Wait(g); err != nil {
+			err = pool.Wait(g)
+			progress.Done()
+
+			if err != nil {
 				return nil, err
 			}
 
@@ -450,7 +577,7 @@ func AllHooksSuccessful(results []HookResult) bool {
 // AssertSHA1 ensures that the hook has its SHA1 computed.
 func (h *Hook) AssertSHA1() (err error) {
 	if strs.IsEmpty(h.SHA1) {
-		h.SHA1, err = cm.GetSHA1HashFile(h.Path)
+		h.SHA1, err = GetHookSHA1(h.Path, h.Override)
 	}
 
 	return
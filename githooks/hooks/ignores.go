@@ -1,6 +1,7 @@
 package hooks
 
 import (
+	"os"
 	"path"
 	"strings"
 
@@ -124,6 +125,39 @@ func (h *HookPatterns) Remove(p *HookPatterns) (removed int) {
 	return
 }
 
+// AddInversionsUnique adds the inverted entries `!<entry>` of all entries in `p`
+// which override the same entries of patterns combined before these.
+func (h *HookPatterns) AddInversionsUnique(p *HookPatterns) (added int) {
+	inv := p.getInversions()
+
+	return h.AddUnique(&inv)
+}
+
+// RemoveInversions removes the inverted entries `!<entry>` of all entries in `p`.
+func (h *HookPatterns) RemoveInversions(p *HookPatterns) (removed int) {
+	inv := p.getInversions()
+
+	return h.Remove(&inv)
+}
+
+// getInversions gets the inverted entries `!<entry>` of all non-inverted entries.
+func (h *HookPatterns) getInversions() (inv HookPatterns) {
+	invert := func(entries []string) (res []string) {
+		for _, e := range entries {
+			if !hasInvertPrefix(e) {
+				res = append(res, patternInversionPrefix+e)
+			}
+		}
+
+		return
+	}
+
+	inv.Patterns = invert(h.Patterns)
+	inv.NamespacePaths = invert(h.NamespacePaths)
+
+	return
+}
+
 // RemoveAll removes all patterns.
 func (h *HookPatterns) RemoveAll() (removed int) {
 	removed = len(h.Patterns) + len(h.NamespacePaths)
@@ -228,9 +262,15 @@ func (h *HookPatterns) Matches(namespacePath string) (matched bool) {
 		}
 	}
 
-	// The full matches can only change the result to `true`
-	// They have no inversion "!" prefix.
-	matched = matched || strs.Includes(h.NamespacePaths, namespacePath)
+	// The full matches are applied after all patterns in order.
+	// An inversion "!" prefix reverts a previous full match or pattern match
+	// (e.g. a worktree overriding a shared ignore).
+	for _, p := range h.NamespacePaths {
+		startIdx, inverted := checkPatternInversion(p)
+		if p[startIdx:] == namespacePath {
+			matched = !inverted
+		}
+	}
 
 	return
 }
@@ -306,8 +346,8 @@ func GetHookIgnoreFileGitDir(gitDir string) string {
 	return path.Join(gitDir, ".githooks.ignore.yaml")
 }
 
-// getHookPatternsGitDir gets all ignored hooks in the current Git directory.
-func getHookPatternsGitDir(gitDir string, hookeNamespace string) (ps HookPatterns, err error) {
+// GetHookPatternsGitDir gets all ignored hooks in the Git directory `gitDir`.
+func GetHookPatternsGitDir(gitDir string, hookeNamespace string) (ps HookPatterns, err error) {
 	file := GetHookIgnoreFileGitDir(gitDir)
 
 	if cm.IsFile(file) {
@@ -318,10 +358,14 @@ func getHookPatternsGitDir(gitDir string, hookeNamespace string) (ps HookPattern
 	return
 }
 
-// StoreHookPatternsGitDir stores all ignored hooks in the worktrees Git directory `gitDirWorktree`.
-func StoreHookPatternsGitDir(patterns HookPatterns, gitDirWorktree string) error {
-	return StoreIgnorePatterns(patterns,
-		path.Join(gitDirWorktree, ".githooks.ignore.yaml"))
+// StoreHookPatternsGitDir stores all ignored hooks in the Git directory `gitDir`.
+func StoreHookPatternsGitDir(patterns HookPatterns, gitDir string) error {
+	// The Git directory might be the not yet existing shared state directory.
+	if err := os.MkdirAll(gitDir, cm.DefaultFileModeDirectory); err != nil {
+		return err
+	}
+
+	return StoreIgnorePatterns(patterns, GetHookIgnoreFileGitDir(gitDir))
 }
 
 // LoadIgnorePatterns loads patterns.
@@ -384,10 +428,12 @@ func StoreIgnorePatterns(patterns HookPatterns, file string) (err error) {
 }
 
 // GetIgnorePatterns loads all ignore patterns in the worktree's hooks dir and
-// also in the worktrees Git directory.
+// also the user patterns in the Git directories `userGitDirs`.
+// The user patterns are combined in the order of `userGitDirs` such that
+// later directories can override earlier ones with inverted patterns `!...`.
 func GetIgnorePatterns(
 	hooksDir string,
-	gitDirWorktree string,
+	userGitDirs []string,
 	hookNames []string,
 	hookNamespace string) (patt RepoIgnorePatterns, err error) {
 	var e error
@@ -397,9 +443,13 @@ func GetIgnorePatterns(
 		err = cm.CombineErrors(cm.Error("Could not get worktree ignore patterns."), e)
 	}
 
-	patt.User, e = getHookPatternsGitDir(gitDirWorktree, hookNamespace)
-	if e != nil {
-		err = cm.CombineErrors(err, cm.Error("Could not get user ignore patterns."), e)
+	for _, gitDir := range userGitDirs {
+		ps, e := GetHookPatternsGitDir(gitDir, hookNamespace)
+		if e != nil {
+			err = cm.CombineErrors(err, cm.Error("Could not get user ignore patterns."), e)
+		}
+
+		patt.User.Add(&ps)
 	}
 
 	return
@@ -0,0 +1,254 @@
+package hooks
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// Interpreter is a command which runs hook files which are not executable.
+// The hook file is passed after `Args`.
+type Interpreter struct {
+	Cmd  string   `yaml:"cmd"`
+	Args []string `yaml:"args"`
+}
+
+// Interpreters maps a file extension (e.g. `.py`) or
+// the interpreter name of a shebang (e.g. `python3`) to an interpreter.
+type Interpreters map[string]Interpreter
+
+// GetDefaultInterpreters gets the builtin interpreters.
+func GetDefaultInterpreters() Interpreters {
+	return Interpreters{
+		// File extensions.
+		".py":  {Cmd: "python3"},
+		".js":  {Cmd: "node"},
+		".ps1": {Cmd: "pwsh", Args: []string{"-NoProfile", "-File"}},
+		".go":  {Cmd: "go", Args: []string{"run"}},
+
+		// Shebang interpreters.
+		"python":  {Cmd: "python3"},
+		"python3": {Cmd: "python3"},
+		"node":    {Cmd: "node"},
+		"pwsh":    {Cmd: "pwsh", Args: []string{"-NoProfile", "-File"}},
+		"bash":    {Cmd: "bash"},
+	}
+}
+
+// The `.runners.yaml` config which overrides the interpreters
+// for the hooks in a hooks directory.
+type interpretersConfig struct {
+	Runners Interpreters `yaml:"runners"`
+	// The version of the file.
+	Version int `yaml:"version"`
+}
+
+// Version for interpretersConfig.
+// Version 1: Initial.
+const interpretersConfigVersion int = 1
+
+func createInterpretersConfig() interpretersConfig {
+	return interpretersConfig{Version: interpretersConfigVersion}
+}
+
+// GetInterpretersFile gets the interpreters config file in the hooks directory `hooksDir`.
+func GetInterpretersFile(hooksDir string) string {
+	return path.Join(hooksDir, ".runners.yaml")
+}
+
+// LoadInterpreters loads the interpreters for hooks in `hooksDir`.
+// The builtin interpreters are overridden by the `.runners.yaml` file in `hooksDir`
+// which is overridden by the Git config `githooks.interpreters`.
+func LoadInterpreters(gitx *git.Context, hooksDir string) (Interpreters, error) {
+	interps, _, err := loadInterpreters(gitx, hooksDir)
+
+	return interps, err
+}
+
+// loadInterpreters loads the interpreters for hooks in `hooksDir` and
+// reports the keys which are set by the `.runners.yaml` file (and not by the Git config).
+func loadInterpreters(gitx *git.Context, hooksDir string) (interps Interpreters, fileKeys strs.StringSet, err error) {
+	interps = GetDefaultInterpreters()
+	fileKeys = strs.NewStringSet(0)
+
+	if strs.IsNotEmpty(hooksDir) {
+		file := GetInterpretersFile(hooksDir)
+
+		if cm.IsFile(file) {
+			config := createInterpretersConfig()
+
+			err = cm.LoadYAML(file, &config)
+			if err != nil {
+				return nil, fileKeys, cm.CombineErrors(err, cm.ErrorF("Could not load file '%s'", file))
+			}
+
+			if config.Version == 0 || config.Version > interpretersConfigVersion {
+				return nil, fileKeys, cm.ErrorF(
+					"File '%s' has version '%v'. "+
+						"This version of Githooks only supports version >= 1 and <= '%v'.",
+					file, config.Version, interpretersConfigVersion)
+			}
+
+			for key, interp := range config.Runners {
+				interps.set(key, interp)
+				fileKeys.Insert(key)
+			}
+		}
+	}
+
+	if gitx != nil {
+		for _, value := range gitx.GetConfigAll(GitCKInterpreters, git.Traverse) {
+			key, interp, e := parseInterpreter(value)
+			if e != nil {
+				return nil, fileKeys, cm.CombineErrors(e,
+					cm.ErrorF("Invalid Git config '%s'.", GitCKInterpreters))
+			}
+
+			interps.set(key, interp)
+			fileKeys.Remove(key)
+		}
+	}
+
+	return interps, fileKeys, nil
+}
+
+// set sets the interpreter for `key` or removes it if its command is empty.
+func (i Interpreters) set(key string, interp Interpreter) {
+	if strs.IsEmpty(interp.Cmd) {
+		delete(i, key)
+	} else {
+		i[key] = interp
+	}
+}
+
+// parseInterpreter parses an interpreter `<key>=<cmd> [args...]`.
+func parseInterpreter(value string) (key string, interp Interpreter, err error) {
+	key, cmd, found := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+
+	if !found || strs.IsEmpty(key) {
+		err = cm.ErrorF("Interpreter '%s' must be of the form '<key>=<cmd> [args...]'.", value)
+
+		return
+	}
+
+	if fields := strings.Fields(cmd); len(fields) != 0 {
+		interp.Cmd = fields[0]
+		interp.Args = fields[1:]
+	}
+
+	return
+}
+
+// Resolve gets the interpreter and its key for the hook `hookPath`.
+// The interpreter of the shebang has priority over the one of the file extension.
+func (i Interpreters) Resolve(hookPath string) (interp Interpreter, key string, found bool) {
+	for _, key = range getInterpreterKeys(hookPath) {
+		if interp, found = i[key]; found {
+			return
+		}
+	}
+
+	return
+}
+
+// getInterpreterKeys gets the keys by which an interpreter for
+// the hook `hookPath` is resolved in order of priority.
+func getInterpreterKeys(hookPath string) (keys []string) {
+	if key := getShebangInterpreter(hookPath); strs.IsNotEmpty(key) {
+		keys = append(keys, key)
+	}
+
+	if key := path.Ext(hookPath); strs.IsNotEmpty(key) {
+		keys = append(keys, key)
+	}
+
+	return
+}
+
+// getShebangInterpreter gets the interpreter name of the shebang
+// in the file `hookPath` (if any), e.g. `python3` for
+// `#!/usr/bin/python3` or `#!/usr/bin/env python3`.
+func getShebangInterpreter(hookPath string) string {
+	file, err := os.Open(hookPath)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	const maxShebangLength = 256
+	line, err := bufio.NewReaderSize(file, maxShebangLength).ReadSlice('\n')
+	if err != nil && len(line) == 0 {
+		return ""
+	}
+
+	shebang, found := strings.CutPrefix(string(line), "#!")
+	if !found {
+		return ""
+	}
+
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	name := path.Base(fields[0])
+	if name != "env" {
+		return name
+	}
+
+	// Skip the options of `env`, e.g. `-S`.
+	for _, f := range fields[1:] {
+		if !strings.HasPrefix(f, "-") {
+			return path.Base(f)
+		}
+	}
+
+	return ""
+}
+
+// getInterpreterRunCmd gets the executable which runs the hook `hookPath`
+// by its interpreter in `hooksDir` or by the default runner
+// and the command of the interpreter.
+// If the `.runners.yaml` file in `hooksDir` decides the interpreter,
+// the resolved command is returned as `override` which must be part of
+// the trusted checksum of the hook (see `GetHookSHA1`) since the file is
+// content of the repository.
+func getInterpreterRunCmd(
+	gitx *git.Context,
+	hookPath string,
+	hooksDir string,
+	envs []string) (exec cm.IExecutable, interpreter string, override string, err error) {
+	interps, fileKeys, err := loadInterpreters(gitx, hooksDir)
+	if err != nil {
+		return
+	}
+
+	interp, _, found := interps.Resolve(hookPath)
+
+	var cmd []string
+	if found {
+		cmd = append([]string{interp.Cmd}, interp.Args...)
+		args := append(cm.CopySlice(interp.Args), hookPath)
+		e := cm.NewExecutable(interp.Cmd, args, envs)
+		exec, interpreter = &e, interp.Cmd
+	} else {
+		exec = GetDefaultRunner(hookPath, envs)
+		interpreter = exec.GetCommand()
+	}
+
+	for _, key := range getInterpreterKeys(hookPath) {
+		if fileKeys.Exists(key) {
+			override = strs.Fmt("runner: %q", cmd)
+
+			break
+		}
+	}
+
+	return
+}
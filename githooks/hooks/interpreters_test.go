@@ -0,0 +1,148 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpreterResolve(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		file := path.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600)) //nolint:mnd
+
+		return file
+	}
+
+	interps := GetDefaultInterpreters()
+
+	i, key, found := interps.Resolve(write("a.py", "print('a')\n"))
+	assert.True(t, found)
+	assert.Equal(t, ".py", key)
+	assert.Equal(t, "python3", i.Cmd)
+
+	i, key, found = interps.Resolve(write("b", "#!/usr/bin/env -S node --no-warnings\n"))
+	assert.True(t, found)
+	assert.Equal(t, "node", key)
+	assert.Equal(t, "node", i.Cmd)
+
+	// The shebang has priority over the extension.
+	i, key, found = interps.Resolve(write("c.js", "#!/bin/bash\necho c"))
+	assert.True(t, found)
+	assert.Equal(t, "bash", key)
+	assert.Equal(t, "bash", i.Cmd)
+
+	// Unknown shebangs fall back to the extension.
+	i, _, found = interps.Resolve(write("d.ps1", "#!/opt/custom\n"))
+	assert.True(t, found)
+	assert.Equal(t, Interpreter{Cmd: "pwsh", Args: []string{"-NoProfile", "-File"}}, i)
+
+	_, _, found = interps.Resolve(write("e.sh", "#!/bin/sh\necho e"))
+	assert.False(t, found)
+}
+
+func TestLoadInterpreters(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	hooksDir := path.Join(repo, ".githooks")
+	require.NoError(t, os.MkdirAll(hooksDir, 0700)) //nolint:mnd
+	require.NoError(t, os.WriteFile(GetInterpretersFile(hooksDir), []byte(
+		"version: 1\n"+
+			"runners:\n"+
+			"  .py: {cmd: python3.12, args: [-u]}\n"+
+			"  .rb: {cmd: ruby}\n"+
+			"  .js: {cmd: ''}\n"), 0600)) //nolint:mnd
+
+	interps, err := LoadInterpreters(gitx, hooksDir)
+	require.NoError(t, err)
+	assert.Equal(t, Interpreter{Cmd: "python3.12", Args: []string{"-u"}}, interps[".py"])
+	assert.Equal(t, Interpreter{Cmd: "ruby"}, interps[".rb"])
+	assert.NotContains(t, interps, ".js")
+	assert.Equal(t, "go", interps[".go"].Cmd)
+
+	// The Git config overrides the file.
+	require.NoError(t, gitx.AddConfig(GitCKInterpreters, ".py=/opt/python -X utf8", git.LocalScope))
+	require.NoError(t, gitx.AddConfig(GitCKInterpreters, ".go=", git.LocalScope))
+
+	interps, err = LoadInterpreters(gitx, hooksDir)
+	require.NoError(t, err)
+	assert.Equal(t, Interpreter{Cmd: "/opt/python", Args: []string{"-X", "utf8"}}, interps[".py"])
+	assert.NotContains(t, interps, ".go")
+
+	require.NoError(t, gitx.AddConfig(GitCKInterpreters, "python3", git.LocalScope))
+	_, err = LoadInterpreters(gitx, hooksDir)
+	assert.Error(t, err)
+}
+
+func TestGetHookRunCmdInterpreter(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	hook := path.Join(repo, "check.py")
+	require.NoError(t, os.WriteFile(hook, []byte("print('ok')\n"), 0600)) //nolint:mnd
+
+	run, err := getHookRunCmd(
+		gitx, "pre-commit", hook, repo, repo, true, nil, "", []string{"A=1"})
+	require.NoError(t, err)
+	assert.Equal(t, "python3", run.interpreter)
+	assert.Equal(t, "python3", run.exec.GetCommand())
+	assert.Equal(t, []string{hook, "arg"}, run.exec.GetArgs("arg"))
+	assert.Equal(t, []string{"A=1"}, run.exec.GetEnvironment())
+}
+
+func TestInterpreterOverrideTrust(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	hooksDir := path.Join(repo, ".githooks")
+	hook := path.Join(hooksDir, "check.py")
+	require.NoError(t, os.MkdirAll(hooksDir, 0700))                       //nolint:mnd
+	require.NoError(t, os.WriteFile(hook, []byte("print('ok')\n"), 0600)) //nolint:mnd
+
+	run, err := getHookRunCmd(gitx, "pre-commit", hook, repo, hooksDir, true, nil, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, run.override)
+
+	sha, err := GetHookSHA1(hook, run.override)
+	require.NoError(t, err)
+	fileSHA, err := cm.GetSHA1HashFile(hook)
+	require.NoError(t, err)
+	assert.Equal(t, fileSHA, sha, "Hooks without override keep the file checksum.")
+
+	// The repository changes the interpreter: the checksum must change.
+	writeRunners := func(content string) {
+		require.NoError(t, os.WriteFile(GetInterpretersFile(hooksDir),
+			[]byte("version: 1\nrunners:\n"+content), 0600)) //nolint:mnd
+	}
+
+	writeRunners("  .py: {cmd: bash}\n")
+	run, err = getHookRunCmd(gitx, "pre-commit", hook, repo, hooksDir, true, nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bash", run.interpreter)
+	assert.NotEmpty(t, run.override)
+
+	shaOverride, err := GetHookSHA1(hook, run.override)
+	require.NoError(t, err)
+	assert.NotEqual(t, fileSHA, shaOverride)
+
+	// Disabling the interpreter in the file changes the checksum too.
+	writeRunners("  .py: {cmd: ''}\n")
+	run, err = getHookRunCmd(gitx, "pre-commit", hook, repo, hooksDir, true, nil, "", nil)
+	require.NoError(t, err)
+	shaDisabled, err := GetHookSHA1(hook, run.override)
+	require.NoError(t, err)
+	assert.NotEqual(t, fileSHA, shaDisabled)
+	assert.NotEqual(t, shaOverride, shaDisabled)
+
+	// The Git config of the user has priority and needs no trust.
+	require.NoError(t, gitx.AddConfig(GitCKInterpreters, ".py=python3", git.LocalScope))
+	run, err = getHookRunCmd(gitx, "pre-commit", hook, repo, hooksDir, true, nil, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "python3", run.interpreter)
+	assert.Empty(t, run.override)
+}
@@ -0,0 +1,227 @@
+package hooks
+
+import (
+	"path"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/container"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// HooksManifestFileName is the file name of the hooks manifest
+// inside a hooks directory which declares hooks inline.
+const HooksManifestFileName = "hooks.yaml"
+
+// hooksManifestEntrySeparator separates the manifest file from the
+// entry `<hookName>/<entryName>` in namespace paths,
+// e.g. `ns:gh-self/hooks.yaml#pre-commit/lint`.
+const hooksManifestEntrySeparator = "#"
+
+// The data of a hook entry in the hooks manifest.
+type hooksManifestEntry struct {
+	Name  string         `yaml:"name"`
+	Cmd   string         `yaml:"cmd"`
+	Args  []string       `yaml:"args"`
+	Env   []string       `yaml:"env"`
+	Image imageRunConfig `yaml:"image"`
+	When  string         `yaml:"when"`
+
+	// The batch name of the hook, hooks with the same
+	// batch name run in parallel. Defaults to `Name`.
+	Batch string `yaml:"batch"`
+
+	// Glob patterns: the hook only runs if any staged file matches.
+	Files []string `yaml:"files"`
+}
+
+// The data for the hooks manifest file.
+type hooksManifestFile struct {
+	Hooks map[string][]hooksManifestEntry `yaml:"hooks"`
+
+	Version int `yaml:"version"`
+}
+
+// The current hooks manifest file version.
+// Version 1: Initial file.
+var hooksManifestFileVersion int = 1
+
+// GetHooksManifestFile gets the hooks manifest file in hooks directory `hooksDir`.
+func GetHooksManifestFile(hooksDir string) string {
+	return path.Join(hooksDir, HooksManifestFileName)
+}
+
+func createHooksManifest() hooksManifestFile {
+	return hooksManifestFile{Version: hooksManifestFileVersion}
+}
+
+// loadHooksManifest loads and validates the hooks manifest `file`.
+func loadHooksManifest(file string) (data hooksManifestFile, err error) {
+	data = createHooksManifest()
+	err = cm.LoadYAML(file, &data)
+	if err != nil {
+		return
+	}
+
+	if data.Version < 1 || data.Version > hooksManifestFileVersion {
+		err = cm.ErrorF(
+			"File '%s' has version '%v'. "+
+				"This version of Githooks only supports version >= 1 and <= '%v'.",
+			file,
+			data.Version,
+			hooksManifestFileVersion)
+
+		return
+	}
+
+	for hookName, entries := range data.Hooks {
+		if !strs.Includes(SupportedHookNames, hookName) {
+			err = cm.CombineErrors(err,
+				cm.ErrorF("Hook name '%s' in '%s' is not supported.", hookName, file))
+
+			continue
+		}
+
+		if e := CheckProtocolHooks(hookName, len(entries)); e != nil {
+			err = cm.CombineErrors(err, e,
+				cm.ErrorF("Hook '%s' in '%s' has too many entries.", hookName, file))
+		}
+
+		names := strs.NewStringSet(len(entries))
+
+		for i := range entries {
+			entry := &entries[i]
+
+			switch {
+			case strs.IsEmpty(entry.Name) || strings.ContainsAny(entry.Name, "/\\#"):
+				err = cm.CombineErrors(err,
+					cm.ErrorF("Hook '%s' entry '%v' in '%s' needs a name "+
+						"without '/', '\\' or '#'.", hookName, i, file))
+			case names.Exists(entry.Name):
+				err = cm.CombineErrors(err,
+					cm.ErrorF("Hook '%s' entry '%s' in '%s' is defined twice.",
+						hookName, entry.Name, file))
+			case strs.IsEmpty(entry.Cmd):
+				err = cm.CombineErrors(err,
+					cm.ErrorF("Hook '%s' entry '%s' in '%s' has no 'cmd'.",
+						hookName, entry.Name, file))
+			}
+
+			names.Insert(entry.Name)
+		}
+	}
+
+	return
+}
+
+// getManifestEntry gets the entry `entryName` for hook `hookName` in the hooks manifest `file`.
+func getManifestEntry(file string, hookName string, entryName string) (*hooksManifestEntry, error) {
+	manifest, err := loadHooksManifest(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range manifest.Hooks[hookName] {
+		if entry.Name == entryName {
+			return &entry, nil
+		}
+	}
+
+	return nil, cm.ErrorF("Hook '%s' entry '%s' does not exist in '%s'.", hookName, entryName, file)
+}
+
+// getManifestNamespacePath gets the namespace path of the entry `entryName`
+// for hook `hookName` in a hooks manifest, e.g. `ns:gh-self/hooks.yaml#pre-commit/lint`.
+func getManifestNamespacePath(hookNamespace string, hookName string, entryName string) string {
+	p := HooksManifestFileName + hooksManifestEntrySeparator + hookName + "/" + entryName
+
+	if strs.IsNotEmpty(hookNamespace) {
+		return path.Join(NamespacePrefix+hookNamespace, p)
+	}
+
+	return p
+}
+
+// getManifestHooks gets all hooks with name `hookName` declared in the
+// hooks manifest in hooks dir `hooksDir`.
+// The reported `batches` is the number of distinct batches.
+func getManifestHooks(
+	gitx *git.Context,
+	rootDir string,
+	hooksDir string,
+	hookName string,
+	hookNamespace string,
+	namespaceEnvs []string,
+	isIgnored IgnoreCallback,
+	isTrusted TrustCallback,
+	lazyIfIgnored bool,
+	containerMgr container.IManager) (hooks []Hook, batches int, err error) {
+	file := GetHooksManifestFile(hooksDir)
+	if !cm.IsFile(file) {
+		return
+	}
+
+	manifest, err := loadHooksManifest(file)
+	if err != nil {
+		return nil, 0, cm.CombineErrors(err, cm.ErrorF("Could not load hooks manifest '%s'.", file))
+	}
+
+	entries := manifest.Hooks[hookName]
+	batchNames := strs.NewStringSet(len(entries))
+
+	for i := range entries {
+		entry := &entries[i]
+
+		batchName := entry.Batch
+		if strs.IsEmpty(batchName) {
+			batchName = entry.Name
+		}
+		batchNames.Insert(batchName)
+
+		namespacedPath := getManifestNamespacePath(hookNamespace, hookName, entry.Name)
+		ignored := isIgnored(namespacedPath)
+
+		trusted := false
+		sha := ""
+		var run hookRun
+
+		if !ignored || !lazyIfIgnored {
+			// The whole manifest is trusted by its checksum.
+			trusted, sha = isTrusted(file, "")
+
+			config := runnerConfigFile{
+				Cmd:   entry.Cmd,
+				Args:  entry.Args,
+				Env:   entry.Env,
+				Image: entry.Image,
+				When:  entry.When}
+
+			run, err = getRunConfigCmd(
+				gitx, hookName, file, rootDir, &config,
+				containerMgr, hookNamespace, namespaceEnvs)
+
+			if err != nil {
+				return nil, 0, cm.CombineErrors(err,
+					cm.ErrorF("Could not detect runner for hook\n'%s'", namespacedPath))
+			}
+		}
+
+		hooks = append(hooks,
+			Hook{
+				IExecutable:   run.exec,
+				Path:          file,
+				Namespace:     hookNamespace,
+				NamespacePath: namespacedPath,
+				NamespaceEnvs: namespaceEnvs,
+				Active:        !ignored,
+				Trusted:       trusted,
+				SHA1:          sha,
+				BatchName:     batchName,
+				When:          run.when,
+				Entry:         entry.Name,
+				Files:         entry.Files})
+	}
+
+	return hooks, len(batchNames), nil
+}
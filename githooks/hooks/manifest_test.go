@@ -0,0 +1,122 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksManifest(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	hooksDir := GetGithooksDir(repo)
+	require.NoError(t, os.MkdirAll(path.Join(hooksDir, "pre-commit"), 0775)) //nolint:mnd
+	require.NoError(t, os.WriteFile(
+		path.Join(hooksDir, "pre-commit", "format.yaml"),
+		[]byte("cmd: format.sh"), 0600)) //nolint:mnd
+
+	manifest := `
+version: 1
+hooks:
+  pre-commit:
+    - name: lint
+      cmd: tools/lint.sh
+      args: ["--hook", "${hook:name}"]
+      batch: check
+      files: ["*.go"]
+    - name: spell
+      cmd: spell
+      batch: check
+      when: "branch == 'main'"
+  commit-msg:
+    - name: msg
+      cmd: check-msg
+`
+	require.NoError(t, os.WriteFile(GetHooksManifestFile(hooksDir), []byte(manifest), 0600)) //nolint:mnd
+
+	isIgnored := func(p string) bool { return p == "ns:gh-self/hooks.yaml#pre-commit/spell" }
+	isTrusted := func(string, string) (bool, string) { return true, "" }
+
+	hs, maxBatches, err := GetAllHooksIn(gitx, repo, hooksDir, "pre-commit", "gh-self", nil,
+		isIgnored, isTrusted, false, true, nil)
+	require.NoError(t, err)
+	require.Len(t, hs, 3)
+	assert.Equal(t, 2, maxBatches)
+
+	lint := &hs[0]
+	assert.Equal(t, "ns:gh-self/hooks.yaml#pre-commit/lint", lint.NamespacePath)
+	assert.Equal(t, GetHooksManifestFile(hooksDir), lint.Path)
+	assert.Equal(t, "lint", lint.Entry)
+	assert.Equal(t, "check", lint.BatchName)
+	assert.Equal(t, path.Join(repo, "tools/lint.sh"), lint.GetCommand())
+	assert.Equal(t, []string{"--hook", "pre-commit"}, lint.GetArgs())
+	assert.True(t, lint.Active)
+
+	spell := &hs[1]
+	assert.Equal(t, "ns:gh-self/hooks.yaml#pre-commit/spell", spell.NamespacePath)
+	assert.False(t, spell.Active)
+	require.NotNil(t, spell.When)
+	assert.Equal(t, "branch == 'main'", spell.When.Expr)
+
+	assert.Equal(t, "ns:gh-self/pre-commit/format.yaml", hs[2].NamespacePath)
+
+	// Opt-in hooks are supported.
+	require.NoError(t, os.WriteFile(GetHooksManifestFile(hooksDir),
+		[]byte("hooks:\n  fsmonitor-watchman:\n    - name: query\n      cmd: query.sh"), 0600)) //nolint:mnd
+	hs, _, err = GetAllHooksIn(gitx, repo, hooksDir, "fsmonitor-watchman", "gh-self", nil,
+		isIgnored, isTrusted, false, true, nil)
+	require.NoError(t, err)
+	require.Len(t, hs, 1)
+	assert.Equal(t, "ns:gh-self/hooks.yaml#fsmonitor-watchman/query", hs[0].NamespacePath)
+
+	// Manifests are not considered for replaced hooks.
+	hs, _, err = GetAllHooksIn(gitx, repo, hooksDir, "pre-commit", "gh-self", nil,
+		isIgnored, isTrusted, false, false, nil)
+	require.NoError(t, err)
+	assert.Len(t, hs, 1)
+
+	// Invalid manifests.
+	invalid := []string{
+		"version: 2",
+		"hooks:\n  pre-nothing:\n    - name: a\n      cmd: a",
+		"hooks:\n  pre-commit:\n    - cmd: a",
+		"hooks:\n  pre-commit:\n    - name: a/b\n      cmd: a",
+		"hooks:\n  pre-commit:\n    - name: a\n      cmd: a\n    - name: a\n      cmd: b",
+		"hooks:\n  pre-commit:\n    - name: a",
+		"hooks:\n  proc-receive:\n    - name: a\n      cmd: a\n    - name: b\n      cmd: b",
+	}
+
+	for _, m := range invalid {
+		require.NoError(t, os.WriteFile(GetHooksManifestFile(hooksDir), []byte(m), 0600)) //nolint:mnd
+		_, _, err = GetAllHooksIn(gitx, repo, hooksDir, "pre-commit", "gh-self", nil,
+			isIgnored, isTrusted, false, true, nil)
+		assert.Error(t, err, "Manifest:\n%s", m)
+	}
+}
+
+func TestHookMatchesFiles(t *testing.T) {
+	hook := Hook{Files: []string{"*.go", "docs/**/*.md"}}
+
+	tests := map[string]bool{
+		"main.go":             true,
+		"cmd/list/list.go":    true,
+		"docs/a/b/readme.md":  true,
+		"readme.md":           false,
+		"githooks/go.mod":     false,
+		"docs/cli/git_hooks":  false,
+		"other/docs/index.md": false,
+	}
+
+	for file, expected := range tests {
+		matched, err := hook.MatchesFiles([]string{file})
+		require.NoError(t, err)
+		assert.Equal(t, expected, matched, "File: '%s'", file)
+	}
+
+	matched, err := (&Hook{}).MatchesFiles(nil)
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
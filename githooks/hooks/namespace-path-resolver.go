@@ -79,3 +79,49 @@ func ResolveNamespacePaths(
 
 	return res, foundAll, err
 }
+
+// ResolveSharedRoot gets the root directory of the shared repository with
+// namespace `namespace` (its configured or default namespace).
+// The namespace `gh-self` resolves to the repository `repoDir`.
+func ResolveSharedRoot(
+	gitx *git.Context,
+	installDir string,
+	repoDir string,
+	namespace string) (root string, found bool, err error) {
+	if namespace == NamespaceRepositoryHook {
+		return repoDir, true, nil
+	}
+
+	allRepos, err := LoadRepoSharedHooks(installDir, repoDir)
+	if err != nil {
+		return
+	}
+
+	for _, scope := range []git.ConfigScope{git.LocalScope, git.GlobalScope} {
+		repos, e := LoadConfigSharedHooks(installDir, gitx, scope)
+		if e != nil {
+			return "", false, e
+		}
+
+		allRepos = append(allRepos, repos...)
+	}
+
+	for rI := range allRepos {
+		repo := &allRepos[rI]
+
+		if !cm.IsDirectory(repo.RepositoryDir) {
+			continue
+		}
+
+		ns, e := GetHooksNamespace(GetSharedGithooksDir(repo.RepositoryDir))
+		if e != nil {
+			return "", false, e
+		}
+
+		if ns == namespace || GetDefaultHooksNamespaceShared(repo) == namespace {
+			return repo.RepositoryDir, true, nil
+		}
+	}
+
+	return "", false, nil
+}
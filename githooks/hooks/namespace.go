@@ -14,6 +14,8 @@ const (
 	NamespaceRepositoryHook = "gh-self"
 	// NamespaceReplacedHook is the namespace for replaced hooks.
 	NamespaceReplacedHook = "gh-replaced"
+	// NamespaceBuiltinHook is the namespace for built-in hooks.
+	NamespaceBuiltinHook = "gh-builtin"
 
 	// NamespacePrefix prefixes the namespace part in the namespace path of a hook.
 	NamespacePrefix = "ns:"
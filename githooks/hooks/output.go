@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/gookit/color"
+)
+
+// OutputModeType is the enum type of the output mode of hooks.
+type OutputModeType int
+type outputModeType struct {
+	Buffered OutputModeType
+	Stream   OutputModeType
+}
+
+// OutputModeTypeV enumerates all output modes of hooks.
+// In buffered mode (default) the output of a hook is shown after it finished.
+// In stream mode the output of a hook is shown live with each line prefixed.
+var OutputModeTypeV = &outputModeType{Buffered: 0, Stream: 1} // nolint: mnd
+
+// The environment variable which overwrites the output mode.
+const outputModeEnv = "GITHOOKS_OUTPUT_MODE"
+
+// GetOutputMode gets the output mode of hooks from the environment
+// variable `GITHOOKS_OUTPUT_MODE` or the Git config.
+func GetOutputMode(gitx *git.Context) (OutputModeType, error) {
+	mode := os.Getenv(outputModeEnv)
+	if strs.IsEmpty(mode) {
+		mode = gitx.GetConfig(GitCKOutputMode, git.Traverse)
+	}
+
+	switch mode {
+	case "", "buffered":
+		return OutputModeTypeV.Buffered, nil
+	case "stream":
+		return OutputModeTypeV.Stream, nil
+	default:
+		return OutputModeTypeV.Buffered, cm.ErrorF("Output mode '%s' is not 'buffered' or 'stream'.", mode)
+	}
+}
+
+// Name gets the name of the output mode.
+func (m *OutputModeType) Name() string {
+	switch *m {
+	case OutputModeTypeV.Stream:
+		return "stream"
+	default:
+		return "buffered"
+	}
+}
+
+// HookOutputFunc creates the writers to which the stdout and stderr output
+// of the hook `hook` is written live.
+type HookOutputFunc func(hook *Hook) (stdout io.WriteCloser, stderr io.WriteCloser)
+
+// The colors of the line prefixes of streamed output.
+var outputPrefixColors = []color.Color{
+	color.FgCyan, color.FgMagenta, color.FgYellow, color.FgGreen, color.FgBlue,
+	color.FgLightCyan, color.FgLightMagenta, color.FgLightYellow, color.FgLightGreen, color.FgLightBlue,
+}
+
+// NewStreamedOutput creates writers which stream the output of a hook
+// with each line prefixed by its namespace path to the log `log`.
+func NewStreamedOutput(log cm.ILogContext) HookOutputFunc {
+	var lock sync.Mutex
+
+	return func(hook *Hook) (io.WriteCloser, io.WriteCloser) {
+		prefix := strs.Fmt("[%s] ", hook.NamespacePath)
+
+		if log.HasColor() {
+			// The same hook gets always the same color.
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(hook.NamespacePath))
+			prefix = outputPrefixColors[h.Sum32()%uint32(len(outputPrefixColors))].Render(prefix)
+		}
+
+		return cm.NewLinePrefixWriter(log.GetInfoWriter(), prefix, &lock),
+			cm.NewLinePrefixWriter(log.GetErrorWriter(), prefix, &lock)
+	}
+}
@@ -0,0 +1,74 @@
+package hooks
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamedOutput(t *testing.T) {
+	script := "echo out1; echo err1 >&2; printf 'out2'"
+	hook := Hook{
+		IExecutable:   &cm.Executable{Cmd: "sh", Args: []string{"-c", script}},
+		NamespacePath: "ns:a/pre-commit/a"}
+
+	var stdout, stderr bytes.Buffer
+	var lock sync.Mutex
+	output := func(h *Hook) (io.WriteCloser, io.WriteCloser) {
+		prefix := "[" + h.NamespacePath + "] "
+
+		return cm.NewLinePrefixWriter(&stdout, prefix, &lock),
+			cm.NewLinePrefixWriter(&stderr, prefix, &lock)
+	}
+
+	var reported []HookResult
+	res, err := ExecuteHooksParallel(
+		nil, &cm.ExecContext{Cwd: t.TempDir()}, HookPrioList{{hook}}, nil,
+		cm.UseOnlyStdin(nil), output, nil,
+		func(res ...HookResult) { reported = append(reported, res...) })
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Len(t, reported, 1)
+
+	assert.NoError(t, res[0].Error)
+	assert.Equal(t, "[ns:a/pre-commit/a] out1\n[ns:a/pre-commit/a] out2\n", stdout.String())
+	assert.Equal(t, "[ns:a/pre-commit/a] err1\n", stderr.String())
+
+	// The whole output is still reported.
+	assert.Contains(t, string(res[0].Output), "out1\n")
+	assert.Contains(t, string(res[0].Output), "err1\n")
+	assert.Contains(t, string(res[0].Output), "out2")
+}
+
+func TestOutputMode(t *testing.T) {
+	t.Setenv(outputModeEnv, "")
+
+	_, gitx := newTestRepo(t)
+
+	mode, err := GetOutputMode(gitx)
+	require.NoError(t, err)
+	assert.Equal(t, OutputModeTypeV.Buffered, mode)
+
+	require.NoError(t, gitx.SetConfig(GitCKOutputMode, "stream", git.LocalScope))
+	mode, err = GetOutputMode(gitx)
+	require.NoError(t, err)
+	assert.Equal(t, OutputModeTypeV.Stream, mode)
+	assert.Equal(t, "stream", mode.Name())
+
+	// The environment overwrites the config.
+	t.Setenv(outputModeEnv, "buffered")
+	mode, err = GetOutputMode(gitx)
+	require.NoError(t, err)
+	assert.Equal(t, OutputModeTypeV.Buffered, mode)
+
+	t.Setenv(outputModeEnv, "live")
+	_, err = GetOutputMode(gitx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package hooks
+
+import (
+	cm "github.com/gabyx/githooks/githooks/common"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// ProtocolHookNames are the hook names which talk a protocol with Git
+// over the standard input and output. Only a single hook can run and its
+// standard streams are passed through unchanged:
+//   - `fsmonitor-watchman`: Git reads the result from the standard output.
+//   - `proc-receive`: Git talks the pkt-line protocol over the standard input and output.
+var ProtocolHookNames = [2]string{"fsmonitor-watchman", "proc-receive"}
+
+// IsProtocolHookName returns `true` if the hook `hookName` talks a protocol with Git.
+func IsProtocolHookName(hookName string) bool {
+	return strs.Includes(ProtocolHookNames[:], hookName)
+}
+
+// CheckProtocolHooks checks that at most one hook is
+// given for the hook `hookName` if it talks a protocol with Git.
+func CheckProtocolHooks(hookName string, count int) error {
+	if count <= 1 || !IsProtocolHookName(hookName) {
+		return nil
+	}
+
+	return cm.ErrorF(
+		"Hook '%s' talks a protocol with Git and supports only a single hook, but '%v' are given.",
+		hookName, count)
+}
@@ -0,0 +1,116 @@
+package hooks
+
+import (
+	"sort"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// EnvVariablePushUpdates is the environment variable which holds the pushed
+// references on `pre-push`, one `<local-ref> <local-sha> <remote-ref> <remote-sha>` per line.
+const EnvVariablePushUpdates = "GITHOOKS_PUSH_UPDATES"
+
+// EnvVariablePushedFilesFile is the environment variable pointing to a file
+// which holds the union of all files changed by the pushed references on `pre-push`,
+// separated by null-chars, relative to the repository where Githooks runs.
+const EnvVariablePushedFilesFile = "GITHOOKS_PUSHED_FILES_FILE"
+
+// PushUpdate is the update of a remote reference pushed on `pre-push`.
+type PushUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// IsCreate returns `true` if the remote reference is created.
+func (p *PushUpdate) IsCreate() bool {
+	return p.RemoteSHA == git.NullRef
+}
+
+// IsDelete returns `true` if the remote reference is deleted.
+func (p *PushUpdate) IsDelete() bool {
+	return p.LocalSHA == git.NullRef
+}
+
+// String formats the update as `<local-ref> <local-sha> <remote-ref> <remote-sha>`.
+func (p *PushUpdate) String() string {
+	return strs.Fmt("%s %s %s %s", p.LocalRef, p.LocalSHA, p.RemoteRef, p.RemoteSHA)
+}
+
+// ParsePushUpdates parses the pushed references from the standard input
+// of `pre-push`, one `<local-ref> <local-sha> <remote-ref> <remote-sha>` per line.
+func ParsePushUpdates(stdin string) (updates []PushUpdate, err error) {
+	for _, line := range strings.Split(stdin, "\n") {
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) != 4: //nolint:mnd
+			return nil, cm.ErrorF("Pushed reference '%s' must be of the form "+
+				"'<local-ref> <local-sha> <remote-ref> <remote-sha>'.", line)
+		}
+
+		updates = append(updates,
+			PushUpdate{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+
+	return
+}
+
+// FormatPushUpdates formats the updates as `<local-ref> <local-sha> <remote-ref> <remote-sha>` lines.
+func FormatPushUpdates(updates []PushUpdate) string {
+	lines := make([]string, 0, len(updates))
+	for i := range updates {
+		lines = append(lines, updates[i].String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GetPushUpdateChangedFiles gets all files changed by the push update `update` to
+// the remote `remote`. If the remote reference is created or its commit is not known
+// locally, these are all files changed in the commits which are not on any
+// remote-tracking branch of `remote` (or of any remote if `remote` has none, e.g.
+// if it is an URL).
+func GetPushUpdateChangedFiles(gitx *git.Context, remote string, update *PushUpdate) (files []string, err error) {
+	var changed string
+
+	switch {
+	case update.IsDelete():
+		return nil, nil
+	case update.IsCreate() || gitx.Check("cat-file", "-e", update.RemoteSHA+"^{commit}") != nil:
+		changed, err = gitx.Get("log", "--format=", "--name-only", "-z", "--diff-filter=ACMR",
+			update.LocalSHA, "--not", getRemoteRefsOption(gitx, remote), "--")
+	default:
+		changed, err = GetChangedFiles(gitx, update.RemoteSHA, update.LocalSHA)
+	}
+
+	if err != nil {
+		return nil, cm.CombineErrors(err,
+			cm.ErrorF("Could not get changed files of pushed reference '%s'.", update.String()))
+	}
+
+	files = strs.MakeUnique(strs.Filter(strings.Split(changed, "\x00"), strs.IsNotEmpty))
+	sort.Strings(files)
+
+	return
+}
+
+// getRemoteRefsOption gets the revision option `--remotes=<remote>` selecting
+// all remote-tracking branches of `remote` or `--remotes` selecting the ones of all
+// remotes if `remote` has none (e.g. it is an URL or is pushed to the first time).
+func getRemoteRefsOption(gitx *git.Context, remote string) string {
+	if strs.IsNotEmpty(remote) {
+		refs, err := gitx.Get("for-each-ref", "--count=1", "--format=%(refname)", "refs/remotes/"+remote+"/")
+		if err == nil && strs.IsNotEmpty(refs) {
+			return "--remotes=" + remote
+		}
+	}
+
+	return "--remotes"
+}
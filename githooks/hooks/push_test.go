@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePushUpdates(t *testing.T) {
+	updates, err := ParsePushUpdates(
+		"refs/heads/main aaa refs/heads/main bbb\n\n" +
+			"(delete) " + git.NullRef + " refs/heads/old ccc\n" +
+			"refs/heads/new ddd refs/heads/new " + git.NullRef + "\n")
+	require.NoError(t, err)
+	require.Len(t, updates, 3) //nolint:mnd
+
+	assert.Equal(t,
+		PushUpdate{LocalRef: "refs/heads/main", LocalSHA: "aaa", RemoteRef: "refs/heads/main", RemoteSHA: "bbb"},
+		updates[0])
+	assert.True(t, updates[1].IsDelete())
+	assert.False(t, updates[1].IsCreate())
+	assert.True(t, updates[2].IsCreate())
+	assert.Equal(t,
+		"refs/heads/main aaa refs/heads/main bbb\n"+
+			"(delete) "+git.NullRef+" refs/heads/old ccc\n"+
+			"refs/heads/new ddd refs/heads/new "+git.NullRef,
+		FormatPushUpdates(updates))
+
+	_, err = ParsePushUpdates("refs/heads/main aaa refs/heads/main\n")
+	assert.Error(t, err)
+}
+
+func TestGetPushUpdateChangedFiles(t *testing.T) {
+	repo, gitx := newTestRepo(t, "-b", "main")
+	require.NoError(t, gitx.Check("config", "user.name", "a"))
+	require.NoError(t, gitx.Check("config", "user.email", "a@b"))
+
+	commit := func(file string) string {
+		require.NoError(t, os.WriteFile(path.Join(repo, file), []byte(file), 0600)) //nolint:mnd
+		require.NoError(t, gitx.Check("add", file))
+		require.NoError(t, gitx.Check("commit", "-q", "-m", file))
+		sha, err := gitx.Get("rev-parse", "HEAD")
+		require.NoError(t, err)
+
+		return sha
+	}
+
+	base := commit("a.txt")
+	require.NoError(t, gitx.Check("update-ref", "refs/remotes/origin/main", base))
+	head := commit("b.txt")
+
+	files, err := GetPushUpdateChangedFiles(gitx, "origin",
+		&PushUpdate{LocalRef: "refs/heads/main", LocalSHA: head, RemoteRef: "refs/heads/main", RemoteSHA: base})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt"}, files)
+
+	// A new branch contains the files of all commits
+	// not on any remote-tracking branch.
+	require.NoError(t, gitx.Check("checkout", "-q", "-b", "feature"))
+	feature := commit("c.txt")
+
+	files, err = GetPushUpdateChangedFiles(gitx, "origin",
+		&PushUpdate{LocalRef: "refs/heads/feature", LocalSHA: feature,
+			RemoteRef: "refs/heads/feature", RemoteSHA: git.NullRef})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt", "c.txt"}, files)
+
+	// An unknown remote commit is treated as a new branch.
+	files, err = GetPushUpdateChangedFiles(gitx, "origin",
+		&PushUpdate{LocalRef: "refs/heads/feature", LocalSHA: feature,
+			RemoteRef: "refs/heads/feature", RemoteSHA: "1234567890123456789012345678901234567890"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt", "c.txt"}, files)
+
+	// An URL remote excludes the remote-tracking branches of all remotes.
+	files, err = GetPushUpdateChangedFiles(gitx, "https://example.com/repo.git",
+		&PushUpdate{LocalRef: "refs/heads/feature", LocalSHA: feature,
+			RemoteRef: "refs/heads/feature", RemoteSHA: git.NullRef})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt", "c.txt"}, files)
+
+	files, err = GetPushUpdateChangedFiles(gitx, "origin",
+		&PushUpdate{LocalRef: "(delete)", LocalSHA: git.NullRef, RemoteRef: "refs/heads/main", RemoteSHA: head})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
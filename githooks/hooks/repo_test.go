@@ -0,0 +1,25 @@
+package hooks
+
+import (
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo initializes a repository with `git init -q <initArgs...>` in a temporary
+// directory, isolated from the global and system Git configuration.
+func newTestRepo(t *testing.T, initArgs ...string) (string, *git.Context) {
+	t.Helper()
+
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repo := t.TempDir()
+	gitx := git.NewCtxAt(repo)
+	require.NoError(t, gitx.Check(append([]string{"init", "-q"}, initArgs...)...))
+
+	return repo, gitx
+}
@@ -22,10 +22,12 @@ type imageRunConfig struct {
 
 // The data for the runner config file.
 type runnerConfigFile struct {
-	Cmd   string         `yaml:"cmd"`
-	Args  []string       `yaml:"args"`
-	Env   []string       `yaml:"env"`
-	Image imageRunConfig `yaml:"image"`
+	Cmd         string             `yaml:"cmd"`
+	Args        []string           `yaml:"args"`
+	Env         []string           `yaml:"env"`
+	Image       imageRunConfig     `yaml:"image"`
+	When        string             `yaml:"when"`
+	Environment *environmentConfig `yaml:"environment"`
 
 	Version int `yaml:"version"`
 }
@@ -34,7 +36,9 @@ type runnerConfigFile struct {
 // Version 1: Initial file.
 // Version 2: Added `Env` field.
 // Version 3: Added `Images` field.
-var runnerConfigFileVersion int = 3
+// Version 4: Added `When` field.
+// Version 5: Added `Environment` field.
+var runnerConfigFileVersion int = 5
 
 // createHookIgnoreFile creates the data for the runner config file.
 func createRunnerConfig() runnerConfigFile {
@@ -60,11 +64,13 @@ func loadRunnerConfig(hookPath string) (data runnerConfigFile, err error) {
 	return
 }
 
-// GetHookRunCmd gets the executable for the hook `hookPath`.
+// GetHookRunCmd gets the executable for the hook `hookPath` which
+// runs for the Git hook `hookName` (if any).
 // Any command in a runner config YAML with path separators will
 // be made absolute to `rootDir`.
 func GetHookRunCmd(
 	gitx *git.Context,
+	hookName string,
 	hookPath string,
 	rootDir string,
 	hooksDir string,
@@ -72,37 +78,102 @@ func GetHookRunCmd(
 	containerMgr container.IManager,
 	hookNamespace string,
 	envs []string,
-) (cm.IExecutable, error) {
+) (cm.IExecutable, *HookEnvironment, error) {
+	run, err := getHookRunCmd(gitx, hookName, hookPath, rootDir, hooksDir,
+		parseRunnerConfig, containerMgr, hookNamespace, envs)
+
+	return run.exec, run.environment, err
+}
+
+// hookRun is the resolved execution of a hook.
+type hookRun struct {
+	// The executable of the hook.
+	exec cm.IExecutable
+	// The condition when the hook runs (if any).
+	when *Condition
+	// The command of the interpreter running the hook file (if any).
+	interpreter string
+	// The interpreter or environment override from the repository
+	// which is part of the trusted checksum of the hook (if any).
+	override string
+	// The managed tool environment of the hook (if any).
+	environment *HookEnvironment
+}
+
+// getHookRunCmd gets the execution of the hook `hookPath`
+// which runs for the Git hook `hookName` (if any).
+func getHookRunCmd(
+	gitx *git.Context,
+	hookName string,
+	hookPath string,
+	rootDir string,
+	hooksDir string,
+	parseRunnerConfig bool,
+	containerMgr container.IManager,
+	hookNamespace string,
+	envs []string,
+) (run hookRun, err error) {
 	exec := cm.NewExecutable(hookPath, nil, envs)
 
 	if cm.IsExecutable(exec.Cmd) {
-		return &exec, nil
+		run.exec = &exec
+
+		return
 	}
 
 	if !parseRunnerConfig || path.Ext(hookPath) != ".yaml" {
-		// Dont parse run config or not existing -> get the default runner.
-		return GetDefaultRunner(hookPath, envs), nil
+		// Dont parse run config or not existing -> get the interpreter.
+		run.exec, run.interpreter, run.override, err = getInterpreterRunCmd(gitx, hookPath, hooksDir, envs)
+		if err != nil {
+			err = cm.CombineErrors(err, cm.ErrorF("Could not get interpreter for '%s'", hookPath))
+		}
+
+		return
 	}
 
-	config, e := loadRunnerConfig(hookPath)
-	if e != nil {
-		return nil, cm.CombineErrors(e, cm.ErrorF("Could not read runner config '%s'", hookPath))
+	config, err := loadRunnerConfig(hookPath)
+	if err != nil {
+		return run, cm.CombineErrors(err, cm.ErrorF("Could not read runner config '%s'", hookPath))
 	}
 
-	subst := getVarSubstitution(os.LookupEnv, gitx.LookupConfig)
+	return getRunConfigCmd(gitx, hookName, hookPath, rootDir, &config, containerMgr, hookNamespace, envs)
+}
+
+// getRunConfigCmd gets the execution of the
+// run configuration `config` defined in file `hookPath`.
+func getRunConfigCmd(
+	gitx *git.Context,
+	hookName string,
+	hookPath string,
+	rootDir string,
+	config *runnerConfigFile,
+	containerMgr container.IManager,
+	hookNamespace string,
+	envs []string,
+) (run hookRun, err error) {
+	exec := cm.NewExecutable(hookPath, nil, envs)
+
+	if strs.IsNotEmpty(strings.TrimSpace(config.When)) {
+		if run.when, err = ParseCondition(config.When); err != nil {
+			return run, cm.CombineErrors(err,
+				cm.ErrorF("Error in hook run config '%s'.", hookPath))
+		}
+	}
+
+	subst := getVarSubstitution(os.LookupEnv, gitx.LookupConfig,
+		getRunConfigVars(gitx, hookName, hookPath, hookNamespace))
 
 	// Substitute variable in env values.
-	var err error
 	for i := range config.Env {
 		if config.Env[i], err = subst(config.Env[i]); err != nil {
-			return nil, cm.CombineErrors(err,
+			return run, cm.CombineErrors(err,
 				cm.ErrorF("Error in hook run config '%s'.", hookPath))
 		}
 	}
 
 	// Substitute variables in command.
 	if exec.Cmd, err = subst(config.Cmd); err != nil {
-		return nil, cm.CombineErrors(err,
+		return run, cm.CombineErrors(err,
 			cm.ErrorF("Error in hook run config '%s'.", hookPath))
 	}
 
@@ -111,7 +182,7 @@ func GetHookRunCmd(
 	// Substitute variables in arguments.
 	for i := range exec.Args {
 		if exec.Args[i], err = subst(exec.Args[i]); err != nil {
-			return nil, cm.CombineErrors(err,
+			return run, cm.CombineErrors(err,
 				cm.ErrorF("Error in hook run config '%s'.", hookPath))
 		}
 	}
@@ -124,21 +195,24 @@ func GetHookRunCmd(
 
 		reference, eR := addImageReferenceSuffix(config.Image.Reference, hookPath, hookNamespace)
 		if eR != nil {
-			return nil, eR
+			return run, eR
 		}
 
-		containerExec, eR := containerMgr.NewHookRunExec(
+		// Hooks with data on the standard input need it attached.
+		attachStdin := strs.Includes(StdinHookNames[:], hookName) || IsProtocolHookName(hookName)
+
+		run.exec, eR = containerMgr.NewHookRunExec(
 			reference,
 			gitx.GetCwd(),
 			rootDir, &exec,
-			false, false,
+			attachStdin, false,
 		)
 
 		if eR != nil {
-			return nil, cm.CombineErrors(eR, cm.Error("Could not create container hook executor."))
+			return run, cm.CombineErrors(eR, cm.Error("Could not create container hook executor."))
 		}
 
-		return containerExec, nil
+		return run, nil
 	} else {
 		// Normal execution.
 
@@ -155,17 +229,130 @@ func GetHookRunCmd(
 			}
 		}
 
-		return &exec, nil
+		// Put the managed tool environment on the `PATH`.
+		// Env. variables from the run config have priority.
+		if config.Environment != nil {
+			run.environment, err = newHookEnvironment(
+				GetInstallDir(gitx), rootDir, config.Environment, hookPath)
+			if err != nil {
+				return run, cm.CombineErrors(err,
+					cm.ErrorF("Error in hook run config '%s'.", hookPath))
+			}
+
+			exec.Env = append(run.environment.GetEnv(), exec.Env...)
+
+			// The packages installed into the environment are trusted with the hook.
+			run.override = strs.Fmt("environment: %s", path.Base(run.environment.Dir))
+		}
+
+		run.exec = &exec
+
+		return run, nil
 	}
 }
 
+// GetHookRunImage gets the container image reference of the hook `hookPath`
+// or empty if the hook is not configured to run containerized.
+func GetHookRunImage(hookPath string, hookNamespace string) (string, error) {
+	if path.Ext(hookPath) != ".yaml" || cm.IsExecutable(hookPath) {
+		return "", nil
+	}
+
+	config, err := loadRunnerConfig(hookPath)
+	if err != nil || strs.IsEmpty(config.Image.Reference) {
+		return "", err
+	}
+
+	return addImageReferenceSuffix(config.Image.Reference, hookPath, hookNamespace)
+}
+
+// The types `env` and `git*` keep their name grammar, the types `repo`, `hook`
+// and `shared` allow names like `git-dir` or shared namespaces.
 var reEnvVariable = regexp.MustCompile(
-	`(\\?)\$\{(!?)(env|git|git-l|git-g|git-s):([a-zA-Z.][a-zA-Z0-9_.]+)\}`,
+	`(\\?)\$\{(!?)(?:(env|git|git-l|git-g|git-s):([a-zA-Z.][a-zA-Z0-9_.]+)|` +
+		`(repo|hook|shared):([a-zA-Z0-9.][a-zA-Z0-9_.\-]*))(:-([^}]*))?\}`,
 )
 
+// varResolver resolves the variable `name` of a variable type, e.g. `${repo:name}`.
+type varResolver func(name string) (string, bool)
+
+// getRunConfigVars gets the resolvers for the variable types `repo`, `hook` and `shared`
+// for the hook `hookName` with run configuration `hookPath` in namespace `hookNamespace`.
+func getRunConfigVars(
+	gitx *git.Context,
+	hookName string,
+	hookPath string,
+	hookNamespace string) map[string]varResolver {
+	var repoRoot, gitDir, gitDirWorktree string
+	var repoErr error
+	repoLoaded := false
+
+	getRepo := func() bool {
+		if !repoLoaded {
+			repoRoot, gitDir, gitDirWorktree, repoErr = gitx.GetRepoRoot()
+			repoLoaded = true
+		}
+
+		return repoErr == nil
+	}
+
+	nonEmpty := func(s string) (string, bool) { return s, strs.IsNotEmpty(s) }
+
+	return map[string]varResolver{
+		"repo": func(name string) (string, bool) {
+			if !getRepo() {
+				return "", false
+			}
+
+			switch name {
+			case "root":
+				return repoRoot, true
+			case "git-dir":
+				return gitDirWorktree, true
+			case "git-common-dir":
+				return gitDir, true
+			default:
+				return "", false
+			}
+		},
+		"hook": func(name string) (string, bool) {
+			switch name {
+			case "name":
+				return nonEmpty(hookName)
+			case "namespace":
+				return nonEmpty(hookNamespace)
+			case "path":
+				return nonEmpty(hookPath)
+			case "dir":
+				if strs.IsEmpty(hookPath) {
+					return "", false
+				}
+
+				return path.Dir(hookPath), true
+			default:
+				return "", false
+			}
+		},
+		"shared": func(namespace string) (string, bool) {
+			if !getRepo() {
+				return "", false
+			}
+
+			root, found, err := ResolveSharedRoot(gitx, GetInstallDir(gitx), repoRoot, namespace)
+
+			return root, found && err == nil
+		},
+	}
+}
+
+// getVarSubstitution gets the substitution function for
+// variables `${<type>:<name>}` in run configurations.
+// The types `env` and `git*` are resolved by `getEnv` and `gitGet`,
+// all others by `resolvers`.
 func getVarSubstitution(
 	getEnv func(string) (string, bool),
-	gitGet func(string, git.ConfigScope) (string, bool)) func(string) (string, error) {
+	gitGet func(string, git.ConfigScope) (string, bool),
+	resolvers map[string]varResolver) func(string) (string, error) {
 	return func(s string) (res string, err error) {
 		res = reEnvVariable.ReplaceAllStringSubmatchFunc(s, func(match []string) (subs string) {
 			// Escape '\${var}' => '${var}'
@@ -175,19 +362,35 @@ func getVarSubstitution(
 
 			var exists bool
 
-			switch match[3] {
+			varType, name := match[3], match[4]
+			if strs.IsEmpty(varType) {
+				varType, name = match[5], match[6]
+			}
+
+			switch varType {
 			case "env":
-				subs, exists = getEnv(match[4])
+				subs, exists = getEnv(name)
 			case "git":
-				subs, exists = gitGet(match[4], git.Traverse)
+				subs, exists = gitGet(name, git.Traverse)
 			case "git-l":
-				subs, exists = gitGet(match[4], git.LocalScope)
+				subs, exists = gitGet(name, git.LocalScope)
 			case "git-g":
-				subs, exists = gitGet(match[4], git.GlobalScope)
+				subs, exists = gitGet(name, git.GlobalScope)
 			case "git-s":
-				subs, exists = gitGet(match[4], git.SystemScope)
+				subs, exists = gitGet(name, git.SystemScope)
 			default:
-				cm.DebugAssert(false, "This should not happen.")
+				if resolve := resolvers[varType]; resolve != nil {
+					subs, exists = resolve(name)
+				}
+			}
+
+			if !exists {
+				subs = ""
+			}
+
+			// Default value `${type:VAR:-default}` if not existing or empty.
+			if len(match[7]) != 0 && strs.IsEmpty(subs) {
+				return match[8]
 			}
 
 			if len(match[2]) != 0 && !exists {
@@ -3,6 +3,7 @@ package hooks
 import (
 	"io"
 	"os"
+	"path"
 	"testing"
 
 	"github.com/gabyx/githooks/githooks/git"
@@ -26,7 +27,7 @@ func getGitConfig(key string, scope git.ConfigScope) (string, bool) {
 }
 
 func TestEnvReplace(t *testing.T) {
-	subst := getVarSubstitution(os.LookupEnv, getGitConfig)
+	subst := getVarSubstitution(os.LookupEnv, getGitConfig, nil)
 
 	_ = os.Setenv("var", "banana")
 	_ = os.Setenv("tar", "monkey")
@@ -110,3 +111,99 @@ func TestRunnerConfigVersion(t *testing.T) {
 		assert.Contains(t, e.Error(), "Githooks only supports version >= 1")
 	}
 }
+
+func TestGetHookRunImage(t *testing.T) {
+	d := t.TempDir()
+
+	file := path.Join(d, "check.yaml")
+	e := os.WriteFile(file,
+		[]byte("version: 3\ncmd: check\nimage:\n  reference: ${namespace}-check:1.0\n"), 0600) //nolint:mnd
+	assert.NoError(t, e)
+
+	image, e := GetHookRunImage(file, "mine")
+	assert.NoError(t, e)
+	assert.Equal(t, "mine-check:1.0", image)
+
+	file = path.Join(d, "plain.yaml")
+	e = os.WriteFile(file, []byte("version: 3\ncmd: check\n"), 0600) //nolint:mnd
+	assert.NoError(t, e)
+
+	image, e = GetHookRunImage(file, "mine")
+	assert.NoError(t, e)
+	assert.Empty(t, image)
+
+	image, e = GetHookRunImage(path.Join(d, "script.sh"), "mine")
+	assert.NoError(t, e)
+	assert.Empty(t, image)
+}
+
+func TestVarSubstitutionResolvers(t *testing.T) {
+	resolvers := map[string]varResolver{
+		"repo": func(name string) (string, bool) {
+			return "/repo-" + name, name == "root" || name == "git-dir"
+		},
+		"shared": func(ns string) (string, bool) {
+			return "/shared/" + ns, ns == "my-ns"
+		},
+	}
+
+	t.Setenv("EMPTY", "")
+	subst := getVarSubstitution(os.LookupEnv, getGitConfig, resolvers)
+
+	r, err := subst(`${repo:root}/bin ${repo:git-dir}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "/repo-root/bin /repo-git-dir", r)
+
+	r, err = subst(`${shared:my-ns}/scripts/run.sh`)
+	assert.NoError(t, err)
+	assert.Equal(t, "/shared/my-ns/scripts/run.sh", r)
+
+	r, err = subst(`${!shared:other}`)
+	assert.Error(t, err)
+	assert.Empty(t, r)
+
+	r, err = subst(`${hook:name}`)
+	assert.NoError(t, err, "Types without resolver are empty.")
+	assert.Empty(t, r)
+
+	// Defaults.
+	r, err = subst(`${env:NOT_EXISTING:-bar} ${env:EMPTY:-empty} ${!git:one.one:-x y}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar empty x y", r)
+
+	r, err = subst(`${git:two:-default} ${shared:my-ns:-/opt}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "two--traverse /shared/my-ns", r)
+
+	r, err = subst(`\${env:NOT_EXISTING:-bar}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `${env:NOT_EXISTING:-bar}`, r)
+
+	// The types `env` and `git*` keep their name grammar.
+	t.Setenv("A", "a")
+	r, err = subst(`${env:A} ${env:1var} ${env:my-var} ${git:a-b:-x} ${repo:git-dir}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `${env:A} ${env:1var} ${env:my-var} ${git:a-b:-x} /repo-git-dir`, r)
+}
+
+func TestRunConfigVars(t *testing.T) {
+	_, gitx := newTestRepo(t)
+
+	vars := getRunConfigVars(gitx, "pre-commit", "/a/b/check.yaml", "my-ns")
+	subst := getVarSubstitution(os.LookupEnv, gitx.LookupConfig, vars)
+
+	r, err := subst(`${hook:name} ${hook:namespace} ${hook:path} ${hook:dir}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "pre-commit my-ns /a/b/check.yaml /a/b", r)
+
+	root, err := gitx.Get("rev-parse", "--show-toplevel")
+	assert.NoError(t, err)
+
+	r, err = subst(`${repo:root} ${shared:gh-self}`)
+	assert.NoError(t, err)
+	assert.Equal(t, root+" "+root, r)
+
+	r, err = subst(`${!repo:unknown}`)
+	assert.Error(t, err)
+	assert.Empty(t, r)
+}
@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"sort"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// ServerModeHookNames are the hook names which run in server mode
+// when Githooks runs on a Git server.
+var ServerModeHookNames = [3]string{"pre-receive", "update", "post-receive"}
+
+// EnvVariableRefUpdates is the environment variable which holds the pushed
+// reference updates in server mode, one `<old-sha> <new-sha> <ref>` per line.
+const EnvVariableRefUpdates = "GITHOOKS_REF_UPDATES"
+
+// EnvVariableChangedFilesFile is the environment variable pointing to a file
+// which holds the changed files of each pushed reference in server mode,
+// one `<ref>\t<file>` per line, relative to the repository where Githooks runs.
+const EnvVariableChangedFilesFile = "GITHOOKS_CHANGED_FILES_FILE"
+
+// RefUpdate is the update of a reference pushed to a repository.
+type RefUpdate struct {
+	Ref    string
+	OldSHA string
+	NewSHA string
+}
+
+// IsCreate returns `true` if the reference is created.
+func (r *RefUpdate) IsCreate() bool {
+	return r.OldSHA == git.NullRef
+}
+
+// IsDelete returns `true` if the reference is deleted.
+func (r *RefUpdate) IsDelete() bool {
+	return r.NewSHA == git.NullRef
+}
+
+// String formats the update as `<old-sha> <new-sha> <ref>`.
+func (r *RefUpdate) String() string {
+	return strs.Fmt("%s %s %s", r.OldSHA, r.NewSHA, r.Ref)
+}
+
+// ParseRefUpdates parses the reference updates from the standard input
+// of `pre-receive` or `post-receive`, one `<old-sha> <new-sha> <ref>` per line.
+func ParseRefUpdates(stdin string) (updates []RefUpdate, err error) {
+	for _, line := range strings.Split(stdin, "\n") {
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) != 3: //nolint:mnd
+			return nil, cm.ErrorF("Reference update '%s' must be of the form "+
+				"'<old-sha> <new-sha> <ref>'.", line)
+		}
+
+		updates = append(updates, RefUpdate{OldSHA: fields[0], NewSHA: fields[1], Ref: fields[2]})
+	}
+
+	return
+}
+
+// GetRefUpdatesFromArgs gets the reference update from the arguments
+// `<ref> <old-sha> <new-sha>` of the `update` hook.
+func GetRefUpdatesFromArgs(args []string) ([]RefUpdate, error) {
+	if len(args) != 3 { //nolint:mnd
+		return nil, cm.ErrorF("Hook arguments '%q' must be '<ref> <old-sha> <new-sha>'.", args)
+	}
+
+	return []RefUpdate{{Ref: args[0], OldSHA: args[1], NewSHA: args[2]}}, nil
+}
+
+// FormatRefUpdates formats the updates as `<old-sha> <new-sha> <ref>` lines.
+func FormatRefUpdates(updates []RefUpdate) string {
+	lines := make([]string, 0, len(updates))
+	for i := range updates {
+		lines = append(lines, updates[i].String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GetRefUpdateChangedFiles gets all files changed by the reference update `update`.
+// For created references these are all files changed in the commits which are not
+// reachable by any other reference. The objects are also found in the quarantine
+// environment of `pre-receive` since the environment is inherited by Git.
+func GetRefUpdateChangedFiles(gitx *git.Context, update *RefUpdate) (files []string, err error) {
+	var changed string
+
+	switch {
+	case update.IsDelete():
+		return nil, nil
+	case update.IsCreate():
+		changed, err = gitx.Get("log", "--format=", "--name-only", "-z", "--diff-filter=ACMR",
+			update.NewSHA, "--not", "--exclude="+update.Ref, "--all", "--")
+	default:
+		changed, err = GetChangedFiles(gitx, update.OldSHA, update.NewSHA)
+	}
+
+	if err != nil {
+		return nil, cm.CombineErrors(err,
+			cm.ErrorF("Could not get changed files of reference update '%s'.", update.String()))
+	}
+
+	files = strs.MakeUnique(strs.Filter(strings.Split(changed, "\x00"), strs.IsNotEmpty))
+	sort.Strings(files)
+
+	return
+}
+
+// SetServerMode sets the settings if Githooks runs in server mode.
+func SetServerMode(
+	gitx *git.Context,
+	enable bool,
+	reset bool,
+	scope git.ConfigScope,
+) error {
+	switch {
+	case reset:
+		return gitx.UnsetConfig(GitCKServerMode, scope)
+	default:
+		return gitx.SetConfig(GitCKServerMode, enable, scope)
+	}
+}
+
+// IsServerModeEnabled gets the settings if Githooks runs in server mode.
+func IsServerModeEnabled(gitx *git.Context, scope git.ConfigScope) (enabled bool, isSet bool) {
+	conf := gitx.GetConfig(GitCKServerMode, scope)
+
+	switch {
+	case strs.IsEmpty(conf) || conf == git.GitCVFalse:
+		return
+	default:
+		return conf == git.GitCVTrue, true
+	}
+}
@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRefUpdates(t *testing.T) {
+	updates, err := ParseRefUpdates(
+		"aaa bbb refs/heads/main\n\n" + git.NullRef + " ccc refs/heads/feature\n")
+	require.NoError(t, err)
+	require.Len(t, updates, 2) //nolint:mnd
+
+	assert.Equal(t, RefUpdate{Ref: "refs/heads/main", OldSHA: "aaa", NewSHA: "bbb"}, updates[0])
+	assert.True(t, updates[1].IsCreate())
+	assert.False(t, updates[1].IsDelete())
+	assert.Equal(t,
+		"aaa bbb refs/heads/main\n"+git.NullRef+" ccc refs/heads/feature",
+		FormatRefUpdates(updates))
+
+	_, err = ParseRefUpdates("aaa refs/heads/main\n")
+	assert.Error(t, err)
+
+	updates, err = GetRefUpdatesFromArgs([]string{"refs/heads/main", "aaa", git.NullRef})
+	require.NoError(t, err)
+	assert.True(t, updates[0].IsDelete())
+
+	_, err = GetRefUpdatesFromArgs([]string{"refs/heads/main"})
+	assert.Error(t, err)
+}
+
+func TestGetRefUpdateChangedFiles(t *testing.T) {
+	repo, gitx := newTestRepo(t, "-b", "main")
+	require.NoError(t, gitx.Check("config", "user.name", "a"))
+	require.NoError(t, gitx.Check("config", "user.email", "a@b"))
+
+	commit := func(file string) string {
+		require.NoError(t, os.WriteFile(path.Join(repo, file), []byte(file), 0600)) //nolint:mnd
+		require.NoError(t, gitx.Check("add", file))
+		require.NoError(t, gitx.Check("commit", "-q", "-m", file))
+		sha, err := gitx.Get("rev-parse", "HEAD")
+		require.NoError(t, err)
+
+		return sha
+	}
+
+	base := commit("a.txt")
+	head := commit("b.txt")
+
+	files, err := GetRefUpdateChangedFiles(gitx,
+		&RefUpdate{Ref: "refs/heads/main", OldSHA: base, NewSHA: head})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.txt"}, files)
+
+	// A new branch only contains the files of commits
+	// not reachable from other references.
+	require.NoError(t, gitx.Check("checkout", "-q", "-b", "feature"))
+	feature := commit("c.txt")
+	require.NoError(t, gitx.Check("checkout", "-q", "main"))
+	require.NoError(t, gitx.Check("branch", "-q", "-D", "feature"))
+
+	files, err = GetRefUpdateChangedFiles(gitx,
+		&RefUpdate{Ref: "refs/heads/feature", OldSHA: git.NullRef, NewSHA: feature})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c.txt"}, files)
+
+	files, err = GetRefUpdateChangedFiles(gitx,
+		&RefUpdate{Ref: "refs/heads/main", OldSHA: head, NewSHA: git.NullRef})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
@@ -1,6 +1,10 @@
 package hooks
 
-import "github.com/gabyx/githooks/githooks/git"
+import (
+	"strings"
+
+	"github.com/gabyx/githooks/githooks/git"
+)
 
 // GetStagedFiles gets all currently staged files.
 // Delimited by `\x00`.
@@ -12,3 +16,21 @@ func GetStagedFiles(gitx *git.Context) (string, error) {
 
 	return changed, nil
 }
+
+// GetChangedFiles gets all files changed on `toRef` since its merge base
+// with `fromRef`, i.e. the changes of the commits in `fromRef..toRef`.
+// Delimited by `\x00`.
+func GetChangedFiles(gitx *git.Context, fromRef string, toRef string) (string, error) {
+	changed, err := gitx.Get("diff", "--diff-filter=ACMR", "--name-only", "-z", fromRef+"..."+toRef, "--")
+
+	return strings.TrimRight(changed, "\x00"), err
+}
+
+// GetChangedFilesOfCommit gets all files changed in commit `commitSHA`
+// with respect to its first parent. Delimited by `\x00`.
+func GetChangedFilesOfCommit(gitx *git.Context, commitSHA string) (string, error) {
+	changed, err := gitx.Get("diff-tree", "--root", "--no-commit-id", "-r", "-m", "--first-parent",
+		"--diff-filter=ACMR", "--name-only", "-z", commitSHA)
+
+	return strings.TrimRight(changed, "\x00"), err
+}
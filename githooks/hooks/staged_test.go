@@ -0,0 +1,50 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFiles(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	commit := func(file string) string {
+		require.NoError(t, os.WriteFile(path.Join(repo, file), []byte(file), 0600)) //nolint:mnd
+		require.NoError(t, gitx.Check("add", file))
+		require.NoError(t, gitx.Check("-c", "user.name=a", "-c", "user.email=a@b", "commit", "-q", "-m", file))
+		sha, err := gitx.Get("rev-parse", git.HEAD)
+		require.NoError(t, err)
+
+		return sha
+	}
+
+	first := commit("a.txt")
+	second := commit("b.txt")
+	third := commit("c.txt")
+
+	files, err := GetChangedFiles(gitx, first, third)
+	require.NoError(t, err)
+	assert.Equal(t, "b.txt\x00c.txt", files)
+
+	// Changes only on a moved base are not contained.
+	require.NoError(t, gitx.Check("checkout", "-q", "-b", "base", first))
+	base := commit("d.txt")
+
+	files, err = GetChangedFiles(gitx, base, third)
+	require.NoError(t, err)
+	assert.Equal(t, "b.txt\x00c.txt", files)
+
+	files, err = GetChangedFilesOfCommit(gitx, second)
+	require.NoError(t, err)
+	assert.Equal(t, "b.txt", files)
+
+	files, err = GetChangedFilesOfCommit(gitx, first)
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", files)
+}
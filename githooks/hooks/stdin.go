@@ -0,0 +1,21 @@
+package hooks
+
+import (
+	"bytes"
+	"io"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+)
+
+// StdinHookNames are the hook names which get data on the standard input.
+// The standard input is read once and replayed to each hook.
+var StdinHookNames = [5]string{
+	"pre-push", "pre-receive", "post-receive", "post-rewrite", "reference-transaction"}
+
+// ReplayStdin returns a pipe setup function which gives each
+// call its own reader over the full standard input `data`.
+func ReplayStdin(data []byte) cm.PipeSetupFunc {
+	return func() (io.Reader, io.Writer, io.Writer) {
+		return bytes.NewReader(data), nil, nil
+	}
+}
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/container"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	thx "github.com/pbenner/threadpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayStdinParallelPrePush(t *testing.T) {
+	stdin := "refs/heads/main aaa refs/heads/main bbb\n" +
+		"refs/heads/feature ccc refs/heads/feature " + git.NullRef + "\n"
+
+	const nHooks = 8
+	batch := make([]Hook, 0, nHooks)
+	for i := range nHooks {
+		batch = append(batch, Hook{
+			IExecutable:   &cm.Executable{Cmd: "sh", Args: []string{"-c", "cat"}},
+			NamespacePath: strs.Fmt("ns:a/pre-push/%v", i)})
+	}
+
+	pool := thx.New(4, 15) //nolint:mnd
+
+	var reported []HookResult
+	res, err := ExecuteHooksParallel(
+		&pool, &cm.ExecContext{Cwd: t.TempDir()}, HookPrioList{batch, batch[:2]}, nil,
+		ReplayStdin([]byte(stdin)), nil, nil,
+		func(res ...HookResult) { reported = append(reported, res...) })
+	require.NoError(t, err)
+	require.Len(t, res, nHooks+2)
+	require.Len(t, reported, nHooks+2)
+
+	// Every hook gets the full standard input.
+	for i := range res {
+		assert.NoError(t, res[i].Error)
+		assert.Equal(t, stdin, string(res[i].Output), res[i].Hook.NamespacePath)
+	}
+}
+
+// A container manager which records if the standard input is attached.
+type stdinRecordingManager struct {
+	container.IManager
+
+	attachStdin []bool
+}
+
+func (m *stdinRecordingManager) NewHookRunExec(
+	ref string,
+	workspaceDir string,
+	workspaceHookDir string,
+	exe cm.IExecutable,
+	attachStdIn bool,
+	allocateTTY bool) (cm.IExecutable, error) {
+	m.attachStdin = append(m.attachStdin, attachStdIn)
+
+	return exe, nil
+}
+
+func TestContainerizedHooksAttachStdin(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	mgr := &stdinRecordingManager{}
+	config := runnerConfigFile{Cmd: "check.sh", Image: imageRunConfig{Reference: "check:1.0"}}
+
+	for _, hookName := range []string{"pre-push", "post-rewrite", "pre-commit"} {
+		_, err := getRunConfigCmd(gitx, hookName,
+			path.Join(repo, ".githooks", hookName, "check.yaml"), repo,
+			&config, mgr, "ns", nil)
+		require.NoError(t, err, hookName)
+	}
+
+	assert.Equal(t, []bool{true, true, false}, mgr.attachStdin)
+}
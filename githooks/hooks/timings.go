@@ -0,0 +1,148 @@
+package hooks
+
+import (
+	"math"
+	"path"
+	"sort"
+	"time"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// The maximal number of durations kept for each hook.
+const hookTimingsMaxSamples = 50
+
+// The version of the hook timings file.
+const hookTimingsVersion = 1
+
+// HookTimings are the last durations of all executed hooks
+// indexed by hook name and namespace path.
+type HookTimings struct {
+	Version int                                   `json:"version"`
+	Hooks   map[string]map[string][]time.Duration `json:"hooks"`
+}
+
+// HookTimingStats are the duration statistics of a hook.
+type HookTimingStats struct {
+	HookName      string
+	NamespacePath string
+
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// GetHookTimingsFile gets the file with the hook timings in the Git directory.
+func GetHookTimingsFile(gitDir string) string {
+	return path.Join(gitDir, ".githooks.timings.json")
+}
+
+// LoadHookTimings loads the hook timings in the Git directory.
+func LoadHookTimings(gitDir string) (t HookTimings, err error) {
+	file := GetHookTimingsFile(gitDir)
+
+	if cm.IsFile(file) {
+		if err = cm.LoadJSON(file, &t); err != nil {
+			return HookTimings{}, cm.CombineErrors(err, cm.ErrorF("Could not load hook timings '%s'.", file))
+		}
+
+		if t.Version != hookTimingsVersion {
+			// Start over on unknown versions.
+			t = HookTimings{}
+		}
+	}
+
+	if t.Hooks == nil {
+		t.Hooks = make(map[string]map[string][]time.Duration)
+	}
+	t.Version = hookTimingsVersion
+
+	return t, nil
+}
+
+// Store stores the hook timings in the Git directory.
+func (t *HookTimings) Store(gitDir string) error {
+	return cm.StoreJSON(GetHookTimingsFile(gitDir), t)
+}
+
+// Add adds the durations of the executed hooks `res` of hook `hookName`.
+// Only the last durations of each hook are kept.
+func (t *HookTimings) Add(hookName string, res ...HookResult) {
+	hooks := t.Hooks[hookName]
+	if hooks == nil {
+		hooks = make(map[string][]time.Duration)
+		t.Hooks[hookName] = hooks
+	}
+
+	for i := range res {
+		nsPath := res[i].Hook.NamespacePath
+		durations := append(hooks[nsPath], res[i].Duration)
+
+		if len(durations) > hookTimingsMaxSamples {
+			durations = durations[len(durations)-hookTimingsMaxSamples:]
+		}
+
+		hooks[nsPath] = durations
+	}
+}
+
+// GetStats gets the duration statistics of all hooks
+// sorted by hook name and namespace path.
+func (t *HookTimings) GetStats() (stats []HookTimingStats) {
+	for hookName, hooks := range t.Hooks {
+		for nsPath, durations := range hooks {
+			if len(durations) == 0 {
+				continue
+			}
+
+			sorted := make([]time.Duration, len(durations))
+			copy(sorted, durations)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+			stats = append(stats,
+				HookTimingStats{
+					HookName:      hookName,
+					NamespacePath: nsPath,
+					Count:         len(sorted),
+					P50:           getPercentile(sorted, 0.5),  //nolint:mnd
+					P95:           getPercentile(sorted, 0.95), //nolint:mnd
+					Max:           sorted[len(sorted)-1]})
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].HookName != stats[j].HookName {
+			return stats[i].HookName < stats[j].HookName
+		}
+
+		return stats[i].NamespacePath < stats[j].NamespacePath
+	})
+
+	return
+}
+
+// GetHookTimeBudget gets the configured duration budget of a hook.
+// A zero duration means no budget.
+func GetHookTimeBudget(gitx *git.Context) (time.Duration, error) {
+	value := gitx.GetConfig(GitCKHookTimeBudget, git.Traverse)
+	if strs.IsEmpty(value) {
+		return 0, nil
+	}
+
+	budget, err := time.ParseDuration(value)
+	if err != nil || budget < 0 {
+		return 0, cm.ErrorF("Git config '%s' is not a valid duration '%s'.", GitCKHookTimeBudget, value)
+	}
+
+	return budget, nil
+}
+
+// getPercentile gets the percentile `p` (nearest rank) of the sorted durations `sorted`.
+func getPercentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(min(idx, len(sorted)-1), 0)]
+}
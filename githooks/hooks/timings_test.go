@@ -0,0 +1,69 @@
+package hooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookTimings(t *testing.T) {
+	gitDir := t.TempDir()
+
+	timings, err := LoadHookTimings(gitDir)
+	require.NoError(t, err)
+	assert.Empty(t, timings.GetStats())
+
+	hookA := Hook{NamespacePath: "ns:a/pre-commit/a"}
+	hookB := Hook{NamespacePath: "ns:a/pre-commit/b"}
+
+	for i := 1; i <= hookTimingsMaxSamples+10; i++ {
+		timings.Add("pre-commit",
+			HookResult{Hook: &hookA, Duration: time.Duration(i) * time.Millisecond},
+			HookResult{Hook: &hookB, Duration: time.Second})
+	}
+	timings.Add("commit-msg", HookResult{Hook: &hookA, Duration: time.Second})
+	require.NoError(t, timings.Store(gitDir))
+
+	timings, err = LoadHookTimings(gitDir)
+	require.NoError(t, err)
+
+	stats := timings.GetStats()
+	require.Len(t, stats, 3) //nolint:mnd
+
+	assert.Equal(t, "commit-msg", stats[0].HookName)
+	assert.Equal(t, 1, stats[0].Count)
+	assert.Equal(t, time.Second, stats[0].P95)
+
+	// Only the last durations 11ms...60ms are kept.
+	a := stats[1]
+	assert.Equal(t, "pre-commit", a.HookName)
+	assert.Equal(t, "ns:a/pre-commit/a", a.NamespacePath)
+	assert.Equal(t, hookTimingsMaxSamples, a.Count)
+	assert.Equal(t, 35*time.Millisecond, a.P50)
+	assert.Equal(t, 58*time.Millisecond, a.P95)
+	assert.Equal(t, 60*time.Millisecond, a.Max)
+
+	assert.Equal(t, "ns:a/pre-commit/b", stats[2].NamespacePath)
+	assert.Equal(t, time.Second, stats[2].P50)
+}
+
+func TestHookTimeBudget(t *testing.T) {
+	_, gitx := newTestRepo(t)
+
+	budget, err := GetHookTimeBudget(gitx)
+	require.NoError(t, err)
+	assert.Zero(t, budget)
+
+	require.NoError(t, gitx.SetConfig(GitCKHookTimeBudget, "1.5s", git.LocalScope))
+	budget, err = GetHookTimeBudget(gitx)
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, budget)
+
+	require.NoError(t, gitx.SetConfig(GitCKHookTimeBudget, "fast", git.LocalScope))
+	_, err = GetHookTimeBudget(gitx)
+	assert.Error(t, err)
+}
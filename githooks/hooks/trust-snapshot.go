@@ -0,0 +1,122 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// The directory inside the checksum directory with the snapshots
+// of the last trusted content of each hook.
+const snapshotsDirName = "snapshots"
+
+// getSnapshotFile gets the snapshot file of the hook `filePath`
+// in the checksum directory `checksumDir`.
+func getSnapshotFile(checksumDir string, filePath string) (string, error) {
+	key, err := cm.GetSHA1Hash(strings.NewReader(filepath.ToSlash(filePath)))
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(checksumDir, snapshotsDirName, key[0:2], key[2:]), nil
+}
+
+// storeSnapshot stores the current content of the hook `filePath`
+// as its last trusted content.
+func (t *ChecksumStore) storeSnapshot(filePath string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not read hook '%s'.", filePath))
+	}
+
+	file, err := getSnapshotFile(t.checksumDir, filePath)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(path.Dir(file), cm.DefaultFileModeDirectory); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, content, cm.DefaultFileModeFile)
+}
+
+// GetSnapshot gets the last trusted content of the hook `filePath`.
+// The content is stored when the hook is trusted.
+func (t *ChecksumStore) GetSnapshot(filePath string) (content []byte, exists bool, err error) {
+	for _, checksumDir := range t.getSearchDirectories() {
+		var file string
+		file, err = getSnapshotFile(checksumDir, filePath)
+		if err != nil {
+			return
+		} else if !cm.IsFile(file) {
+			continue
+		}
+
+		content, err = os.ReadFile(file)
+
+		return content, err == nil, err
+	}
+
+	return
+}
+
+// GetTrustDiff gets the unified diff between the last trusted content
+// of the hook `filePath` and its current content.
+// The diff is empty if the content did not change.
+func (t *ChecksumStore) GetTrustDiff(filePath string) (diff string, hasSnapshot bool, err error) {
+	snapshot, hasSnapshot, err := t.GetSnapshot(filePath)
+	if err != nil || !hasSnapshot {
+		return
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", true, cm.CombineErrors(err, cm.ErrorF("Could not read hook '%s'.", filePath))
+	}
+
+	diff, err = GetUnifiedDiff(snapshot, content, "trusted", "current")
+
+	return diff, true, err
+}
+
+// GetUnifiedDiff gets the unified diff between content `a` and `b`
+// named `nameA` and `nameB`.
+func GetUnifiedDiff(a []byte, b []byte, nameA string, nameB string) (string, error) {
+	isBinary := func(c []byte) bool { return strings.IndexByte(string(c), 0) >= 0 }
+
+	if isBinary(a) || isBinary(b) {
+		if string(a) == string(b) {
+			return "", nil
+		}
+
+		return strs.Fmt("Binary files '%s' and '%s' differ.\n", nameA, nameB), nil
+	}
+
+	return difflib.GetUnifiedDiffString(
+		difflib.UnifiedDiff{
+			A:        splitDiffLines(a),
+			B:        splitDiffLines(b),
+			FromFile: nameA,
+			ToFile:   nameB,
+			Context:  3}) //nolint:mnd
+}
+
+// splitDiffLines splits `content` into lines ending with a newline.
+func splitDiffLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+
+	if last := len(lines) - 1; strs.IsEmpty(lines[last]) {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+
+	return lines
+}
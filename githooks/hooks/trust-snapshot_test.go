@@ -0,0 +1,66 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	hook := path.Join(dir, "pre-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho a\nexit 0\n"), 0700)) //nolint:mnd
+
+	store, err := GetChecksumStorage(path.Join(dir, "git"))
+	require.NoError(t, err)
+
+	// Never trusted.
+	_, hasSnapshot, err := store.GetTrustDiff(hook)
+	require.NoError(t, err)
+	assert.False(t, hasSnapshot)
+
+	sha, err := cm.GetSHA1HashFile(hook)
+	require.NoError(t, err)
+	require.NoError(t, store.SyncChecksumAdd(ChecksumResult{SHA1: sha, Path: hook}))
+
+	diff, hasSnapshot, err := store.GetTrustDiff(hook)
+	require.NoError(t, err)
+	assert.True(t, hasSnapshot)
+	assert.Empty(t, diff)
+
+	// Changed hook.
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho b\nexit 0\n"), 0700)) //nolint:mnd
+	trusted, _, err := store.IsTrusted(hook, "")
+	require.NoError(t, err)
+	assert.False(t, trusted)
+
+	diff, hasSnapshot, err = store.GetTrustDiff(hook)
+	require.NoError(t, err)
+	assert.True(t, hasSnapshot)
+	assert.Equal(t,
+		"--- trusted\n+++ current\n@@ -1,3 +1,3 @@\n #!/bin/sh\n-echo a\n+echo b\n exit 0\n",
+		diff)
+
+	// Untrusting keeps the snapshot.
+	removed, err := store.SyncChecksumRemove(sha)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, hasSnapshot, err = store.GetTrustDiff(hook)
+	require.NoError(t, err)
+	assert.True(t, hasSnapshot)
+}
+
+func TestUnifiedDiffBinary(t *testing.T) {
+	diff, err := GetUnifiedDiff([]byte("a\x00"), []byte("b\x00"), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "Binary files 'a' and 'b' differ.\n", diff)
+
+	diff, err = GetUnifiedDiff([]byte("a\x00"), []byte("a\x00"), "a", "b")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	cm "github.com/gabyx/githooks/githooks/common"
 	"github.com/gabyx/githooks/githooks/git"
@@ -103,6 +104,18 @@ type ChecksumStore struct {
 	// with file name equal to the checksum.
 	checksumDir string
 
+	// layerDirs are additional checksum directories which are only
+	// searched but never written to (e.g. the per-worktree store
+	// overriding a shared store).
+	layerDirs []string
+
+	// untrustedDir is the directory of the worktree's untrusted checksums
+	// which override all trusted checksums of the layers (if any).
+	untrustedDir string
+	// storeUntrusted tells if removed checksums which are still trusted
+	// in any layer are stored in `untrustedDir`.
+	storeUntrusted bool
+
 	// Checksums are the checksums manually added to this store
 	checksums map[string]ChecksumData
 }
@@ -125,6 +138,32 @@ func (t *ChecksumStore) SetSearchDirectory(path string) {
 	t.checksumDir = path
 }
 
+// AddLayerDirectory adds a checksum directory `path` which is
+// additionally searched (read-only).
+func (t *ChecksumStore) AddLayerDirectory(path string) {
+	if path != t.checksumDir && !strs.Includes(t.layerDirs, path) {
+		t.layerDirs = append(t.layerDirs, path)
+	}
+}
+
+// SetUntrustedDirectory sets the directory `path` of untrusted checksums
+// which override the trusted checksums of all layers.
+// If `store` is set, removing a checksum which is still trusted
+// in any layer stores it in this directory.
+func (t *ChecksumStore) SetUntrustedDirectory(path string, store bool) {
+	t.untrustedDir = path
+	t.storeUntrusted = store
+}
+
+// getSearchDirectories gets all directories which are searched for checksums.
+func (t *ChecksumStore) getSearchDirectories() []string {
+	if strs.IsEmpty(t.checksumDir) {
+		return t.layerDirs
+	}
+
+	return append([]string{t.checksumDir}, t.layerDirs...)
+}
+
 func (t *ChecksumStore) assertData() {
 	if t.checksums == nil {
 		t.checksums = make(map[string]ChecksumData)
@@ -146,7 +185,8 @@ func (t *ChecksumStore) AddChecksum(sha1 string, filePath string) bool {
 	return false
 }
 
-// SyncChecksumAdd adds SHA1 checksums of a path to the search directory.
+// SyncChecksumAdd adds SHA1 checksums of a path to the search directory
+// and stores the content of the path as its last trusted snapshot.
 func (t *ChecksumStore) SyncChecksumAdd(checksums ...ChecksumResult) error {
 	if strs.IsEmpty(t.checksumDir) {
 		return cm.Error("No checksum directory.")
@@ -171,6 +211,21 @@ func (t *ChecksumStore) SyncChecksumAdd(checksums ...ChecksumResult) error {
 		if err != nil {
 			return err
 		}
+
+		// Trusting again undoes an untrust of the worktree.
+		if strs.IsNotEmpty(t.untrustedDir) {
+			err = os.Remove(getChecksumFile(t.untrustedDir, checksum.SHA1))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		// Keep the trusted content to show the changes on the next trust prompt.
+		if cm.IsFile(checksum.Path) {
+			if err = t.storeSnapshot(checksum.Path); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -178,6 +233,8 @@ func (t *ChecksumStore) SyncChecksumAdd(checksums ...ChecksumResult) error {
 
 // SyncChecksumRemove removes SHA1 checksums
 // of a path from the search directory.
+// Checksums which are still trusted in any layer directory are
+// stored as untrusted if enabled (see `SetUntrustedDirectory`).
 func (t *ChecksumStore) SyncChecksumRemove(sha1s ...string) (removed int, err error) {
 	if strs.IsEmpty(t.checksumDir) {
 		err = cm.Error("No checksum directory.")
@@ -188,9 +245,7 @@ func (t *ChecksumStore) SyncChecksumRemove(sha1s ...string) (removed int, err er
 	for _, sha1 := range sha1s {
 		cm.DebugAssertF(len(sha1) == 40, "Wrong SHA1 hash '%s'", sha1) //nolint:mnd
 
-		dir := path.Join(t.checksumDir, sha1[0:2])
-		file := path.Join(dir, sha1[2:])
-
+		file := getChecksumFile(t.checksumDir, sha1)
 		if cm.IsFile(file) {
 			if err = os.Remove(file); err != nil {
 				return
@@ -198,23 +253,69 @@ func (t *ChecksumStore) SyncChecksumRemove(sha1s ...string) (removed int, err er
 
 			removed++
 		}
+
+		if !t.storeUntrusted {
+			continue
+		}
+
+		for _, checksumDir := range t.layerDirs {
+			if !cm.IsFile(getChecksumFile(checksumDir, sha1)) {
+				continue
+			}
+
+			if err = cm.TouchFile(getChecksumFile(t.untrustedDir, sha1), true); err != nil {
+				return
+			}
+
+			removed++
+
+			break
+		}
 	}
 
 	return
 }
 
-// IsTrusted checks if a path has been trusted.
-func (t *ChecksumStore) IsTrusted(filePath string) (bool, string, error) {
-	sha1, err := cm.GetSHA1HashFile(filePath)
+// getChecksumFile gets the file of checksum `sha1` in the checksum directory `dir`.
+func getChecksumFile(dir string, sha1 string) string {
+	return path.Join(dir, sha1[0:2], sha1[2:])
+}
+
+// GetHookSHA1 gets the SHA1 hash of the hook `hookPath` which
+// also covers the interpreter or environment override `override` (if any),
+// such that changing the interpreter or the environment manifest
+// of a trusted hook needs a new trust.
+func GetHookSHA1(hookPath string, override string) (string, error) {
+	sha1, err := cm.GetSHA1HashFile(hookPath)
+	if err != nil || strs.IsEmpty(override) {
+		return sha1, err
+	}
+
+	return cm.GetSHA1Hash(strings.NewReader(sha1 + "\n" + override))
+}
+
+// IsTrusted checks if a path together with the
+// override `override` (if any, see `GetHookSHA1`) has been trusted.
+func (t *ChecksumStore) IsTrusted(filePath string, override string) (bool, string, error) {
+	sha1, err := GetHookSHA1(filePath, override)
 	if err != nil {
 		return false, "",
 			cm.CombineErrors(cm.ErrorF("Could not get hash for '%s'", filePath), err)
 	}
 
-	// Check first search directory ...
-	if strs.IsNotEmpty(t.checksumDir) {
-		bucket := sha1[0:2]
-		exists, e := cm.IsPathExisting(path.Join(t.checksumDir, bucket, sha1[2:]))
+	// Check first all checksums (trusted during this run) ...
+	if _, ok := t.checksums[sha1]; ok {
+		return true, sha1, nil
+	}
+
+	// The worktree's untrusted checksums override all search directories ...
+	if strs.IsNotEmpty(t.untrustedDir) && cm.IsFile(getChecksumFile(t.untrustedDir, sha1)) {
+		return false, sha1, nil
+	}
+
+	// Check all search directories ...
+	for _, checksumDir := range t.getSearchDirectories() {
+		exists, e := cm.IsPathExisting(getChecksumFile(checksumDir, sha1))
 		if exists {
 			return true, sha1, nil
 		} else if e != nil {
@@ -222,12 +323,6 @@ func (t *ChecksumStore) IsTrusted(filePath string) (bool, string, error) {
 		}
 	}
 
-	// Check all checksums ...
-	_, ok := t.checksums[sha1]
-	if ok {
-		return true, sha1, nil
-	}
-
 	return false, sha1, nil
 }
 
@@ -235,9 +330,9 @@ func (t *ChecksumStore) IsTrusted(filePath string) (bool, string, error) {
 func (t *ChecksumStore) Summary() string {
 	return strs.Fmt(
 		"Checksum store contains '%v' checksums\n"+
-			"and directory search path '%v'.",
+			"and directory search paths '%q'.",
 		len(t.checksums),
-		t.checksumDir)
+		t.getSearchDirectories())
 }
 
 // GetChecksumDirectoryGitDir gets the checksum file inside the Git directory.
@@ -245,10 +340,38 @@ func GetChecksumDirectoryGitDir(gitDir string) string {
 	return path.Join(gitDir, ".githooks.checksums")
 }
 
+// GetUntrustedDirectoryGitDir gets the directory of the untrusted checksums
+// inside the worktree's Git directory `gitDir`.
+func GetUntrustedDirectoryGitDir(gitDir string) string {
+	return path.Join(gitDir, ".githooks.untrusted")
+}
+
 // GetChecksumStorage loads the checksum store from the
-// current Git directory.
-func GetChecksumStorage(gitDirWorktree string) (store ChecksumStore, err error) {
-	cacheDir := GetChecksumDirectoryGitDir(gitDirWorktree)
+// current Git directory `gitDir`. The checksum stores in
+// `layerGitDirs` are additionally searched (read-only).
+func GetChecksumStorage(gitDir string, layerGitDirs ...string) (store ChecksumStore, err error) {
+	cacheDir, err := getChecksumDirectory(gitDir)
+	if err != nil {
+		return
+	}
+
+	store.SetSearchDirectory(cacheDir)
+
+	for _, dir := range layerGitDirs {
+		if cacheDir, err = getChecksumDirectory(dir); err != nil {
+			return
+		}
+
+		store.AddLayerDirectory(cacheDir)
+	}
+
+	return
+}
+
+// getChecksumDirectory gets the checksum directory inside the Git directory
+// and resolves it if its a symbolic link.
+func getChecksumDirectory(gitDir string) (cacheDir string, err error) {
+	cacheDir = GetChecksumDirectoryGitDir(gitDir)
 
 	fi, e := os.Lstat(cacheDir)
 
@@ -265,7 +388,5 @@ func GetChecksumStorage(gitDirWorktree string) (store ChecksumStore, err error)
 		}
 	}
 
-	store.SetSearchDirectory(cacheDir)
-
 	return
 }
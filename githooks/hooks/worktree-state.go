@@ -0,0 +1,235 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// UserStateDirs are the Git directories containing the user's hook state
+// (trusted checksums and user ignore patterns) of a worktree.
+type UserStateDirs struct {
+	// The Git directory where new state is stored.
+	Store string
+
+	// All Git directories where state is loaded from, in increasing priority.
+	// The last one is always the worktree's Git directory which overrides the others.
+	Layers []string
+}
+
+// GetSharedStateDir gets the directory inside the common Git directory `gitDir`
+// which contains the user's hook state shared by all worktrees.
+func GetSharedStateDir(gitDir string) string {
+	return path.Join(gitDir, ".githooks.shared")
+}
+
+// SetShareWorktreeState sets the settings if the user's hook state
+// is shared by all worktrees.
+func SetShareWorktreeState(
+	gitx *git.Context,
+	enable bool,
+	reset bool,
+	scope git.ConfigScope,
+) error {
+	switch {
+	case reset:
+		return gitx.UnsetConfig(GitCKShareWorktreeState, scope)
+	default:
+		return gitx.SetConfig(GitCKShareWorktreeState, enable, scope)
+	}
+}
+
+// IsWorktreeStateShared gets the settings if the user's hook state
+// is shared by all worktrees.
+func IsWorktreeStateShared(gitx *git.Context, scope git.ConfigScope) (enabled bool, isSet bool) {
+	conf := gitx.GetConfig(GitCKShareWorktreeState, scope)
+
+	switch {
+	case strs.IsEmpty(conf) || conf == git.GitCVFalse:
+		return
+	default:
+		return conf == git.GitCVTrue, true
+	}
+}
+
+// GetUserStateDirs gets the directories with the user's hook state for the
+// worktree with Git directory `gitDirWorktree` in the repository with common
+// Git directory `gitDir`.
+// If the state is shared, new state goes to the shared directory
+// unless `worktreeOnly` is set, which stores it in the worktree's override layer.
+func GetUserStateDirs(
+	gitx *git.Context,
+	gitDir string,
+	gitDirWorktree string,
+	worktreeOnly bool) (dirs UserStateDirs) {
+	if shared, _ := IsWorktreeStateShared(gitx, git.Traverse); !shared {
+		return UserStateDirs{Store: gitDirWorktree, Layers: []string{gitDirWorktree}}
+	}
+
+	sharedDir := GetSharedStateDir(gitDir)
+	dirs.Layers = []string{sharedDir, gitDirWorktree}
+
+	if worktreeOnly {
+		dirs.Store = gitDirWorktree
+	} else {
+		dirs.Store = sharedDir
+	}
+
+	return
+}
+
+// GetUserChecksumStorage loads the checksum store with the user's trusted
+// checksums in the state directories `dirs`.
+// If the state is shared, the worktree's untrusted checksums override
+// the trusted checksums of all layers and removing a checksum
+// in the worktree's layer untrusts it for this worktree only.
+func GetUserChecksumStorage(dirs UserStateDirs) (store ChecksumStore, err error) {
+	store, err = GetChecksumStorage(dirs.Store, dirs.Layers...)
+	if err != nil || len(dirs.Layers) <= 1 {
+		return
+	}
+
+	worktree := dirs.Layers[len(dirs.Layers)-1]
+	store.SetUntrustedDirectory(GetUntrustedDirectoryGitDir(worktree), dirs.Store == worktree)
+
+	return
+}
+
+// GetWorktreeGitDirs gets the Git directories of all worktrees
+// in the repository with common Git directory `gitDir`.
+func GetWorktreeGitDirs(gitDir string) (dirs []string, err error) {
+	dirs = append(dirs, gitDir)
+
+	worktreesDir := path.Join(gitDir, "worktrees")
+	if !cm.IsDirectory(worktreesDir) {
+		return
+	}
+
+	entries, err := os.ReadDir(worktreesDir)
+	if err != nil {
+		return nil, cm.CombineErrors(err, cm.ErrorF("Could not read '%s'.", worktreesDir))
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, path.Join(worktreesDir, e.Name()))
+		}
+	}
+
+	return
+}
+
+// MigrateWorktreeStates merges the user's hook state (trusted checksums and
+// user ignore patterns) of all worktrees of the repository with common Git
+// directory `gitDir` into the shared state directory.
+// If `clean` is set, the merged per-worktree state is removed.
+// It returns the number of merged checksum files and ignore patterns.
+func MigrateWorktreeStates(
+	gitDir string,
+	clean bool) (checksums int, patterns int, err error) {
+	worktrees, err := GetWorktreeGitDirs(gitDir)
+	if err != nil {
+		return
+	}
+
+	sharedDir := GetSharedStateDir(gitDir)
+	sharedChecksumDir, err := getChecksumDirectory(sharedDir)
+	if err != nil {
+		return
+	}
+
+	var sharedPatterns HookPatterns
+	sharedIgnoreFile := GetHookIgnoreFileGitDir(sharedDir)
+	if cm.IsFile(sharedIgnoreFile) {
+		if sharedPatterns, err = LoadIgnorePatterns(sharedIgnoreFile); err != nil {
+			return
+		}
+	}
+
+	for _, worktree := range worktrees {
+		checksumDir, e := getChecksumDirectory(worktree)
+		if e != nil {
+			err = cm.CombineErrors(err, e)
+
+			continue
+		}
+
+		if cm.IsDirectory(checksumDir) {
+			n, e := mergeChecksumDirectory(checksumDir, sharedChecksumDir)
+			checksums += n
+			err = cm.CombineErrors(err, e)
+
+			if e == nil && clean {
+				err = cm.CombineErrors(err, os.RemoveAll(GetChecksumDirectoryGitDir(worktree)))
+			}
+		}
+
+		ignoreFile := GetHookIgnoreFileGitDir(worktree)
+		if cm.IsFile(ignoreFile) {
+			ps, e := LoadIgnorePatterns(ignoreFile)
+			if e != nil {
+				err = cm.CombineErrors(err, e)
+
+				continue
+			}
+
+			patterns += sharedPatterns.AddUnique(&ps)
+
+			if clean {
+				err = cm.CombineErrors(err, os.Remove(ignoreFile))
+			}
+		}
+	}
+
+	if patterns != 0 {
+		err = cm.CombineErrors(err, StoreHookPatternsGitDir(sharedPatterns, sharedDir))
+	}
+
+	return checksums, patterns, err
+}
+
+// mergeChecksumDirectory copies all checksum files and trust snapshots
+// in `srcDir` which do not exist in `dstDir`.
+// It returns the number of copied checksum files.
+func mergeChecksumDirectory(srcDir string, dstDir string) (merged int, err error) {
+	err = cm.WalkPaths(srcDir, func(p string, info os.FileInfo) error {
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, e := filepath.Rel(srcDir, p)
+		if e != nil {
+			return e
+		}
+
+		dst := path.Join(dstDir, filepath.ToSlash(rel))
+		if cm.IsFile(dst) {
+			return nil
+		}
+
+		if e = os.MkdirAll(path.Dir(dst), cm.DefaultFileModeDirectory); e != nil {
+			return e
+		}
+
+		if e = cm.CopyFileOrDirectory(p, dst); e != nil {
+			return e
+		}
+
+		if path.Base(path.Dir(path.Dir(dst))) != snapshotsDirName {
+			merged++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		err = cm.CombineErrors(err,
+			cm.ErrorF("Could not merge checksums from '%s' into '%s'.", srcDir, dstDir))
+	}
+
+	return
+}
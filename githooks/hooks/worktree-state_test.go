@@ -0,0 +1,180 @@
+package hooks
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserStateDirs(t *testing.T) {
+	repo, gitx := newTestRepo(t)
+
+	gitDir := path.Join(repo, ".git")
+	worktree := path.Join(gitDir, "worktrees", "feature")
+
+	dirs := GetUserStateDirs(gitx, gitDir, worktree, false)
+	assert.Equal(t, UserStateDirs{Store: worktree, Layers: []string{worktree}}, dirs)
+
+	require.NoError(t, SetShareWorktreeState(gitx, true, false, git.LocalScope))
+
+	shared := GetSharedStateDir(gitDir)
+	dirs = GetUserStateDirs(gitx, gitDir, worktree, false)
+	assert.Equal(t, UserStateDirs{Store: shared, Layers: []string{shared, worktree}}, dirs)
+
+	dirs = GetUserStateDirs(gitx, gitDir, worktree, true)
+	assert.Equal(t, UserStateDirs{Store: worktree, Layers: []string{shared, worktree}}, dirs)
+}
+
+func TestLayeredUserState(t *testing.T) {
+	dir := t.TempDir()
+	shared := path.Join(dir, "shared")
+	worktree := path.Join(dir, "worktree")
+
+	hook := path.Join(dir, "pre-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\necho a\n"), 0700)) //nolint:mnd
+	sha, err := cm.GetSHA1HashFile(hook)
+	require.NoError(t, err)
+
+	// Trusted in the worktree layer only.
+	store, err := GetChecksumStorage(worktree)
+	require.NoError(t, err)
+	require.NoError(t, store.SyncChecksumAdd(ChecksumResult{SHA1: sha, Path: hook}))
+
+	store, err = GetChecksumStorage(shared, shared, worktree)
+	require.NoError(t, err)
+	trusted, _, err := store.IsTrusted(hook, "")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	_, hasSnapshot, err := store.GetSnapshot(hook)
+	require.NoError(t, err)
+	assert.True(t, hasSnapshot)
+
+	// Untrusting only removes it from the store.
+	removed, err := store.SyncChecksumRemove(sha)
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	trusted, _, err = store.IsTrusted(hook, "")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	// Untrusting in the worktree overrides the shared trust.
+	sharedDirs := UserStateDirs{Store: shared, Layers: []string{shared, worktree}}
+	worktreeDirs := UserStateDirs{Store: worktree, Layers: []string{shared, worktree}}
+	otherDirs := UserStateDirs{Store: shared, Layers: []string{shared, path.Join(dir, "other")}}
+
+	isTrusted := func(dirs UserStateDirs) bool {
+		s, e := GetUserChecksumStorage(dirs)
+		require.NoError(t, e)
+		trusted, _, e := s.IsTrusted(hook, "")
+		require.NoError(t, e)
+
+		return trusted
+	}
+
+	store, err = GetUserChecksumStorage(sharedDirs)
+	require.NoError(t, err)
+	require.NoError(t, store.SyncChecksumAdd(ChecksumResult{SHA1: sha, Path: hook}))
+
+	store, err = GetUserChecksumStorage(worktreeDirs)
+	require.NoError(t, err)
+	removed, err = store.SyncChecksumRemove(sha)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed) //nolint:mnd
+
+	assert.False(t, isTrusted(worktreeDirs))
+	assert.False(t, isTrusted(sharedDirs))
+	assert.True(t, isTrusted(otherDirs), "Other worktrees keep the shared trust.")
+
+	// Trusting again in the worktree undoes the untrust.
+	require.NoError(t, store.SyncChecksumAdd(ChecksumResult{SHA1: sha, Path: hook}))
+	assert.True(t, isTrusted(sharedDirs))
+
+	// The worktree layer overrides shared ignores with inverted patterns.
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{Patterns: []string{"**/pre-commit/*"}}, shared))
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{Patterns: []string{"!**/pre-commit/a.sh"}}, worktree))
+
+	ignores, err := GetIgnorePatterns(dir, []string{shared, worktree}, nil, "ns")
+	require.NoError(t, err)
+	assert.True(t, ignores.User.Matches("ns:ns/pre-commit/b.sh"))
+	assert.False(t, ignores.User.Matches("ns:ns/pre-commit/a.sh"))
+
+	// Inverted namespace paths override shared namespace paths and patterns.
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{
+			Patterns:       []string{"**/pre-commit/*"},
+			NamespacePaths: []string{"ns:ns/pre-commit/c.sh"}}, shared))
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{NamespacePaths: []string{"!ns:ns/pre-commit/b.sh", "!ns:ns/pre-commit/c.sh"}}, worktree))
+
+	ignores, err = GetIgnorePatterns(dir, []string{shared, worktree}, nil, "ns")
+	require.NoError(t, err)
+	assert.False(t, ignores.User.Matches("ns:ns/pre-commit/b.sh"))
+	assert.False(t, ignores.User.Matches("ns:ns/pre-commit/c.sh"))
+	assert.True(t, ignores.User.Matches("ns:ns/pre-commit/d.sh"))
+}
+
+func TestMigrateWorktreeStates(t *testing.T) {
+	gitDir := t.TempDir()
+	worktree := path.Join(gitDir, "worktrees", "feature")
+	require.NoError(t, os.MkdirAll(worktree, 0700)) //nolint:mnd
+
+	hookA := path.Join(gitDir, "a.sh")
+	hookB := path.Join(gitDir, "b.sh")
+	require.NoError(t, os.WriteFile(hookA, []byte("a"), 0700)) //nolint:mnd
+	require.NoError(t, os.WriteFile(hookB, []byte("b"), 0700)) //nolint:mnd
+	shaA, err := cm.GetSHA1HashFile(hookA)
+	require.NoError(t, err)
+	shaB, err := cm.GetSHA1HashFile(hookB)
+	require.NoError(t, err)
+
+	for _, s := range []struct {
+		dir  string
+		sha  string
+		hook string
+	}{{gitDir, shaA, hookA}, {worktree, shaA, hookA}, {worktree, shaB, hookB}} {
+		store, e := GetChecksumStorage(s.dir)
+		require.NoError(t, e)
+		require.NoError(t, store.SyncChecksumAdd(ChecksumResult{SHA1: s.sha, Path: s.hook}))
+	}
+
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{NamespacePaths: []string{"ns:a/a.sh"}}, gitDir))
+	require.NoError(t, StoreHookPatternsGitDir(
+		HookPatterns{NamespacePaths: []string{"ns:a/a.sh", "ns:a/b.sh"}}, worktree))
+
+	checksums, patterns, err := MigrateWorktreeStates(gitDir, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, checksums) //nolint:mnd
+	assert.Equal(t, 2, patterns)  //nolint:mnd
+
+	shared := GetSharedStateDir(gitDir)
+	store, err := GetChecksumStorage(shared)
+	require.NoError(t, err)
+	for _, hook := range []string{hookA, hookB} {
+		trusted, _, e := store.IsTrusted(hook, "")
+		require.NoError(t, e)
+		assert.True(t, trusted)
+	}
+
+	ps, err := LoadIgnorePatterns(GetHookIgnoreFileGitDir(shared))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ns:a/a.sh", "ns:a/b.sh"}, ps.NamespacePaths)
+
+	assert.False(t, cm.IsDirectory(GetChecksumDirectoryGitDir(worktree)))
+	assert.False(t, cm.IsFile(GetHookIgnoreFileGitDir(gitDir)))
+
+	// Migrating again merges nothing.
+	checksums, patterns, err = MigrateWorktreeStates(gitDir, false)
+	require.NoError(t, err)
+	assert.Zero(t, checksums)
+	assert.Zero(t, patterns)
+}
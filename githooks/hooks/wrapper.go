@@ -1,6 +1,7 @@
 package hooks
 
 import (
+	"bytes"
 	"os"
 	"path"
 	"regexp"
@@ -21,6 +22,22 @@ func IsRunWrapper(filePath string) (bool, error) {
 	return cm.MatchLineRegexInFile(filePath, runWrapperDetectionRegex)
 }
 
+// IsRunWrapperUpToDate answers the question if the run-wrapper `filePath`
+// has the same content as the run-wrapper of this build.
+func IsRunWrapperUpToDate(filePath string) (bool, error) {
+	runWrapperContent, err := getRunWrapperContent()
+	if err != nil {
+		return false, err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(content, runWrapperContent), nil
+}
+
 // GetHookReplacementFileName returns the file name of a replaced custom Git hook.
 func GetHookReplacementFileName(fileName string) string {
 	return path.Base(fileName) + ".replaced.githook"
@@ -338,7 +355,7 @@ func InstallRunWrappers(
 // UninstallRunWrappers deletes run-wrappers in `dir`.
 // Existing replaced hooks get renamed.
 func UninstallRunWrappers(dir string, lfsHooksCache LFSHooksCache) (int, error) {
-	return uninstallRunWrappers(ManagedHookNames, dir, lfsHooksCache)
+	return uninstallRunWrappers(SupportedHookNames, dir, lfsHooksCache)
 }
 
 func uninstallRunWrappers(
@@ -0,0 +1,1705 @@
+// Package runner executes all hooks of a Git hook invocation.
+package runner
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabyx/githooks/githooks/build"
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	"github.com/gabyx/githooks/githooks/prompt"
+	strs "github.com/gabyx/githooks/githooks/strings"
+	"github.com/gabyx/githooks/githooks/updates"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pbenner/threadpool"
+	"github.com/pkg/math"
+)
+
+// Options are the options to run all hooks of a Git hook invocation.
+type Options struct {
+	HookPath string   // The path of the hook (the run-wrapper) Git invoked.
+	Args     []string // The arguments Git passed to the hook.
+
+	// Simulate the invocation of the hook, e.g. from the CLI.
+	// Nothing is done except running the hooks:
+	// no registering, no update checks, no shared hooks updates,
+	// no image updates and no Git LFS hooks.
+	Simulate bool
+
+	// Only show which hooks would run.
+	DryRun bool
+
+	// The standard input passed to each hook.
+	// If `nil`, the standard input of this process is used.
+	Stdin []byte
+
+	// The staged files (delimited by `\x00`) exported to the hooks.
+	// If `nil`, the staged files in the index are used.
+	StagedFiles *string
+
+	// Force a non-interactive run which skips active, untrusted hooks,
+	// e.g. in CI.
+	NonInteractive     bool
+	SkipUntrustedHooks bool
+}
+
+// Run runs all hooks for the hook invocation in `opts` in the
+// repository `repoPath`.
+func Run(log cm.ILogContext, repoPath string, opts *Options) {
+	settings, uiSettings := setupSettings(log, repoPath, opts)
+
+	if settings.HookName != "reference-transaction" && !opts.Simulate {
+		// Git 2.46.x apparently runs `reference-transaction` on `git init`
+		// where different commands fail like `git config ...` since
+		// the repo is not yet properly initialized (?).
+		assertRegistered(log, settings.GitX, settings.InstallDir)
+	}
+
+	checksums, err := hooks.GetUserChecksumStorage(settings.UserStateDirs)
+	log.AssertNoErrorF(err, "Errors while loading checksum store.")
+	log.DebugF("%s", checksums.Summary())
+
+	// Set this repositories hook namespace.
+	ns, err := hooks.GetHooksNamespace(settings.RepositoryHooksDir)
+	log.AssertNoErrorF(err, "Errors while loading hook namespace.")
+	if strs.IsNotEmpty(ns) {
+		settings.HookNamespace = ns
+	}
+
+	ignores, err := hooks.GetIgnorePatterns(
+		settings.RepositoryHooksDir,
+		settings.UserStateDirs.Layers,
+		[]string{settings.HookName},
+		settings.HookNamespace)
+	log.AssertNoErrorF(err, "Errors while loading ignore patterns.")
+	log.DebugF("User ignore patterns: '%+q'.", ignores.User)
+	log.DebugF("Accumuldated repository ignore patterns: '%q'.", ignores.HooksDir)
+
+	defer storePendingData(&settings, &uiSettings, &ignores, &checksums)
+
+	bufferStdin(&settings)
+
+	if settings.Disabled {
+		// Githooks is disabled, run minimal stuff.
+		executeLFSHooks(&settings)
+		executeOldHook(&settings, &uiSettings, &ignores, &checksums)
+
+		return
+	}
+
+	exportGeneralVars(&settings)
+
+	cleanUp := exportStagedFiles(&settings)
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+
+	cleanUp = exportRefUpdates(&settings)
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+
+	cleanUp = exportPushUpdates(&settings)
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+
+	assertContainerManager(&settings)
+
+	if hooks.IsProtocolHookName(settings.HookName) {
+		// No updates or other output which could disturb the protocol with Git.
+		executeProtocolHook(&settings, &uiSettings, &ignores, &checksums)
+		uiSettings.PromptCtx.Close()
+
+		return
+	}
+
+	updateGithooks(&settings, &uiSettings)
+	executeLFSHooks(&settings)
+	executeOldHook(&settings, &uiSettings, &ignores, &checksums)
+	updateLocalHookImages(&settings)
+
+	hooks := collectHooks(&settings, &uiSettings, &ignores, &checksums)
+
+	cleanUp = exportPushedFiles(&settings, &hooks)
+	if cleanUp != nil {
+		defer cleanUp()
+	}
+
+	if settings.DryRun {
+		logExecutionPlan(&settings, &hooks)
+	} else {
+		executeHooks(&settings, &hooks)
+	}
+
+	uiSettings.PromptCtx.Close()
+	log.Debug("All done.\n")
+}
+
+func logInvocation(s *HookSettings) {
+	t := os.Getenv("GITHOOKS_RUNNER_TRACE")
+	if strs.IsNotEmpty(t) {
+		s.Log.DebugF("Running hooks for: '%s' %q", s.HookName, s.Args)
+	} else if t == "1" || cm.IsDebug {
+		s.Log.DebugF("Settings:\n%s", s.toString())
+	}
+}
+
+func setupSettings(log cm.ILogContext, repoPath string, opts *Options) (HookSettings, UISettings) {
+	// General execution context, in currenct working dir.
+	execx := cm.ExecContext{Env: os.Environ()}
+
+	// Current git context, in current working dir.
+	gitx := git.NewCtxAt(repoPath)
+	log.AssertNoErrorF(gitx.InitConfigCache(nil),
+		"Could not init git config cache.")
+
+	gitDir, err := gitx.GetGitDirWorktree()
+	log.AssertNoErrorPanic(err, "Could not get git directory.")
+
+	gitDirCommon, err := gitx.GetGitDirCommon()
+	log.AssertNoErrorPanic(err, "Could not get common git directory.")
+
+	if !opts.Simulate {
+		err = hooks.DeleteHookDirTemp(path.Join(gitDir, "hooks"))
+		log.AssertNoErrorF(err, "Could not clean temporary directory in '%s/hooks'.", gitDir)
+	}
+
+	hookPath, err := filepath.Abs(opts.HookPath)
+	cm.AssertNoErrorPanicF(err, "Could not abs. path from '%s'.",
+		opts.HookPath)
+	hookPath = filepath.ToSlash(hookPath)
+
+	installDir := getInstallDir(log, gitx)
+
+	promptx, err := prompt.CreateContext(log, true, false)
+	log.DebugIfF(err != nil, "Prompt setup failed -> using fallback.")
+
+	isGithooksDisabled := hooks.IsGithooksDisabled(gitx, true)
+	nonInteractive := opts.NonInteractive || hooks.IsRunnerNonInteractive(gitx, git.Traverse)
+	skipNonExistingSharedHooks := hooks.SkipNonExistingSharedHooks(gitx, git.Traverse)
+	skipUntrustedHooks, _ := hooks.SkipUntrustedHooks(gitx, git.Traverse)
+	skipUntrustedHooks = skipUntrustedHooks || opts.SkipUntrustedHooks
+
+	serverMode := false
+	if strs.Includes(hooks.ServerModeHookNames[:], path.Base(hookPath)) {
+		serverMode, _ = hooks.IsServerModeEnabled(gitx, git.Traverse)
+	}
+
+	if serverMode {
+		// Nobody can answer prompts on a server and
+		// active, untrusted hooks always reject the push.
+		nonInteractive = true
+		skipUntrustedHooks = false
+	}
+
+	isTrusted, hasTrustFile, trustAllSet := hooks.IsRepoTrusted(gitx, repoPath)
+	if !isTrusted && hasTrustFile && !trustAllSet && !nonInteractive && !isGithooksDisabled {
+		isTrusted = showTrustRepoPrompt(log, gitx, promptx, repoPath)
+	}
+
+	s := HookSettings{
+		Log:                log,
+		Args:               opts.Args,
+		ExecX:              execx,
+		GitX:               gitx,
+		RepositoryDir:      repoPath,
+		RepositoryHooksDir: path.Join(repoPath, hooks.HooksDirName),
+		GitDirWorktree:     gitDir,
+		InstallDir:         installDir,
+		UserStateDirs:      hooks.GetUserStateDirs(gitx, gitDirCommon, gitDir, false),
+
+		HookPath:      hookPath,
+		HookName:      path.Base(hookPath),
+		HookDir:       path.Dir(hookPath),
+		HookNamespace: hooks.NamespaceRepositoryHook,
+		IsRepoTrusted: isTrusted,
+
+		SkipNonExistingSharedHooks: skipNonExistingSharedHooks,
+		SkipUntrustedHooks:         skipUntrustedHooks,
+		NonInteractive:             nonInteractive,
+		Disabled:                   isGithooksDisabled,
+		ServerMode:                 serverMode,
+
+		Simulate:    opts.Simulate,
+		DryRun:      opts.DryRun,
+		Stdin:       opts.Stdin,
+		StagedFiles: opts.StagedFiles}
+
+	s.Conditions = hooks.NewConditionContext(gitx, s.HookName, s.Args)
+
+	logInvocation(&s)
+
+	return s, UISettings{AcceptAllChanges: false, PromptCtx: promptx}
+}
+
+func getInstallDir(log cm.ILogContext, gitx *git.Context) string {
+	installDir := hooks.GetInstallDir(gitx)
+
+	setDefault := func() {
+		usr, err := homedir.Dir()
+		cm.AssertNoErrorPanic(err, "Could not get home directory.")
+		usr = filepath.ToSlash(usr)
+		installDir = path.Join(usr, hooks.HooksDirName)
+	}
+
+	if strs.IsEmpty(installDir) {
+		setDefault()
+	} else if exists, err := cm.IsPathExisting(installDir); !exists {
+		log.AssertNoError(err,
+			"Could not check path '%s'", installDir)
+		log.WarnF(
+			"Githooks installation is corrupt!\n"+
+				"Install directory at '%s' is missing.",
+			installDir)
+
+		setDefault()
+
+		log.WarnF(
+			"Falling back to default directory at '%s'.\n"+
+				"Please run the Githooks install script again to fix it.",
+			installDir)
+	}
+
+	log.Debug(strs.Fmt("Install dir set to: '%s'.", installDir))
+
+	return installDir
+}
+
+func assertRegistered(log cm.ILogContext, gitx *git.Context, installDir string) {
+	if !gitx.IsConfigSet(hooks.GitCKRegistered, git.LocalScope) {
+		gitDir, err := gitx.GetGitDirCommon()
+		log.AssertNoErrorPanicF(err, "Could not get Git common dir.")
+
+		log.DebugF("Register repo '%s'", gitDir)
+		err = hooks.RegisterRepo(gitDir, installDir, true, false)
+		log.AssertNoErrorF(err, "Could not register repo '%s'.", gitDir)
+
+		err = hooks.MarkRepoRegistered(gitx)
+		log.AssertNoErrorF(err, "Could not set register flag in repo '%s'.", gitDir)
+	} else {
+		log.Debug(
+			"Repository already registered.")
+	}
+}
+
+func showTrustRepoPrompt(
+	log cm.ILogContext,
+	gitx *git.Context,
+	promptx prompt.IContext,
+	repoPath string,
+) (isTrusted bool) {
+	question := strs.Fmt(
+		`This repository '%s'
+wants you to trust all current and future hooks without prompting.
+Do you want to allow running every current and future hooks?`, repoPath)
+
+	var answer string
+	answer, err := promptx.ShowOptions(question, "(yes, no)", "y/n", "Yes", "No")
+	log.AssertNoErrorF(err, "Could not get trust prompt answer.")
+	if err != nil {
+		return
+	}
+
+	if answer == "y" {
+		e := hooks.SetTrustAllSetting(gitx, true, false)
+		log.AssertNoErrorF(e, "Could not store trust setting.")
+		isTrusted = true
+	} else {
+		e := hooks.SetTrustAllSetting(gitx, false, false)
+		log.AssertNoErrorF(e, "Could not store trust setting.")
+	}
+
+	return
+}
+
+func exportGeneralVars(settings *HookSettings) {
+	// Here set into global env, for simple env replacement in run command.
+	_ = os.Setenv(hooks.EnvVariableOs, runtime.GOOS)
+	_ = os.Setenv(hooks.EnvVariableArch, runtime.GOARCH)
+
+	settings.ExecX.Env = append(settings.ExecX.Env,
+		strs.Fmt("%s=%s", hooks.EnvVariableOs, runtime.GOOS),
+		strs.Fmt("%s=%s", hooks.EnvVariableArch, runtime.GOARCH))
+}
+
+func assertContainerManager(settings *HookSettings) {
+	var err error
+	settings.ContainerMgr, err = hooks.NewContainerManager(settings.GitX, false, nil)
+
+	settings.Log.AssertNoErrorPanicF(err, "Could not create container manager.")
+}
+
+func exportStagedFiles(settings *HookSettings) (cleanUp func()) {
+	if !strs.Includes(hooks.StagedFilesHookNames[:], settings.HookName) {
+		return nil
+	}
+
+	var files string
+	var err error
+
+	if settings.StagedFiles != nil {
+		files = *settings.StagedFiles
+	} else {
+		files, err = hooks.GetStagedFiles(settings.GitX)
+	}
+
+	settings.StagedFileList = strs.Filter(strings.Split(files, "\x00"), strs.IsNotEmpty)
+
+	if len(files) != 0 {
+		settings.Log.DebugF("Exporting staged files:\n- %s",
+			strings.ReplaceAll(strings.TrimRight(files, "\x00"), "\x00", "\n- "))
+	}
+
+	if settings.Log.AssertNoError(err, "Could not export staged files.") {
+		exportOnlyFile := settings.GitX.GetConfig(
+			hooks.GitCKExportStagedFilesAsFile,
+			git.Traverse) == git.GitCVTrue
+
+		cm.DebugAssertF(
+			func() bool {
+				_, exists := os.LookupEnv(hooks.EnvVariableStagedFiles)
+				return !exists //nolint:nlreturn
+			}(),
+			"Env. variable '%s' already defined.", hooks.EnvVariableStagedFiles)
+
+		if exportOnlyFile {
+			// Create the file inside the `.githooks` directory.
+			// to make it better accessible when running containerized.
+			// If it would be in /tmp we would need to mount this file to the container
+			// as well.
+			file, e := os.CreateTemp(settings.RepositoryHooksDir, ".githooks-staged-files-*")
+			filePath := filepath.ToSlash(file.Name())
+			relPath := path.Join(hooks.HooksDirName, path.Base(filePath))
+
+			// Remove the file on exit.
+			defer func() { _ = file.Close() }()
+			cleanUp = func() {
+				_ = os.Remove(file.Name())
+				_ = os.Unsetenv(hooks.EnvVariableStagedFilesFile)
+			}
+
+			if settings.Log.AssertNoError(e, "Could not open temp file for staged files") {
+				_, ef := file.WriteString(files)
+				settings.Log.AssertNoError(ef, "Could not write staged files to temp file.")
+
+				settings.StagedFilesFile = filePath
+			}
+
+			// Set environment directly.
+			_ = os.Setenv(hooks.EnvVariableStagedFilesFile, relPath)
+			// Set environment also in execution context.
+			settings.ExecX.Env = append(settings.ExecX.Env,
+				strs.Fmt("%s=%s", hooks.EnvVariableStagedFilesFile, relPath))
+		} else {
+			files = strings.ReplaceAll(files, "\x00", "\n")
+
+			// Set environment directly.
+			_ = os.Setenv(hooks.EnvVariableStagedFiles, files)
+			cleanUp = func() { _ = os.Unsetenv(hooks.EnvVariableStagedFiles) }
+			// Set environment also in execution context.
+			settings.ExecX.Env = append(settings.ExecX.Env,
+				strs.Fmt("%s=%s", hooks.EnvVariableStagedFiles, files))
+		}
+	}
+
+	return cleanUp
+}
+
+// exportRefUpdates exports the pushed reference updates and the
+// changed files of each reference in server mode.
+func exportRefUpdates(settings *HookSettings) (cleanUp func()) {
+	if !settings.ServerMode {
+		return nil
+	}
+
+	var err error
+	if settings.HookName == "update" {
+		settings.RefUpdates, err = hooks.GetRefUpdatesFromArgs(settings.Args)
+	} else {
+		settings.RefUpdates, err = hooks.ParseRefUpdates(string(settings.Stdin))
+	}
+	settings.Log.AssertNoErrorPanic(err, "Could not parse the pushed reference updates.")
+
+	updates := hooks.FormatRefUpdates(settings.RefUpdates)
+	settings.Log.DebugF("Exporting reference updates:\n%s", updates)
+
+	var sb strings.Builder
+	for i := range settings.RefUpdates {
+		update := &settings.RefUpdates[i]
+
+		files, e := hooks.GetRefUpdateChangedFiles(settings.GitX, update)
+		settings.Log.AssertNoErrorPanic(e, "Could not export changed files.")
+
+		for _, f := range files {
+			_, _ = strs.FmtW(&sb, "%s\t%s\n", update.Ref, f)
+		}
+	}
+
+	var relPath string
+	settings.ChangedFilesFile, relPath = writeGitDirTempFile(
+		settings, ".githooks-changed-files-*", sb.String(), "changed files")
+
+	// Set environment directly.
+	_ = os.Setenv(hooks.EnvVariableRefUpdates, updates)
+	_ = os.Setenv(hooks.EnvVariableChangedFilesFile, relPath)
+	// Set environment also in execution context.
+	settings.ExecX.Env = append(settings.ExecX.Env,
+		strs.Fmt("%s=%s", hooks.EnvVariableRefUpdates, updates),
+		strs.Fmt("%s=%s", hooks.EnvVariableChangedFilesFile, relPath))
+
+	return func() {
+		_ = os.Remove(settings.ChangedFilesFile)
+		_ = os.Unsetenv(hooks.EnvVariableRefUpdates)
+		_ = os.Unsetenv(hooks.EnvVariableChangedFilesFile)
+	}
+}
+
+// bufferStdin reads the standard input of hooks which get data on it once.
+// All hooks get the full standard input replayed.
+func bufferStdin(settings *HookSettings) {
+	if settings.Stdin != nil || !strs.Includes(hooks.StdinHookNames[:], settings.HookName) {
+		return
+	}
+
+	var err error
+	settings.Stdin, err = io.ReadAll(os.Stdin)
+	settings.Log.AssertNoErrorPanic(err, "Could not read standard input.")
+}
+
+// exportPushUpdates exports the pushed references on `pre-push`.
+func exportPushUpdates(settings *HookSettings) (cleanUp func()) {
+	if settings.HookName != "pre-push" {
+		return nil
+	}
+
+	var err error
+	settings.PushUpdates, err = hooks.ParsePushUpdates(string(settings.Stdin))
+	settings.Log.AssertNoErrorPanic(err, "Could not parse the pushed references.")
+
+	updates := hooks.FormatPushUpdates(settings.PushUpdates)
+	settings.Log.DebugF("Exporting pushed references:\n%s", updates)
+
+	// Set environment directly.
+	_ = os.Setenv(hooks.EnvVariablePushUpdates, updates)
+	// Set environment also in execution context.
+	settings.ExecX.Env = append(settings.ExecX.Env,
+		strs.Fmt("%s=%s", hooks.EnvVariablePushUpdates, updates))
+
+	return func() {
+		_ = os.Unsetenv(hooks.EnvVariablePushUpdates)
+	}
+}
+
+// exportPushedFiles exports the union of all pushed files on `pre-push`.
+// The files are only computed if any hook `hs` runs, since this
+// might need to walk the history of new references.
+func exportPushedFiles(settings *HookSettings, hs *hooks.Hooks) (cleanUp func()) {
+	if settings.HookName != "pre-push" || settings.DryRun || hs.GetHooksCount() == 0 {
+		return nil
+	}
+
+	var remote string
+	if len(settings.Args) != 0 {
+		remote = settings.Args[0]
+	}
+
+	var files []string
+	for i := range settings.PushUpdates {
+		fs, e := hooks.GetPushUpdateChangedFiles(settings.GitX, remote, &settings.PushUpdates[i])
+		if !settings.Log.AssertNoErrorF(e, "Could not export pushed files in '%s'.", hooks.EnvVariablePushedFilesFile) {
+			return nil
+		}
+
+		files = append(files, fs...)
+	}
+
+	files = strs.MakeUnique(files)
+	sort.Strings(files)
+
+	var sb strings.Builder
+	for _, f := range files {
+		_, _ = strs.FmtW(&sb, "%s\x00", f)
+	}
+
+	var relPath string
+	settings.PushedFilesFile, relPath = writeGitDirTempFile(
+		settings, ".githooks-pushed-files-*", sb.String(), "pushed files")
+
+	// Set environment directly.
+	_ = os.Setenv(hooks.EnvVariablePushedFilesFile, relPath)
+	// Set environment also in execution context.
+	settings.ExecX.Env = append(settings.ExecX.Env,
+		strs.Fmt("%s=%s", hooks.EnvVariablePushedFilesFile, relPath))
+
+	return func() {
+		_ = os.Remove(settings.PushedFilesFile)
+		_ = os.Unsetenv(hooks.EnvVariablePushedFilesFile)
+	}
+}
+
+// writeGitDirTempFile writes `content` to a new temporary file with name `pattern`
+// inside the Git directory which is also accessible when running containerized
+// and is the repository directory for bare repositories on a server.
+// It returns the file path and the path relative to the repository.
+func writeGitDirTempFile(
+	settings *HookSettings,
+	pattern string,
+	content string,
+	what string) (filePath string, relPath string) {
+	file, err := os.CreateTemp(settings.GitDirWorktree, pattern)
+	settings.Log.AssertNoErrorPanicF(err, "Could not open temp file for %s.", what)
+	defer func() { _ = file.Close() }()
+
+	_, err = file.WriteString(content)
+	settings.Log.AssertNoErrorPanicF(err, "Could not write %s to temp file.", what)
+
+	filePath = filepath.ToSlash(file.Name())
+	relPath = filePath
+	if p, e := filepath.Rel(settings.RepositoryDir, file.Name()); e == nil {
+		relPath = filepath.ToSlash(p)
+	}
+
+	return
+}
+
+func updateGithooks(settings *HookSettings, uiSettings *UISettings) {
+	if !shouldRunUpdateCheck(settings) {
+		return
+	}
+
+	err := updates.RecordUpdateCheckTimestamp(settings.InstallDir)
+	settings.Log.AssertNoError(err, "Could not record update check time.")
+
+	var usePreRelease bool
+	if settings.GitX.GetConfig(
+		hooks.GitCKUpdateCheckUsePrerelease,
+		git.GlobalScope,
+	) == git.GitCVTrue {
+		usePreRelease = true
+	}
+
+	cloneDir := hooks.GetReleaseCloneDir(settings.InstallDir)
+	status, err := updates.FetchUpdates(
+		cloneDir,
+		"",
+		"",
+		build.BuildTag,
+		true,
+		updates.ErrorOnWrongRemote,
+		usePreRelease, true)
+
+	if err != nil {
+		m := strs.Fmt(
+			"Running update check failed.")
+
+		settings.Log.AssertNoError(err, m)
+		err = uiSettings.PromptCtx.ShowMessage(m, true)
+		settings.Log.AssertNoError(err, "Could not show message.")
+
+		return
+	}
+
+	versionText, _ := updates.FormatUpdateText(&status, true)
+	settings.Log.Info(versionText)
+	settings.Log.Info(
+		"If you would like to disable update checks, run:",
+		"  $ git hooks update --disable-check")
+}
+
+func shouldRunUpdateCheck(settings *HookSettings) bool {
+	if settings.HookName != "post-commit" || settings.Simulate {
+		return false
+	}
+
+	enabled, _ := updates.GetUpdateCheckSettings(settings.GitX)
+	if !enabled {
+		return false
+	}
+
+	lastUpdateCheck, _, err := updates.GetUpdateCheckTimestamp(settings.InstallDir)
+	settings.Log.AssertNoErrorF(err, "Could get last update check time.")
+
+	return time.Since(lastUpdateCheck).Hours() > 24.0 //nolint:mnd
+}
+
+func executeLFSHooks(settings *HookSettings) {
+	if !strs.Includes(hooks.LFSHookNames[:], settings.HookName) {
+		return
+	} else if settings.Simulate {
+		settings.Log.InfoF("Skipping Git LFS hook '%s' in simulated run.", settings.HookName)
+
+		return
+	}
+
+	lfsIsAvailable := git.IsLFSAvailable()
+	lfsRequiredFile, lfsReqFileExists := hooks.GetLFSRequiredFile(settings.RepositoryDir)
+	lfsConfFile, lfsConfExists := git.GetLFSConfigFile(settings.RepositoryDir)
+	lfsIsRequired := lfsReqFileExists || lfsConfExists
+
+	if lfsIsAvailable {
+		settings.Log.Debug("Executing LFS Hook")
+
+		// The standard input might have been read already.
+		stdin, _, _ := settings.getStdin()()
+		err := settings.GitX.CheckPipedStdin(
+			stdin,
+			append(
+				[]string{"lfs", settings.HookName},
+				settings.Args...,
+			)...)
+
+		settings.Log.AssertNoErrorPanic(err, "Execution of LFS Hook failed.")
+	} else if lfsIsRequired {
+		settings.Log.PanicF("This repository requires Git LFS, but 'git-lfs' was\n"+
+			"not found on your PATH.\n"+
+			"Git LFS is required since one of the following is true:\n"+
+			"  - file '%s' existing: '%v'\n"+
+			"  - file `%s` existing: '%v'",
+			lfsConfFile, lfsConfExists, lfsRequiredFile, lfsReqFileExists)
+	}
+}
+
+func failOrWarnOnActiveUntrusted(log cm.ILogContext, skipUntrustedHooks bool, serverMode bool, hook *hooks.Hook) {
+	if hook.Active && !hook.Trusted {
+		switch {
+		case serverMode && !skipUntrustedHooks:
+			log.PanicF(
+				"Push rejected, hook '%s' is active and needs to be trusted\n"+
+					"on the server first. Either trust the hook or disable it.",
+				hook.NamespacePath)
+		case skipUntrustedHooks:
+			log.WarnF(
+				"Hook '%s'\nis active and needs to be trusted first. Skipping.", hook.NamespacePath)
+		default:
+			log.PanicF(
+				"Hook '%s' is active and needs to be trusted first.\n"+
+					"Either trust the hook or disable it, or skip active,\n"+
+					"untrusted hooks by running:\n"+
+					"  $ git hooks config skip-untrusted-hooks --enable",
+				hook.NamespacePath)
+		}
+	}
+}
+
+func executeOldHook(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) {
+	hook := getOldHook(settings, uiSettings, ignores, checksums)
+	if hook == nil {
+		return
+	}
+
+	if settings.DryRun {
+		settings.Log.InfoF("Would execute replaced hook '%s'.", hook.NamespacePath)
+
+		return
+	}
+
+	executeHookPassThrough(settings, hook)
+}
+
+// getOldHook gets the replaced hook if it is active and trusted, otherwise `nil`.
+func getOldHook(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) *hooks.Hook {
+	// e.g. 'hooks/pre-commit.replaced.githook's
+	hookName := hooks.GetHookReplacementFileName(settings.HookName)
+	hookNamespace := hooks.NamespaceReplacedHook
+
+	// Old hook can only be ignored by user ignores...
+	isIgnored := func(namespacePath string) bool {
+		ignored, byUser := ignores.IsIgnored(namespacePath)
+
+		return ignored && byUser
+	}
+
+	isTrusted := func(hookPath string, override string) (bool, string) {
+		if settings.IsRepoTrusted {
+			return true, ""
+		}
+
+		trusted, sha, e := checksums.IsTrusted(hookPath, override)
+		settings.Log.AssertNoErrorPanicF(e, "Could not check trust status '%s'.", hookPath)
+
+		return trusted, sha
+	}
+
+	hooks, _, err := hooks.GetAllHooksIn(
+		settings.GitX,
+		settings.RepositoryDir,
+		settings.HookDir, hookName, hookNamespace, nil,
+		isIgnored, isTrusted, true, false,
+		settings.ContainerMgr)
+	settings.Log.AssertNoErrorPanicF(err, "Errors while collecting hooks in '%s'.", settings.HookDir)
+
+	if len(hooks) == 0 {
+		settings.Log.DebugF("Old hook '%s' does not exist. -> Skip!", hookName)
+
+		return nil
+	}
+
+	hook := &hooks[0]
+
+	if hook.Active && !hook.Trusted {
+		if !settings.NonInteractive && !settings.DryRun {
+			// Active hook, but not trusted:
+			// Show trust prompt to let user trust it or disable it.
+			showTrustPrompt(settings.Log, uiSettings, checksums, hook)
+		}
+
+		failOrWarnOnActiveUntrusted(settings.Log, settings.SkipUntrustedHooks || settings.DryRun, settings.ServerMode, hook)
+	}
+
+	if !hook.Active || !hook.Trusted {
+		settings.Log.DebugF("Hook '%s' is skipped [active: '%v', trusted: '%v']",
+			hook.Path, hook.Active, hook.Trusted)
+
+		return nil
+	}
+
+	return hook
+}
+
+// executeHookPassThrough executes the hook `hook` with the standard streams
+// passed through and the arguments from Git.
+func executeHookPassThrough(settings *HookSettings, hook *hooks.Hook) {
+	settings.Log.DebugF("Executing hook: '%s'.", hook.Path)
+	stdin, _, _ := settings.getStdin()()
+	err := cm.RunExecutable(&settings.ExecX, hook, cm.UseStreams(stdin, os.Stdout, os.Stderr), settings.Args...)
+
+	settings.Log.AssertNoErrorPanicF(err, "Hook launch failed: '%q'.", hook)
+}
+
+// executeProtocolHook executes the single hook of hooks which talk a protocol
+// with Git, e.g. `fsmonitor-watchman` or `proc-receive`.
+// The replaced hook counts as a hook too.
+func executeProtocolHook(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) {
+	oldHook := getOldHook(settings, uiSettings, ignores, checksums)
+	hs := collectHooks(settings, uiSettings, ignores, checksums)
+
+	count := hs.GetHooksCount()
+	if oldHook != nil {
+		count++
+	}
+
+	err := hooks.CheckProtocolHooks(settings.HookName, count)
+	settings.Log.AssertNoErrorPanicF(err, "Disable or ignore all but one hook for '%s'.", settings.HookName)
+
+	if count == 0 {
+		settings.Log.DebugF("No hook for '%s' found. -> Skip!", settings.HookName)
+
+		return
+	}
+
+	// Containerized executions need to apply env. variables to
+	// arguments of the command.
+	if settings.ContainerMgr != nil {
+		applyEnvToContainerRunArgs(&hs)
+	}
+
+	setupHookEnvironments(settings.Log, &hs)
+
+	hook := oldHook
+	hs.Map(func(h *hooks.Hook) { hook = h })
+
+	if settings.DryRun {
+		settings.Log.InfoF("Would execute hook '%s'.", hook.NamespacePath)
+
+		return
+	}
+
+	executeHookPassThrough(settings, hook)
+}
+
+func collectHooks(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) (h hooks.Hooks) {
+	// Load common env. file if existing.
+	namespaceEnvs, err := hooks.LoadNamespaceEnvs(settings.RepositoryHooksDir)
+	cm.AssertNoErrorPanic(err, "Could not load env. file")
+	settings.Log.DebugF("Namespace envs: %v", namespaceEnvs)
+
+	// Local hooks in repository
+	// No parsing of local includes because already happened.
+	h.LocalHooks = getHooksIn(
+		settings, uiSettings, settings.RepositoryDir, settings.RepositoryHooksDir,
+		false, settings.HookNamespace, namespaceEnvs, false, ignores, checksums)
+
+	// Built-in hooks run after the local hooks in their own batch.
+	if builtins := getBuiltinHooks(settings, ignores); len(builtins) != 0 {
+		h.LocalHooks = append(h.LocalHooks, builtins)
+	}
+
+	// All shared hooks
+	var allAddedShared = make([]string, 0)
+	h.RepoSharedHooks = getRepoSharedHooks(
+		settings, uiSettings,
+		namespaceEnvs, ignores, checksums, &allAddedShared)
+
+	h.LocalSharedHooks = getConfigSharedHooks(
+		settings,
+		uiSettings,
+		namespaceEnvs,
+		ignores,
+		checksums,
+		&allAddedShared,
+		hooks.SharedHookTypeV.Local)
+
+	h.GlobalSharedHooks = getConfigSharedHooks(
+		settings,
+		uiSettings,
+		namespaceEnvs,
+		ignores,
+		checksums,
+		&allAddedShared,
+		hooks.SharedHookTypeV.Global)
+
+	return
+}
+
+func updateLocalHookImages(settings *HookSettings) {
+	if settings.ContainerMgr == nil || settings.HookName != "post-merge" || settings.Simulate {
+		return
+	}
+
+	e := hooks.UpdateImages(
+		settings.Log,
+		settings.RepositoryDir,
+		settings.RepositoryDir,
+		settings.RepositoryHooksDir,
+		"",
+		settings.ContainerMgr,
+		false)
+
+	settings.Log.AssertNoErrorF(e, "Could not updating container images from '%s'.", settings.HookDir)
+}
+
+func updateSharedHooks(
+	settings *HookSettings,
+	sharedHooks []hooks.SharedRepo,
+	sharedType hooks.SharedHookType,
+) {
+	disableUpdate, _ := hooks.IsSharedHooksUpdateDisabled(settings.GitX, git.Traverse)
+	updateTriggers := settings.GitX.GetConfigAll(hooks.GitCKSharedUpdateTriggers, git.Traverse)
+
+	updateOnCloneDoneFile := path.Join(
+		settings.GitDirWorktree,
+		".githooks-shared-update-on-clone-done",
+	)
+	updateOnCloneDoneFileExists, _ := cm.IsPathExisting(updateOnCloneDoneFile)
+	updateOnCloneNeeded := settings.HookName == "post-checkout" && !updateOnCloneDoneFileExists
+
+	triggered := settings.HookName == "post-merge" || updateOnCloneNeeded ||
+		strs.Includes(updateTriggers, settings.HookName)
+
+	if disableUpdate || !triggered || settings.Simulate {
+		settings.Log.Debug("Shared hooks not updated.")
+
+		return
+	}
+
+	settings.Log.Debug("Updating all shared hooks.")
+	_, err := hooks.UpdateSharedHooks(settings.Log, sharedHooks, sharedType, settings.ContainerMgr)
+	settings.Log.AssertNoError(err, "Errors while updating shared hooks repositories.")
+
+	if updateOnCloneNeeded {
+		_ = cm.TouchFile(updateOnCloneDoneFile, true)
+	}
+}
+
+func getRepoSharedHooks(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	namespaceEnvs hooks.NamespaceEnvs,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore,
+	allAddedHooks *[]string) (hs hooks.HookPrioList) {
+	shared, err :=
+		hooks.LoadRepoSharedHooks(settings.InstallDir, settings.RepositoryDir)
+
+	if err != nil {
+		settings.Log.ErrorOrPanicF(!settings.SkipNonExistingSharedHooks, err,
+			"Repository shared hooks are demanded but failed "+
+				"to parse the file:\n'%s'",
+			hooks.GetRepoSharedFile(settings.RepositoryDir))
+	}
+
+	updateSharedHooks(settings, shared, hooks.SharedHookTypeV.Repo)
+
+	for i := range shared {
+		shRepo := &shared[i]
+
+		if checkSharedHook(settings, shRepo, allAddedHooks, hooks.SharedHookTypeV.Repo) {
+			hs = append(hs,
+				getHooksInShared(
+					settings, uiSettings,
+					namespaceEnvs,
+					shRepo, ignores, checksums)...)
+			*allAddedHooks = append(*allAddedHooks, shRepo.RepositoryDir)
+		}
+	}
+
+	return
+}
+
+func getConfigSharedHooks(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	namespaceEnvs hooks.NamespaceEnvs,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore,
+	allAddedHooks *[]string,
+	sharedType hooks.SharedHookType) (hs hooks.HookPrioList) {
+	var shared []hooks.SharedRepo
+	var err error
+
+	switch sharedType {
+	case hooks.SharedHookTypeV.Local:
+		shared, err = hooks.LoadConfigSharedHooks(
+			settings.InstallDir,
+			settings.GitX,
+			git.LocalScope,
+		)
+	case hooks.SharedHookTypeV.Global:
+		shared, err = hooks.LoadConfigSharedHooks(
+			settings.InstallDir,
+			settings.GitX,
+			git.GlobalScope,
+		)
+	default:
+		cm.DebugAssertF(false, "Wrong shared type '%v'", sharedType)
+	}
+
+	if err != nil {
+		settings.Log.ErrorOrPanicF(!settings.SkipNonExistingSharedHooks,
+			err,
+			"Shared hooks are demanded but failed "+
+				"to parse the %s config:\n'%s'",
+			hooks.GetSharedHookTypeString(sharedType),
+			hooks.GitCKShared)
+	}
+
+	for i := range shared {
+		shRepo := &shared[i]
+
+		if checkSharedHook(settings, shRepo, allAddedHooks, sharedType) {
+			hs = append(hs, getHooksInShared(
+				settings, uiSettings,
+				namespaceEnvs, shRepo, ignores, checksums)...)
+
+			*allAddedHooks = append(*allAddedHooks, shRepo.RepositoryDir)
+		}
+	}
+
+	return
+}
+
+func checkSharedHook(
+	settings *HookSettings,
+	hook *hooks.SharedRepo,
+	allAddedHooks *[]string,
+	sharedType hooks.SharedHookType) bool {
+	// Aborting a 'reference-transaction' hook (type 'prepared') leads
+	// to all sorts of problems, therefore
+	// do only print an error and continue.
+	isFatal := settings.HookName != "reference-transaction"
+
+	if strs.Includes(*allAddedHooks, hook.RepositoryDir) {
+		settings.Log.WarnF(
+			"Shared hooks entry:\n'%s'\n"+
+				"is already listed and will be skipped.", hook.OriginalURL)
+
+		return false
+	}
+
+	// Check that no local paths are in repository configured
+	// shared hooks
+	settings.Log.ErrorOrPanicIfF(isFatal, !hooks.AllowLocalURLInRepoSharedHooks() &&
+		sharedType == hooks.SharedHookTypeV.Repo && hook.IsLocal,
+		"Shared hooks in '%[1]s' contain a local path\n"+
+			"'%[2]s'\n"+
+			"which is forbidden.\n"+
+			"\n"+
+			"You can only have local paths in shared hooks defined\n"+
+			"in the local or global Git configuration.\n"+
+			"\n"+
+			"You need to fix this by running\n"+
+			"  $ git hooks shared add [--local|--global] '%[2]s'\n"+
+			"and deleting it from the '.shared' file by\n"+
+			"  $ git hooks shared remove --shared '%[2]s'",
+		hooks.GetRepoSharedFileRel(), hook.OriginalURL)
+
+	// Check if existing otherwise skip or fail...
+	exists, err := cm.IsPathExisting(hook.RepositoryDir)
+
+	if !exists {
+		mess := "Repository: '%s'\nneeds shared hooks in:\n" +
+			"'%s'\n"
+
+		if hook.IsCloned {
+			mess += "which are are not available. To fix, run:\n" +
+				"$ git hooks shared update\n" +
+				"or gracefully continue by setting:\n" +
+				"$ git hooks config skip-non-existing-shared-hooks --enable [--global]"
+		} else {
+			mess += "which does not exist."
+		}
+
+		if settings.SkipNonExistingSharedHooks {
+			mess += "\nContinuing..."
+		}
+
+		settings.Log.ErrorOrPanicF(isFatal && !settings.SkipNonExistingSharedHooks,
+			err, mess, settings.RepositoryDir, hook.OriginalURL)
+
+		return false
+	}
+
+	// If cloned check that the remote url
+	// is the same as the specified
+	// Note: GIT_DIR might be set (?bug?) (actually the case for post-checkout hook)
+	if hook.IsCloned {
+		url := git.NewCtxSanitizedAt(hook.RepositoryDir).GetConfig(
+			"remote.origin.url", git.LocalScope)
+
+		if url != hook.URL {
+			mess := "Failed to execute shared hooks in '%s'\n" +
+				"The remote URL '%s' is different.\n" +
+				"To fix it, run:\n" +
+				"  $ git hooks shared purge\n" +
+				"  $ git hooks shared update"
+
+			if settings.SkipNonExistingSharedHooks {
+				mess += "\nContinuing..."
+			}
+
+			settings.Log.ErrorOrPanicF(isFatal && !settings.SkipNonExistingSharedHooks,
+				nil, mess, hook.OriginalURL, url)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func getHooksIn(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	rootDir string,
+	hooksDir string,
+	addInternalIgnores bool,
+	hookNamespace string,
+	namespaceEnvs hooks.NamespaceEnvs,
+	readNamespace bool,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) (batches hooks.HookPrioList) {
+	settings.Log.DebugF("Getting hooks in '%s'", hooksDir)
+
+	isTrusted := func(hookPath string, override string) (bool, string) {
+		if settings.IsRepoTrusted {
+			return true, ""
+		}
+
+		trusted, sha, e := checksums.IsTrusted(hookPath, override)
+		settings.Log.AssertNoErrorPanicF(e, "Could not check trust status '%s'.", hookPath)
+
+		return trusted, sha
+	}
+
+	// Determine namespace
+	if readNamespace {
+		ns, err := hooks.GetHooksNamespace(hooksDir)
+		settings.Log.AssertNoErrorPanicF(err, "Could not get hook namespace in '%s'", hooksDir)
+		if strs.IsNotEmpty(ns) {
+			hookNamespace = ns
+		}
+	}
+
+	var internalIgnores hooks.HookPatterns
+	if addInternalIgnores {
+		var e error
+		internalIgnores, e = hooks.GetHookPatternsHooksDir(
+			hooksDir,
+			[]string{settings.HookName},
+			hookNamespace,
+		)
+		settings.Log.AssertNoErrorPanicF(e, "Could not get worktree ignores in '%s'.", hooksDir)
+	}
+
+	isIgnored := func(namespacePath string) bool {
+		ignored, _ := ignores.IsIgnored(namespacePath)
+
+		return ignored || internalIgnores.Matches(namespacePath)
+	}
+
+	allHooks, maxBatches, err := hooks.GetAllHooksIn(
+		settings.GitX,
+		rootDir,
+		hooksDir, settings.HookName, hookNamespace, namespaceEnvs.Get(hookNamespace),
+		isIgnored, isTrusted, true, true,
+		settings.ContainerMgr)
+	settings.Log.AssertNoErrorPanicF(err, "Errors while collecting hooks in '%s'.", hooksDir)
+
+	if len(allHooks) == 0 {
+		return batches
+	}
+
+	// Sort allHooks by the given batchName
+	if len(allHooks) > 1 {
+		sort.Slice(allHooks, func(i, j int) bool {
+			return allHooks[i].BatchName < allHooks[j].BatchName
+		})
+	}
+
+	// Split all hooks (sorted by the batch names)
+	// into batches.
+	batches = make(hooks.HookPrioList, 1, maxBatches)
+
+	curBatchIdx := 0
+	curBatchName := &allHooks[0].BatchName
+
+	for i := range allHooks {
+		hook := &allHooks[i]
+
+		if hook.Active && !hook.Trusted {
+			if !settings.NonInteractive && !settings.DryRun {
+				// Active hook, but not trusted:
+				// Show trust prompt to let user trust it or disable it.
+				showTrustPrompt(settings.Log, uiSettings, checksums, hook)
+			}
+
+			failOrWarnOnActiveUntrusted(settings.Log, settings.SkipUntrustedHooks || settings.DryRun, settings.ServerMode, hook)
+		}
+
+		if !hook.Active || !hook.Trusted {
+			settings.Log.DebugF("Hook '%s' is skipped [active: '%v', trusted: '%v']",
+				hook.Path, hook.Active, hook.Trusted)
+
+			continue
+		}
+
+		if hook.When != nil {
+			run, err := hook.When.Evaluate(settings.Conditions)
+			settings.Log.AssertNoErrorPanicF(err, "Could not evaluate condition of hook '%s'.", hook.NamespacePath)
+
+			if !run {
+				settings.Log.InfoF("Hook '%s' is skipped: condition '%s' is not met.",
+					hook.NamespacePath, hook.When.Expr)
+
+				continue
+			}
+		}
+
+		if len(hook.Files) != 0 &&
+			strs.Includes(hooks.StagedFilesHookNames[:], settings.HookName) {
+			matched, err := hook.MatchesFiles(settings.StagedFileList)
+			settings.Log.AssertNoErrorPanicF(err, "Could not match files of hook '%s'.", hook.NamespacePath)
+
+			if !matched {
+				settings.Log.InfoF("Hook '%s' is skipped: no staged files match '%s'.",
+					hook.NamespacePath, strings.Join(hook.Files, "', '"))
+
+				continue
+			}
+		}
+
+		if *curBatchName != hook.BatchName {
+			// Batch name changed, add another batch...
+			batches = append(batches, []hooks.Hook{})
+			curBatchIdx++
+			curBatchName = &hook.BatchName
+		}
+
+		batches[curBatchIdx] = append(batches[curBatchIdx], *hook)
+	}
+
+	return batches
+}
+
+// getBuiltinHooks gets all active built-in hooks which are configured in the repository.
+func getBuiltinHooks(settings *HookSettings, ignores *hooks.RepoIgnorePatterns) (builtins []hooks.Hook) {
+	isIgnored := func(namespacePath string) bool {
+		ignored, _ := ignores.IsIgnored(namespacePath)
+
+		return ignored
+	}
+
+	allHooks, err := hooks.GetBuiltinHooks(settings.RepositoryHooksDir, settings.HookName, isIgnored)
+	settings.Log.AssertNoErrorPanicF(err, "Could not get built-in hooks in '%s'.", settings.RepositoryHooksDir)
+
+	for i := range allHooks {
+		if !allHooks[i].Active {
+			settings.Log.DebugF("Built-in hook '%s' is skipped [active: 'false']", allHooks[i].NamespacePath)
+
+			continue
+		}
+
+		builtins = append(builtins, allHooks[i])
+	}
+
+	return
+}
+
+func getHooksInShared(settings *HookSettings,
+	uiSettings *UISettings,
+	namespaceEnvs hooks.NamespaceEnvs,
+	shRepo *hooks.SharedRepo,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) hooks.HookPrioList {
+	hookNamespace := hooks.GetDefaultHooksNamespaceShared(shRepo)
+
+	dir := hooks.GetSharedGithooksDir(shRepo.RepositoryDir)
+
+	return getHooksIn(
+		settings, uiSettings,
+		shRepo.RepositoryDir, dir, true, hookNamespace,
+		namespaceEnvs, true, ignores, checksums)
+}
+
+func logBatches(log cm.ILogContext, title string, hooks hooks.HookPrioList) {
+	var l string
+
+	if hooks == nil {
+		log.DebugF("%s: none", title)
+	} else {
+		for bIdx, batch := range hooks {
+			l += strs.Fmt(" Batch: %v\n", bIdx)
+			for i := range batch {
+				l += strs.Fmt("  - '%s' %+q\n", batch[i].GetCommand(), batch[i].GetArgs())
+			}
+		}
+		log.DebugF("%s :\n%s", title, l)
+	}
+}
+
+func formatExecutionPlan(title string, hs hooks.HookPrioList) string {
+	if hs.GetHooksCount() == 0 {
+		return strs.Fmt("%s: none", title)
+	}
+
+	var sb strings.Builder
+	_, _ = strs.FmtW(&sb, "%s:", title)
+
+	for bIdx, batch := range hs {
+		_, _ = strs.FmtW(&sb, "\n Batch %v:", bIdx)
+		for i := range batch {
+			_, _ = strs.FmtW(&sb, "\n  %s '%s' : '%s' %q",
+				cm.ListItemLiteral, batch[i].NamespacePath, batch[i].GetCommand(), batch[i].GetArgs())
+		}
+	}
+
+	return sb.String()
+}
+
+// logExecutionPlan logs all hooks which would be executed
+// in order of execution.
+func logExecutionPlan(settings *HookSettings, hs *hooks.Hooks) {
+	settings.Log.InfoF("Execution plan for hook '%s' with arguments '%q':\n%s\n%s\n%s\n%s",
+		settings.HookName, settings.Args,
+		formatExecutionPlan("Local hooks", hs.LocalHooks),
+		formatExecutionPlan("Repository shared hooks", hs.RepoSharedHooks),
+		formatExecutionPlan("Local shared hooks", hs.LocalSharedHooks),
+		formatExecutionPlan("Global shared hooks", hs.GlobalSharedHooks))
+}
+
+// The maximal number of diff lines shown in the trust prompt.
+const trustPromptDiffLines = 40
+
+// limitLines limits `text` to `n` lines.
+func limitLines(text string, n int) string {
+	lines := strs.SplitLines(strings.TrimRight(text, "\n"))
+	if len(lines) <= n {
+		return strings.Join(lines, "\n")
+	}
+
+	return strings.Join(lines[:n], "\n") +
+		strs.Fmt("\n... '%v' more lines, see 'git hooks trust diff'.", len(lines)-n)
+}
+
+func showTrustPrompt(
+	log cm.ILogContext,
+	uiSettings *UISettings,
+	checksums *hooks.ChecksumStore,
+	hook *hooks.Hook) {
+	if hook.Trusted {
+		return
+	}
+
+	mess := strs.Fmt("New or changed hook found:\n'%s'", hook.Path)
+
+	if strs.IsNotEmpty(hook.Entry) {
+		// All entries of a hooks manifest share its checksum,
+		// it might have been trusted for a previous entry.
+		trusted, _, err := checksums.IsTrusted(hook.Path, "")
+		log.AssertNoErrorPanicF(err, "Could not check trust status '%s'.", hook.Path)
+
+		if trusted {
+			hook.Trusted = true
+
+			return
+		}
+
+		mess = strs.Fmt("New or changed hooks manifest found:\n'%s'", hook.Path)
+	}
+
+	acceptHook := uiSettings.AcceptAllChanges
+	disableHook := false
+
+	if !acceptHook {
+		diff, hasSnapshot, err := checksums.GetTrustDiff(hook.Path)
+		log.AssertNoError(err, "Could not get changes of hook '%s'.", hook.Path)
+
+		if hasSnapshot && strs.IsNotEmpty(diff) {
+			mess += "\nChanges since the last trusted version:\n" +
+				limitLines(diff, trustPromptDiffLines)
+		}
+
+		question := mess + "\nDo you accept the changes?"
+
+		answer, err := uiSettings.PromptCtx.ShowOptions(question,
+			"(yes, all, no, disable)",
+			"y/a/n/d",
+			"Yes", "All", "No", "Disable")
+		log.AssertNoError(err, "Could not get trust prompt answer.")
+
+		switch answer {
+		case "a":
+			uiSettings.AcceptAllChanges = true
+			fallthrough //nolint:nlreturn
+		case "y":
+			acceptHook = true
+		case "d":
+			disableHook = true
+		default:
+			// Don't run hook ...
+			// Trusted == false
+		}
+	} else {
+		log.Info("-> Already accepted.")
+	}
+
+	if acceptHook || disableHook {
+		err := hook.AssertSHA1()
+		log.AssertNoError(err, "Could not compute SHA1 hash of '%s'.", hook.Path)
+	}
+
+	if acceptHook {
+		hook.Trusted = true
+
+		uiSettings.AppendTrustedHook(
+			hooks.ChecksumResult{
+				SHA1:          hook.SHA1,
+				Path:          hook.Path,
+				NamespacePath: hook.NamespacePath})
+
+		checksums.AddChecksum(hook.SHA1, hook.Path)
+	} else if disableHook {
+		log.InfoF("-> Adding hook\n'%s'\nto disabled list.", hook.Path)
+
+		hook.Active = false
+
+		uiSettings.AppendDisabledHook(
+			hooks.ChecksumResult{
+				SHA1:          hook.SHA1,
+				Path:          hook.Path,
+				NamespacePath: hook.NamespacePath})
+	}
+}
+
+func applyEnvToContainerRunArgs(hs *hooks.Hooks) {
+	hs.Map(func(h *hooks.Hook) {
+		// Apply normal envs and the namespace env. variables too.
+		// Modify the staged files to point to the correct destination.
+		envs := append(hooks.GetGithooksEnvVariables(""), h.NamespaceEnvs...)
+
+		// Note: Will do a NoOp anything for not containerized runs in `h`.
+		h.ApplyEnvironmentToArgs(envs)
+	})
+}
+
+// setupHookEnvironments sets up all managed tool environments
+// of the hooks which are not yet set up.
+func setupHookEnvironments(log cm.ILogContext, hs *hooks.Hooks) {
+	hs.Map(func(h *hooks.Hook) {
+		if h.Environment == nil || h.Environment.IsReady() {
+			return
+		}
+
+		err := h.Environment.Setup(log)
+		log.AssertNoErrorPanicF(err, "Could not set up environment for hook '%s'.", h.NamespacePath)
+	})
+}
+
+func executeHooks(settings *HookSettings, hs *hooks.Hooks) {
+	// Containerized executions need to apply env. variables to
+	// arguments of the command.
+	if settings.ContainerMgr != nil {
+		applyEnvToContainerRunArgs(hs)
+	}
+
+	setupHookEnvironments(settings.Log, hs)
+
+	if cm.IsDebug {
+		logBatches(settings.Log, "Local Hooks", hs.LocalHooks)
+		logBatches(settings.Log, "Repo Shared Hooks", hs.RepoSharedHooks)
+		logBatches(settings.Log, "Local Shared Hooks", hs.LocalSharedHooks)
+		logBatches(settings.Log, "Global Shared Hooks", hs.GlobalSharedHooks)
+	}
+
+	var nThreads = runtime.NumCPU()
+	nThSetting := settings.GitX.GetConfig(hooks.GitCKNumThreads, git.Traverse)
+	if n, err := strconv.Atoi(nThSetting); err == nil {
+		nThreads = n
+	}
+
+	// Minimal 1 thread.
+	nThreads = math.MaxInt(nThreads, 1)
+
+	var pool *threadpool.ThreadPool
+	if hooks.UseThreadPool && hs.GetHooksCount() > 1 {
+		settings.Log.Debug("Launching with thread pool")
+		p := threadpool.New(nThreads, 15) //nolint:mnd
+		pool = &p
+	}
+
+	var results []hooks.HookResult
+	var err error
+
+	// Dump execution sequence.
+	if cm.IsDebug {
+		file, e := os.CreateTemp("", strs.Fmt("*-githooks-prio-list-%s.json", settings.HookName))
+		settings.Log.AssertNoErrorPanic(e, "Failed to create execution settings.Log.")
+		defer func() { _ = file.Close() }()
+		e = hs.StoreJSON(file)
+		settings.Log.AssertNoErrorPanic(e, "Failed to create execution settings.Log.")
+		settings.Log.DebugF("Hooks priority list written to '%s'.", file.Name())
+	}
+
+	outputMode, err := hooks.GetOutputMode(settings.GitX)
+	settings.Log.AssertNoErrorF(err, "Could not get the output mode of hooks.")
+
+	var output hooks.HookOutputFunc
+	if outputMode == hooks.OutputModeTypeV.Stream {
+		output = hooks.NewStreamedOutput(settings.Log)
+	}
+
+	// Show the running hooks in the terminal, except when their output is streamed.
+	var progress *cm.ProgressStatus
+	if output == nil {
+		progress = cm.NewProgressStatus(settings.Log, hs.GetHooksCount())
+	}
+
+	rejectsPush := settings.ServerMode && settings.HookName != "post-receive"
+	logResults := func(res ...hooks.HookResult) { logHookResults(settings.Log, output != nil, rejectsPush, res...) }
+	if hs.GetHooksCount() != 0 {
+		var storeRecords func()
+		logResults, storeRecords = recordHookResults(settings, logResults)
+		// Also store the records when hooks failed.
+		defer storeRecords()
+	}
+
+	settings.Log.InfoIfF(
+		len(hs.LocalHooks) != 0,
+		"Launching '%v' local hooks [type: '%s', threads: '%v'] ...",
+		hs.LocalHooks.CountFmt(), settings.HookName, nThreads)
+
+	results, err = hooks.ExecuteHooksParallel(
+		pool, &settings.ExecX, hs.LocalHooks,
+		results, settings.getStdin(), output, progress, logResults,
+		settings.Args...)
+	settings.Log.AssertNoErrorPanic(err, "Local hook execution failed.")
+
+	settings.Log.InfoIfF(
+		len(hs.RepoSharedHooks) != 0,
+		"Launching '%v' repository shared hooks [type: '%s', threads: '%v']...",
+		hs.RepoSharedHooks.CountFmt(), settings.HookName, nThreads)
+
+	results, err = hooks.ExecuteHooksParallel(
+		pool, &settings.ExecX, hs.RepoSharedHooks,
+		results, settings.getStdin(), output, progress, logResults,
+		settings.Args...)
+	settings.Log.AssertNoErrorPanic(err, "Shared repository hook execution failed.")
+
+	settings.Log.InfoIfF(
+		len(hs.LocalSharedHooks) != 0,
+		"Launching '%v' local shared hooks [type: '%s', threads: '%v']...",
+		hs.LocalSharedHooks.CountFmt(), settings.HookName, nThreads)
+
+	results, err = hooks.ExecuteHooksParallel(
+		pool, &settings.ExecX, hs.LocalSharedHooks,
+		results, settings.getStdin(), output, progress, logResults,
+		settings.Args...)
+	settings.Log.AssertNoErrorPanic(err, "Local shared hook execution failed.")
+
+	settings.Log.InfoIfF(
+		len(hs.GlobalSharedHooks) != 0,
+		"Launching '%v' global shared hooks [type: '%s', threads: '%v']...",
+		hs.GlobalSharedHooks.CountFmt(), settings.HookName, nThreads)
+
+	_, err = hooks.ExecuteHooksParallel(
+		pool, &settings.ExecX, hs.GlobalSharedHooks,
+		results, settings.getStdin(), output, progress, logResults,
+		settings.Args...)
+	settings.Log.AssertNoErrorPanic(err, "Global shared hook execution failed.")
+}
+
+// recordHookResults records the timings and the execution history of the
+// results before logging them with `logResults`. The records are stored with `store`.
+// Simulated runs (e.g. `git hooks run` and `git hooks ci`) are only recorded as
+// such in the history and are excluded from the timings.
+func recordHookResults(
+	settings *HookSettings,
+	logResults func(res ...hooks.HookResult)) (recordResults func(res ...hooks.HookResult), store func()) {
+	var timings *hooks.HookTimings
+	if !settings.Simulate {
+		t, err := hooks.LoadHookTimings(settings.GitDirWorktree)
+		settings.Log.AssertNoErrorF(err, "Could not load hook timings.")
+		timings = &t
+	}
+
+	budget, err := hooks.GetHookTimeBudget(settings.GitX)
+	settings.Log.AssertNoErrorF(err, "Could not get hook time budget.")
+
+	var run *hooks.HistoryRun
+	if isHistoryEnabled(settings) {
+		r := hooks.NewHistoryRun(settings.RepositoryDir, settings.HookName, settings.Args)
+		r.Simulated = settings.Simulate
+		// Outputs can contain secrets echoed by hooks and are only recorded on request.
+		r.RecordOutput = settings.GitX.GetConfig(hooks.GitCKHistoryOutput, git.Traverse) == git.GitCVTrue
+		run = &r
+	}
+
+	recordResults = func(res ...hooks.HookResult) {
+		if timings != nil {
+			timings.Add(settings.HookName, res...)
+		}
+		warnOverBudget(settings.Log, budget, res...)
+
+		if run != nil {
+			run.AddResults(res...)
+		}
+
+		logResults(res...)
+	}
+
+	store = func() {
+		if timings != nil {
+			err := timings.Store(settings.GitDirWorktree)
+			settings.Log.AssertNoErrorF(err, "Could not store hook timings.")
+		}
+
+		if run != nil {
+			storeHistory(settings, run)
+		}
+	}
+
+	return
+}
+
+// warnOverBudget warns about hooks which took longer than `budget`.
+func warnOverBudget(log cm.ILogContext, budget time.Duration, res ...hooks.HookResult) {
+	if budget == 0 {
+		return
+	}
+
+	for i := range res {
+		log.WarnIfF(res[i].Duration > budget,
+			"Hook '%s' took '%v' which is over the budget of '%v'.\n"+
+				"See 'git hooks stats' for the timings of all hooks.",
+			res[i].Hook.NamespacePath, res[i].Duration.Round(time.Millisecond), budget)
+	}
+}
+
+func isHistoryEnabled(settings *HookSettings) bool {
+	return strs.IsNotEmpty(settings.InstallDir) &&
+		settings.GitX.GetConfig(hooks.GitCKHistoryDisabled, git.Traverse) != git.GitCVTrue
+}
+
+func storeHistory(settings *HookSettings, run *hooks.HistoryRun) {
+	run.Finish()
+	err := hooks.AppendHistory(settings.InstallDir, run)
+	settings.Log.AssertNoErrorF(err, "Could not store the execution history.")
+}
+
+// logHookResults logs the results of hooks.
+// The output is not logged if it has already been streamed.
+// If `rejectsPush` is set, failing hooks reject the push on the server.
+func logHookResults(log cm.ILogContext, streamed bool, rejectsPush bool, res ...hooks.HookResult) {
+	hadErrors := false
+	var sb strings.Builder
+
+	for _, r := range res {
+		if r.Error == nil {
+			if len(r.Output) != 0 && !streamed {
+				_, _ = log.GetInfoWriter().Write(r.Output)
+			}
+		} else {
+			hadErrors = true
+			if len(r.Output) != 0 && !streamed {
+				_, _ = log.GetErrorWriter().Write(r.Output)
+			}
+			log.AssertNoErrorF(r.Error, "Hook '%s' failed!", r.Hook.Path)
+			_, _ = strs.FmtW(&sb, "\n%s '%s'", cm.ListItemLiteral, r.Hook.NamespacePath)
+		}
+	}
+
+	switch {
+	case hadErrors && rejectsPush:
+		log.PanicF("Push rejected, some hooks failed, check output for details:%s", sb.String())
+	case hadErrors:
+		log.PanicF("Some hooks failed, check output for details:%s", sb.String())
+	}
+}
+
+func storePendingData(
+	settings *HookSettings,
+	uiSettings *UISettings,
+	ignores *hooks.RepoIgnorePatterns,
+	checksums *hooks.ChecksumStore) {
+	// Store all ignore user patterns if there are new ones.
+	if len(uiSettings.DisabledHooks) != 0 {
+		// Load the patterns of the store (only one layer of `ignores.User`) ...
+		store := settings.UserStateDirs.Store
+		patterns, err := hooks.GetHookPatternsGitDir(store, settings.HookNamespace)
+		settings.Log.AssertNoErrorF(err, "Could not load user ignore patterns.")
+
+		// ... add all to the list ...
+		for i := range uiSettings.DisabledHooks {
+			namespacePath := uiSettings.DisabledHooks[i].NamespacePath
+			patterns.AddNamespacePaths(namespacePath)
+			ignores.User.AddNamespacePaths(namespacePath)
+		}
+
+		// ... and store them
+		err = hooks.StoreHookPatternsGitDir(patterns, store)
+		settings.Log.AssertNoErrorF(err, "Could not store disabled hooks.")
+	}
+
+	// Store all checksums if there are any new ones.
+	if len(uiSettings.TrustedHooks) != 0 {
+		err := checksums.SyncChecksumAdd(uiSettings.TrustedHooks...)
+		settings.Log.AssertNoErrorF(err, "Could not store checksum for hook")
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatExecutionPlan(t *testing.T) {
+	assert.Equal(t, "Local hooks: none", formatExecutionPlan("Local hooks", nil))
+
+	hs := hooks.HookPrioList{
+		{
+			{IExecutable: &cm.Executable{Cmd: "a.sh"}, NamespacePath: "ns:a/pre-commit/a.sh"},
+			{IExecutable: &cm.Executable{Cmd: "b.sh", Args: []string{"x"}}, NamespacePath: "ns:a/pre-commit/b.sh"},
+		},
+		{
+			{IExecutable: &cm.Executable{Cmd: "c.sh"}, NamespacePath: "ns:a/pre-commit/c.sh"},
+		},
+	}
+
+	assert.Equal(t,
+		"Local hooks:\n"+
+			" Batch 0:\n"+
+			"  • 'ns:a/pre-commit/a.sh' : 'a.sh' []\n"+
+			"  • 'ns:a/pre-commit/b.sh' : 'b.sh' [\"x\"]\n"+
+			" Batch 1:\n"+
+			"  • 'ns:a/pre-commit/c.sh' : 'c.sh' []",
+		formatExecutionPlan("Local hooks", hs))
+}
+
+func TestLimitLines(t *testing.T) {
+	assert.Equal(t, "a\nb", limitLines("a\nb\n", 2))
+	assert.Equal(t, "a\nb\n... '2' more lines, see 'git hooks trust diff'.", limitLines("a\nb\nc\nd\n", 2))
+}
+
+func TestGetOldHookUntrustedProtocolHook(t *testing.T) {
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+
+	repo := t.TempDir()
+	hookDir := path.Join(repo, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hookDir, 0700)) //nolint:mnd
+
+	hookName := "fsmonitor-watchman"
+	require.NoError(t, os.WriteFile(
+		path.Join(hookDir, hooks.GetHookReplacementFileName(hookName)),
+		[]byte("#!/bin/sh\necho 'replaced'\n"), 0700)) //nolint:mnd
+
+	checksums, err := hooks.GetChecksumStorage(path.Join(repo, ".git"))
+	require.NoError(t, err)
+
+	settings := HookSettings{
+		Log:            log,
+		GitX:           git.NewCtxAt(repo),
+		RepositoryDir:  repo,
+		HookName:       hookName,
+		HookDir:        hookDir,
+		NonInteractive: true,
+	}
+	ignores := hooks.RepoIgnorePatterns{}
+	uiSettings := UISettings{}
+
+	// Active, untrusted replaced hooks fail the run...
+	settings.SkipNonExistingSharedHooks = true
+	assert.Panics(t, func() { getOldHook(&settings, &uiSettings, &ignores, &checksums) })
+
+	// ...unless untrusted hooks are skipped.
+	settings.SkipNonExistingSharedHooks = false
+	settings.SkipUntrustedHooks = true
+	assert.Nil(t, getOldHook(&settings, &uiSettings, &ignores, &checksums))
+}
+
+func TestRecordHookResultsSimulated(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+
+	gitDir := t.TempDir()
+	settings := HookSettings{
+		Log:            log,
+		GitX:           git.NewCtxAt(gitDir),
+		GitDirWorktree: gitDir,
+		HookName:       "pre-commit",
+	}
+	res := hooks.HookResult{
+		Hook:     &hooks.Hook{NamespacePath: "ns:a/pre-commit/a.sh"},
+		Duration: time.Second}
+
+	record := func() {
+		logResults := func(res ...hooks.HookResult) {}
+		recordResults, store := recordHookResults(&settings, logResults)
+		recordResults(res)
+		store()
+	}
+
+	// Simulated runs are not part of the timings...
+	settings.Simulate = true
+	record()
+	assert.NoFileExists(t, hooks.GetHookTimingsFile(gitDir))
+
+	// ...but runs by Git are.
+	settings.Simulate = false
+	record()
+	timings, err := hooks.LoadHookTimings(gitDir)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, timings.Hooks["pre-commit"]["ns:a/pre-commit/a.sh"])
+}
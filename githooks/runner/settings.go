@@ -1,14 +1,19 @@
-package main
+package runner
 
 import (
+	"os"
+
 	cm "github.com/gabyx/githooks/githooks/common"
 	"github.com/gabyx/githooks/githooks/container"
 	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
 	strs "github.com/gabyx/githooks/githooks/strings"
 )
 
 // HookSettings defines hooks related settings for this run.
 type HookSettings struct {
+	Log cm.ILogContext // The log context of this run.
+
 	Args               []string       // Rest arguments.
 	ExecX              cm.ExecContext // Execution context for executables (working dir is this repository).
 	GitX               *git.Context   // Git context to execute commands (working dir is this repository).
@@ -17,6 +22,8 @@ type HookSettings struct {
 	GitDirWorktree     string         // Git directory. (for worktrees this points to the worktree Git dir).
 	InstallDir         string         // Install directory.
 
+	UserStateDirs hooks.UserStateDirs // Git directories with the user's trusted checksums and ignores.
+
 	HookPath      string // Absolute path of the hook executing this runner.
 	HookName      string // Name of the hook.
 	HookDir       string // Directory of the hook.
@@ -28,8 +35,33 @@ type HookSettings struct {
 	NonInteractive             bool               // If all non-fatal prompts should be default answered.
 	ContainerMgr               container.IManager // A container manager not nil when hooks should run containerized.
 	Disabled                   bool               // If Githooks has been disabled.
+	ServerMode                 bool               // If Githooks runs a server hook in server mode.
+
+	RefUpdates       []hooks.RefUpdate // The pushed reference updates in server mode.
+	ChangedFilesFile string            // The temporary file where the changed files of each reference are written to.
+
+	PushUpdates     []hooks.PushUpdate // The pushed references on `pre-push`.
+	PushedFilesFile string             // The temporary file where the union of all pushed files is written to.
+
+	StagedFilesFile string   // The temporary file where all staged files are written to.
+	StagedFiles     *string  // The staged files to export, if `nil` the staged files in the index are used.
+	StagedFileList  []string // The exported staged files.
+
+	Conditions *hooks.ConditionContext // The context to evaluate `when` conditions of hooks.
+
+	Simulate bool   // If the hook invocation is simulated.
+	DryRun   bool   // If hooks are only shown and not executed.
+	Stdin    []byte // The standard input for all hooks, if `nil` the standard input is used.
+}
+
+// getStdin returns the pipe setup for the standard input of a hook.
+// Each call gets its own reader when the standard input is given.
+func (s *HookSettings) getStdin() cm.PipeSetupFunc {
+	if s.Stdin == nil {
+		return cm.UseOnlyStdin(os.Stdin)
+	}
 
-	StagedFilesFile string // The temporary file where all staged files are written to.
+	return hooks.ReplayStdin(s.Stdin)
 }
 
 func (s HookSettings) toString() string {
@@ -1,4 +1,4 @@
-package main
+package runner
 
 import (
 	hooks "github.com/gabyx/githooks/githooks/hooks"
@@ -0,0 +1,146 @@
+package updates
+
+import (
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-version"
+)
+
+// UpdateChannelType is the type of an update channel.
+type UpdateChannelType string
+
+const (
+	// StableChannel gets all releases respecting the rollout percentages.
+	StableChannel UpdateChannelType = "stable"
+	// CanaryChannel gets all releases including prereleases immediately.
+	CanaryChannel UpdateChannelType = "canary"
+	// PinnedChannel gets releases up to and including a pinned version.
+	PinnedChannel UpdateChannelType = "pinned"
+)
+
+// UpdateChannel is the update channel which decides
+// which versions are considered as updates.
+type UpdateChannel struct {
+	Type UpdateChannelType
+
+	// The pinned version for `PinnedChannel`.
+	PinnedVersion *version.Version
+}
+
+// String returns the config value of the update channel.
+func (c UpdateChannel) String() string {
+	if c.Type == PinnedChannel {
+		return strs.Fmt("%s:%s", c.Type, c.PinnedVersion.Original())
+	}
+
+	return string(c.Type)
+}
+
+// ParseUpdateChannel parses an update channel from `value`, which
+// is one of `stable`, `canary` or `pinned:<version>`.
+// An empty value is the `stable` channel.
+func ParseUpdateChannel(value string) (channel UpdateChannel, err error) {
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strs.IsEmpty(value) || value == string(StableChannel):
+		channel.Type = StableChannel
+	case value == string(CanaryChannel):
+		channel.Type = CanaryChannel
+	case strings.HasPrefix(value, string(PinnedChannel)+":"):
+		channel.Type = PinnedChannel
+		channel.PinnedVersion, err = version.NewVersion(
+			strings.TrimPrefix(value, string(PinnedChannel)+":"))
+
+		if err != nil {
+			err = cm.CombineErrors(cm.ErrorF("Could not parse pinned version in update channel '%s'.", value), err)
+		}
+	default:
+		err = cm.ErrorF("Update channel '%s' is not one of 'stable', 'canary' or 'pinned:<version>'.", value)
+	}
+
+	return
+}
+
+// GetUpdateChannel gets the update channel from the global Git config.
+func GetUpdateChannel(gitx *git.Context) (UpdateChannel, error) {
+	return ParseUpdateChannel(gitx.GetConfig(hooks.GitCKUpdateChannel, git.GlobalScope))
+}
+
+// SetUpdateChannel sets the update channel in the global Git config.
+func SetUpdateChannel(gitx *git.Context, channel UpdateChannel) error {
+	return gitx.SetConfig(hooks.GitCKUpdateChannel, channel.String(), git.GlobalScope)
+}
+
+// ResetUpdateChannel resets the update channel in the global Git config.
+func ResetUpdateChannel(gitx *git.Context) error {
+	return gitx.UnsetConfig(hooks.GitCKUpdateChannel, git.GlobalScope)
+}
+
+// SetupUpdateRolloutID creates the unique id of this installation used to
+// decide if it belongs to a partial rollout, if it does not exist yet.
+func SetupUpdateRolloutID(gitx *git.Context) (id string, err error) {
+	id = gitx.GetConfig(hooks.GitCKUpdateRolloutID, git.GlobalScope)
+	if strs.IsNotEmpty(id) {
+		return id, nil
+	}
+
+	id = uuid.New().String()
+	err = gitx.SetConfig(hooks.GitCKUpdateRolloutID, id, git.GlobalScope)
+
+	return id, err
+}
+
+// getUpdateRolloutID gets the unique id of this installation used to
+// decide if it belongs to a partial rollout. If it cannot be stored
+// (e.g. read-only global Git config), a non-persisted id
+// derived from the host and the user is used.
+func getUpdateRolloutID(gitx *git.Context) string {
+	id, err := SetupUpdateRolloutID(gitx)
+	if err == nil {
+		return id
+	}
+
+	host, _ := os.Hostname()
+	home, _ := os.UserHomeDir()
+
+	return strs.Fmt("host:%s:%s", host, home)
+}
+
+// getRolloutBucket returns the bucket in [0, 100) of the installation with `id`
+// for the release `tag`. Including the tag distributes the installations
+// differently for each release.
+func getRolloutBucket(id string, tag string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id + "/" + tag))
+
+	return int(h.Sum32() % 100) //nolint:mnd
+}
+
+var updateRolloutTrailerRe = regexp.MustCompile(`(?m)^Update-Rollout: *(\d+) *%? *$`)
+
+// getRolloutPercentage parses the rollout percentage from the tag message `mess`.
+// If no trailer is present, `100` is returned.
+func getRolloutPercentage(mess string) (int, error) {
+	match := updateRolloutTrailerRe.FindStringSubmatch(mess)
+	if match == nil {
+		return 100, nil //nolint:mnd
+	}
+
+	p, err := strconv.Atoi(match[1])
+	if err != nil || p > 100 {
+		return 0, cm.ErrorF("Rollout percentage '%s' is invalid.", match[1])
+	}
+
+	return p, nil
+}
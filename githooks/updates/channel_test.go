@@ -0,0 +1,139 @@
+package updates
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpdateChannel(t *testing.T) {
+	c, err := ParseUpdateChannel("")
+	require.NoError(t, err)
+	assert.Equal(t, StableChannel, c.Type)
+
+	c, err = ParseUpdateChannel("canary")
+	require.NoError(t, err)
+	assert.Equal(t, CanaryChannel, c.Type)
+
+	c, err = ParseUpdateChannel("pinned:v3.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, PinnedChannel, c.Type)
+	assert.Equal(t, "3.1.0", c.PinnedVersion.String())
+	assert.Equal(t, "pinned:v3.1.0", c.String())
+
+	_, err = ParseUpdateChannel("pinned:")
+	assert.Error(t, err)
+
+	_, err = ParseUpdateChannel("nightly")
+	assert.Error(t, err)
+}
+
+func TestRolloutPercentage(t *testing.T) {
+	p, err := getRolloutPercentage("Release\n\nUpdate-Info: Bla\n")
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+
+	p, err = getRolloutPercentage("Release\n\nUpdate-Rollout: 25%\n")
+	require.NoError(t, err)
+	assert.Equal(t, 25, p)
+
+	p, err = getRolloutPercentage("Release\n\nUpdate-Rollout: 0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+
+	_, err = getRolloutPercentage("Release\n\nUpdate-Rollout: 120%")
+	assert.Error(t, err)
+
+	b := getRolloutBucket("id", "v1.0.0")
+	assert.Equal(t, b, getRolloutBucket("id", "v1.0.0"))
+	assert.True(t, b >= 0 && b < 100)
+}
+
+func setupReleaseRepo(t *testing.T, tags map[string]string, order []string) (*git.Context, string) {
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	gitx := git.NewCtxSanitizedAt(dir)
+
+	require.NoError(t, gitx.Check("init", "-q"))
+	require.NoError(t, gitx.Check("config", "user.name", "Test"))
+	require.NoError(t, gitx.Check("config", "user.email", "test@example.com"))
+	require.NoError(t, gitx.Check("commit", "-q", "--allow-empty", "-m", "Init"))
+
+	first, err := gitx.Get("rev-parse", "HEAD")
+	require.NoError(t, err)
+
+	for _, tag := range order {
+		require.NoError(t, os.WriteFile(path.Join(dir, "file"), []byte(tag), 0600)) //nolint:mnd
+		require.NoError(t, gitx.Check("add", "file"))
+		require.NoError(t, gitx.Check("commit", "-q", "-m", "Version "+tag))
+		require.NoError(t, gitx.Check("tag", "-a", tag, "-m", tags[tag]))
+	}
+
+	return gitx, first
+}
+
+func TestUpdateChannels(t *testing.T) {
+	tags := map[string]string{
+		"v1.1.0":       "Release",
+		"v1.2.0-rc1":   "Prerelease",
+		"v1.2.0":       "Release\n\nUpdate-Rollout: 0%",
+		"v1.3.0":       "Release\n\nUpdate-Rollout: 100%",
+		"v1.4.0":       "Release\n\nUpdate-Rollout: 0%\nUpdate-NoSkip: true",
+		"v1.5.0":       "Release",
+		"v1.6.0-beta1": "Prerelease",
+	}
+	order := []string{"v1.1.0", "v1.2.0-rc1", "v1.2.0", "v1.3.0", "v1.4.0", "v1.5.0", "v1.6.0-beta1"}
+
+	gitx, first := setupReleaseRepo(t, tags, order)
+
+	get := func(channel string) string {
+		c, err := ParseUpdateChannel(channel)
+		require.NoError(t, err)
+		_, tag, _, _, err := getNewUpdateCommit(gitx, first, "HEAD", false, c)
+		require.NoError(t, err)
+
+		return tag
+	}
+
+	// Stable stops before the not rolled out non-skippable version.
+	assert.Equal(t, "v1.3.0", get("stable"))
+	assert.NotEmpty(t, gitx.GetConfig(hooks.GitCKUpdateRolloutID, git.GlobalScope))
+
+	// Canary ignores rollouts but still stops at non-skippable versions.
+	assert.Equal(t, "v1.4.0", get("canary"))
+	assert.Equal(t, "v1.2.0", get("pinned:1.2.0"))
+	assert.Equal(t, "v1.2.0-rc1", get("pinned:1.2.0-rc1"))
+	assert.Equal(t, "v1.4.0", get("pinned:1.5.5"))
+
+	// Starting after the non-skippable version.
+	first, err := gitx.Get("rev-parse", "v1.4.0")
+	require.NoError(t, err)
+	c, err := ParseUpdateChannel("canary")
+	require.NoError(t, err)
+	_, tag, _, _, err := getNewUpdateCommit(gitx, first, "HEAD", false, c)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.6.0-beta1", tag)
+}
+
+func TestUpdateRolloutIDReadOnlyConfig(t *testing.T) {
+	// The global Git config cannot be written.
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), "missing", ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	gitx := git.NewCtxAt(t.TempDir())
+
+	_, err := SetupUpdateRolloutID(gitx)
+	assert.Error(t, err)
+
+	id := getUpdateRolloutID(gitx)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, getUpdateRolloutID(gitx))
+}
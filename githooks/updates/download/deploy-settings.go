@@ -1,7 +1,11 @@
 package download
 
 import (
+	"bytes"
 	"path"
+	"runtime"
+	"strings"
+	"text/template"
 
 	cm "github.com/gabyx/githooks/githooks/common"
 )
@@ -15,6 +19,7 @@ type deploySettings struct {
 	Github *GithubDeploySettings `yaml:"github"`
 	HTTP   *HTTPDeploySettings   `yaml:"http"`
 	Local  *LocalDeploySettings  `yaml:"local"`
+	OCI    *OCIDeploySettings    `yaml:"oci"`
 }
 
 const deploySettingsVersion = 1
@@ -48,6 +53,8 @@ func LoadDeploySettings(file string) (IDeploySettings, error) {
 		return settings.HTTP, nil
 	case settings.Local != nil:
 		return settings.Local, nil
+	case settings.OCI != nil:
+		return settings.OCI, nil
 	}
 
 	return nil, nil
@@ -69,6 +76,8 @@ func StoreDeploySettings(file string, settings IDeploySettings) error {
 		s.HTTP = v
 	case *LocalDeploySettings:
 		s.Local = v
+	case *OCIDeploySettings:
+		s.OCI = v
 	default:
 		cm.PanicF("Cannot store deploy settings for type '%T'", v)
 	}
@@ -80,3 +89,32 @@ func StoreDeploySettings(file string, settings IDeploySettings) error {
 func GetDeploySettingsFile(installDir string) string {
 	return path.Join(installDir, "deploy.yaml")
 }
+
+// formatDeployTemplate formats the deploy template `tmpl` for version `versionTag`.
+// The variables can be used as `{{VersionTag}}` or `{{.VersionTag}}`.
+func formatDeployTemplate(tmpl string, versionTag string) (string, error) {
+	vars := map[string]string{
+		"VersionTag": versionTag,
+		"Version":    strings.TrimPrefix(versionTag, "v"),
+		"Os":         runtime.GOOS,
+		"Arch":       runtime.GOARCH,
+	}
+
+	funcs := template.FuncMap{}
+	for k, v := range vars {
+		funcs[k] = func() string { return v }
+	}
+
+	t, err := template.New("").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", cm.CombineErrors(err, cm.ErrorF("Could not parse template '%s'.", tmpl))
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, vars)
+	if err != nil {
+		return "", cm.ErrorF("Could not format template '%s'.", tmpl)
+	}
+
+	return buf.String(), nil
+}
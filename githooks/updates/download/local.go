@@ -2,10 +2,8 @@ package download
 
 import (
 	"bytes"
-	"html/template"
 	"os"
 	"path"
-	"runtime"
 	"strings"
 
 	cm "github.com/gabyx/githooks/githooks/common"
@@ -41,27 +39,11 @@ func (s *LocalDeploySettings) Download(
 	dir string,
 	token string,
 ) error {
-	// Copy everything to director `dir`
-	pathTmpl := template.Must(template.New("").Parse(s.PathTemplate))
-
-	var buf bytes.Buffer
-	err := pathTmpl.Execute(&buf, struct {
-		VersionTag string
-		Version    string
-		Os         string
-		Arch       string
-	}{
-		VersionTag: versionTag,
-		Version:    strings.TrimPrefix(versionTag, "v"),
-		Os:         runtime.GOOS,
-		Arch:       runtime.GOARCH,
-	})
-
+	targetFile, err := formatDeployTemplate(s.PathTemplate, versionTag)
 	if err != nil {
-		return cm.ErrorF("Could not format path template '%s'.", s.PathTemplate)
+		return err
 	}
 
-	targetFile := buf.String()
 	targetExtension := ""
 	switch {
 	case strings.HasSuffix(targetFile, ".tar.gz"):
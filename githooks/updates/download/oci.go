@@ -0,0 +1,334 @@
+package download
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	ref "github.com/distribution/reference"
+	cm "github.com/gabyx/githooks/githooks/common"
+	strs "github.com/gabyx/githooks/githooks/strings"
+	"github.com/opencontainers/go-digest"
+)
+
+// OCIDeploySettings are deploy settings for an OCI registry.
+type OCIDeploySettings struct {
+	// Reference template string to the release artifact, e.g.
+	// `registry.company.com/tools/githooks:{{VersionTag}}`, which can contain
+	// - `{{VersionTag}}` : The version tag to download.
+	// - `{{Version}}` : The version to download (removed prefix 'v' of `VersionTag`).
+	// - `{{Os}}` : The `runtime.GOOS` variable with the operating system.
+	// - `{{Arch}}` : The `runtime.GOARCH` for type architecture.
+	// The resolved artifact manifest needs to contain one layer per release asset
+	// (e.g. as pushed by `oras push`) where the file name is given by the
+	// annotation `org.opencontainers.image.title`. Next to the compressed archive of
+	// the Githooks binaries a checksum file
+	// `githooks.checksums`
+	// and a checksum signature file
+	// `githooks.checksums.sig` need to be present, which is validated using
+	// the `PublicPGP`.
+	ReferenceTemplate string
+
+	// If `true`, the registry is accessed over plain HTTP instead of HTTPS.
+	Insecure bool
+
+	// If empty, the internal Githooks binary
+	// embedded PGP is taken from `.deploy.pgp`.
+	PublicPGP string
+}
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ociAnnotationTitle      = "org.opencontainers.image.title"
+	ociDockerHubDomain      = "docker.io"
+	ociDockerHubRegistryAPI = "registry-1.docker.io"
+)
+
+// The parameters `key="value"` of a `WWW-Authenticate` challenge.
+var reOCIChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+// Download downloads the version with `versionTag` into `dir` from an OCI registry.
+// The `token` is an optional bearer token used to authenticate the registry
+// requests. If empty, unauthenticated requests are made.
+// If the registry challenges the requests (e.g. Docker Hub, also for public
+// repositories), a token is requested from the announced authorization
+// service, authenticated with `token` if given.
+func (s *OCIDeploySettings) Download(
+	log cm.ILogContext,
+	versionTag string,
+	dir string,
+	token string,
+) error {
+	reference, err := formatDeployTemplate(s.ReferenceTemplate, versionTag)
+	if err != nil {
+		return err
+	}
+
+	named, err := ref.ParseNormalizedNamed(reference)
+	if err != nil {
+		return cm.CombineErrors(err,
+			cm.ErrorF("Could not parse OCI reference '%s'.", reference))
+	}
+
+	scheme := "https"
+	if s.Insecure {
+		scheme = "http"
+	}
+
+	domain := ref.Domain(named)
+	if domain == ociDockerHubDomain {
+		domain = ociDockerHubRegistryAPI
+	}
+	repoURL := scheme + "://" + domain + "/v2/" + ref.Path(named)
+
+	tagOrDigest := "latest"
+	if d, ok := named.(ref.Digested); ok {
+		tagOrDigest = d.Digest().String()
+	} else if t, ok := named.(ref.Tagged); ok {
+		tagOrDigest = t.Tag()
+	}
+
+	log.InfoF("Resolve OCI artifact '%s'.", reference)
+	manifest, token, err := getOCIManifest(repoURL+"/manifests/"+tagOrDigest, token)
+	if err != nil {
+		return cm.CombineErrors(err,
+			cm.ErrorF("Could not get manifest for OCI artifact '%s'.", reference))
+	}
+
+	// Wrap into our list
+	var assets []Asset
+	digests := make(map[string]string)
+	for i := range manifest.Layers {
+		name := manifest.Layers[i].Annotations[ociAnnotationTitle]
+		if strs.IsEmpty(name) {
+			continue
+		}
+
+		blobURL := repoURL + "/blobs/" + manifest.Layers[i].Digest
+		digests[blobURL] = manifest.Layers[i].Digest
+
+		assets = append(assets,
+			Asset{
+				FileName: path.Base(name),
+				URL:      blobURL})
+	}
+
+	target, checksums, err := getGithooksAsset(assets)
+	if err != nil {
+		return cm.CombineErrors(
+			err,
+			cm.ErrorF("Could not select asset in OCI artifact '%s'.", reference),
+		)
+	}
+
+	log.InfoF("Verify signature of checksum file '%s'.", checksums.File.URL)
+	checksumData, err := verifyChecksumSignature(checksums, s.PublicPGP, token)
+	if err != nil {
+		return cm.CombineErrors(err,
+			cm.ErrorF("Signature verification of update failed."+
+				"Something is fishy!"))
+	}
+
+	log.InfoF("Downloading file '%s'.", target.URL)
+	response, err := GetFile(target.URL, token)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not download url '%s'.", target.URL))
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	// Store into temp. file.
+	err = os.MkdirAll(dir, cm.DefaultFileModeDirectory)
+	if err != nil {
+		return cm.ErrorF("Could create dir '%s'.", dir)
+	}
+
+	temp, err := os.CreateTemp(dir, "*-"+target.FileName)
+	if err != nil {
+		return cm.ErrorF("Could open temp file '%s' for download.", target.FileName)
+	}
+	_, err = io.Copy(temp, response.Body)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not store download in '%s'.", temp.Name()))
+	}
+	_ = temp.Close()
+
+	// Validate the digest of the layer in the manifest.
+	log.InfoF("Validate digest '%s'.", digests[target.URL])
+	err = checkOCIDigest(temp.Name(), digests[target.URL])
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Digest validation failed."))
+	}
+
+	// Validate checksum.
+	log.InfoF("Validate checksums.")
+	err = checkChecksum(temp.Name(), checksumData)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Checksum validation failed."))
+	}
+
+	// Extract the file.
+	err = Extract(temp.Name(), target.Extension, dir)
+	if err != nil {
+		return cm.CombineErrors(err,
+			cm.ErrorF("Archive extraction from url '%s' failed.", target.URL))
+	}
+
+	return nil
+}
+
+// getOCIManifest gets the OCI image manifest from `url`.
+// If the registry answers with a bearer token challenge, a token is requested
+// and returned in `authToken` for all further requests to the repository.
+func getOCIManifest(url string, token string) (manifest ociManifest, authToken string, err error) {
+	authToken = token
+
+	response, err := requestOCIManifest(url, authToken)
+	if err != nil {
+		return
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		challenge := response.Header.Get("WWW-Authenticate")
+		_ = response.Body.Close()
+
+		authToken, err = getOCIRegistryToken(challenge, token)
+		if err != nil {
+			err = cm.CombineErrors(err,
+				cm.ErrorF("Could not authenticate request of manifest '%s'.", url))
+
+			return
+		}
+
+		response, err = requestOCIManifest(url, authToken)
+		if err != nil {
+			return
+		}
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		err = cm.ErrorF("Request of manifest '%s' failed with status: '%v'.",
+			url, response.StatusCode)
+
+		return
+	}
+
+	err = cm.ReadJSON(response.Body, &manifest)
+	if err != nil {
+		return
+	}
+
+	if manifest.MediaType != "" && manifest.MediaType != ociManifestMediaType {
+		err = cm.ErrorF("Manifest media type '%s' is not supported.", manifest.MediaType)
+	}
+
+	return
+}
+
+func requestOCIManifest(url string, token string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", ociManifestMediaType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+// getOCIRegistryToken gets a token from the authorization service announced
+// in the challenge `WWW-Authenticate: Bearer realm="...",service="...",scope="..."`
+// of the registry. The request is authenticated with `token` if not empty.
+func getOCIRegistryToken(challenge string, token string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", cm.ErrorF("Authentication challenge '%s' is not supported.", challenge)
+	}
+
+	values := make(map[string]string)
+	for _, match := range reOCIChallengeParam.FindAllStringSubmatch(params, -1) {
+		values[strings.ToLower(match[1])] = match[2]
+	}
+
+	realm, err := url.Parse(values["realm"])
+	if err != nil || strs.IsEmpty(values["realm"]) {
+		return "", cm.ErrorF("Authentication challenge '%s' has no valid realm.", challenge)
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if strs.IsNotEmpty(values[key]) {
+			query.Set(key, values[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	response, err := GetFile(realm.String(), token)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	var result struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	err = cm.ReadJSON(response.Body, &result)
+	if err != nil {
+		return "", cm.CombineErrors(err, cm.ErrorF("Could not read token from '%s'.", realm.Redacted()))
+	}
+
+	if strs.IsNotEmpty(result.Token) {
+		return result.Token, nil
+	} else if strs.IsNotEmpty(result.AccessToken) {
+		return result.AccessToken, nil
+	}
+
+	return "", cm.ErrorF("No token received from '%s'.", realm.Redacted())
+}
+
+// checkOCIDigest checks if the content of file `filePath` matches the digest `d`.
+func checkOCIDigest(filePath string, d string) error {
+	dgst, err := digest.Parse(d)
+	if err != nil {
+		return cm.CombineErrors(err, cm.ErrorF("Could not parse digest '%s'.", d))
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	verifier := dgst.Verifier()
+	if _, err = io.Copy(verifier, file); err != nil {
+		return err
+	}
+
+	if !verifier.Verified() {
+		return cm.ErrorF("File '%s' does not match digest '%s'.", filePath, d)
+	}
+
+	return nil
+}
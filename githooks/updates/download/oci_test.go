@@ -0,0 +1,217 @@
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+)
+
+func createTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0755, Size: int64(len(content))})) //nolint:mnd
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func sha256Digest(data []byte) string {
+	h := sha256.Sum256(data)
+
+	return hex.EncodeToString(h[:])
+}
+
+type ociStandInOptions struct {
+	// Challenge all registry requests without the token `"test-token"`
+	// with a bearer token challenge.
+	challenge bool
+	// Serve wrong content for the blob with this name.
+	corruptBlob string
+}
+
+// ociRegistryStandIn serves `blobs` over the OCI distribution API under
+// repository `githooks` with a manifest for tag `tag`.
+func ociRegistryStandIn(
+	t *testing.T,
+	tag string,
+	blobs map[string][]byte,
+	opts ociStandInOptions) *httptest.Server {
+	var layers []ociDescriptor
+	byDigest := make(map[string][]byte)
+
+	for name, data := range blobs {
+		digest := "sha256:" + sha256Digest(data)
+		byDigest[digest] = data
+		if name == opts.corruptBlob {
+			byDigest[digest] = append([]byte("corrupt"), data...)
+		}
+		layers = append(layers, ociDescriptor{
+			MediaType:   "application/octet-stream",
+			Digest:      digest,
+			Size:        int64(len(data)),
+			Annotations: map[string]string{ociAnnotationTitle: name}})
+	}
+
+	var manifest bytes.Buffer
+	require.NoError(t, cm.WriteJSON(&manifest,
+		ociManifest{SchemaVersion: 2, MediaType: ociManifestMediaType, Layers: layers}))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("service") != "registry" ||
+				r.URL.Query().Get("scope") != "repository:githooks:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "test-token"}`))
+
+			return
+		}
+
+		if opts.challenge && r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="`+server.URL+`/token",service="registry",scope="repository:githooks:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/v2/githooks/manifests/"+tag:
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			_, _ = w.Write(manifest.Bytes())
+		case strings.HasPrefix(r.URL.Path, "/v2/githooks/blobs/"):
+			data, ok := byDigest[strings.TrimPrefix(r.URL.Path, "/v2/githooks/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+			_, _ = w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func TestOCIDownload(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", nil)
+	require.NoError(t, err)
+
+	var publicKey bytes.Buffer
+	w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	targetOs := runtime.GOOS
+	if targetOs == "darwin" {
+		targetOs = "macos"
+	}
+
+	archiveName := "githooks-3.1.0-" + targetOs + "." + runtime.GOARCH + ".tar.gz"
+	archive := createTarGz(t, map[string]string{"githooks-cli": "cli", "githooks-runner": "runner"})
+	checksums := []byte(sha256Digest(archive) + "  " + archiveName + "\n")
+
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.DetachSign(&signature, entity, bytes.NewReader(checksums), nil))
+
+	blobs := map[string][]byte{
+		archiveName:                   archive,
+		githooksChecksumFile:          checksums,
+		githooksChecksumSignatureFile: signature.Bytes()}
+
+	server := ociRegistryStandIn(t, "v3.1.0", blobs, ociStandInOptions{})
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	settings := OCIDeploySettings{
+		ReferenceTemplate: host + "/githooks:{{VersionTag}}",
+		Insecure:          true,
+		PublicPGP:         publicKey.String()}
+
+	log, err := cm.CreateLogContext(false, false)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	err = settings.Download(log, "v3.1.0", dir, "")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path.Join(dir, "githooks-runner"))
+	require.NoError(t, err)
+	assert.Equal(t, "runner", string(content))
+
+	// Non-existing version.
+	err = settings.Download(log, "v3.2.0", t.TempDir(), "")
+	assert.Error(t, err)
+
+	// Wrong public key.
+	other, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+	require.NoError(t, err)
+	var otherKey bytes.Buffer
+	w, err = armor.Encode(&otherKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, other.Serialize(w))
+	require.NoError(t, w.Close())
+
+	settings.PublicPGP = otherKey.String()
+	err = settings.Download(log, "v3.1.0", t.TempDir(), "")
+	assert.Error(t, err)
+	settings.PublicPGP = publicKey.String()
+
+	// Registry with a token challenge.
+	challenged := ociRegistryStandIn(t, "v3.1.0", blobs, ociStandInOptions{challenge: true})
+	defer challenged.Close()
+
+	settings.ReferenceTemplate = strings.TrimPrefix(challenged.URL, "http://") + "/githooks:{{VersionTag}}"
+	dir = t.TempDir()
+	err = settings.Download(log, "v3.1.0", dir, "")
+	require.NoError(t, err)
+	assert.FileExists(t, path.Join(dir, "githooks-cli"))
+
+	// Layer not matching the digest of the manifest.
+	corrupted := ociRegistryStandIn(t, "v3.1.0", blobs, ociStandInOptions{corruptBlob: archiveName})
+	defer corrupted.Close()
+
+	settings.ReferenceTemplate = strings.TrimPrefix(corrupted.URL, "http://") + "/githooks:{{VersionTag}}"
+	dir = t.TempDir()
+	err = settings.Download(log, "v3.1.0", dir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Digest validation failed")
+	assert.NoFileExists(t, path.Join(dir, "githooks-cli"))
+}
+
+func TestDeployTemplate(t *testing.T) {
+	s, err := formatDeployTemplate("a/{{VersionTag}}/{{.Version}}", "v1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, "a/v1.2.3/1.2.3", s)
+}
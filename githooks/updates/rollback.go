@@ -0,0 +1,144 @@
+package updates
+
+import (
+	"os"
+	"path"
+
+	cm "github.com/gabyx/githooks/githooks/common"
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+	strs "github.com/gabyx/githooks/githooks/strings"
+)
+
+// RollbackInfo holds the data to roll back an update
+// to the previously installed version.
+type RollbackInfo struct {
+	// The previous version and its release clone commit.
+	Version   string `yaml:"version"`
+	Tag       string `yaml:"tag"`
+	CommitSHA string `yaml:"commitSHA"`
+
+	// The version which replaced the previous version.
+	UpdatedToVersion string `yaml:"updatedToVersion"`
+}
+
+// GetRollbackDir gets the directory inside the install directory
+// where the previous version is kept.
+func GetRollbackDir(installDir string) string {
+	return path.Join(installDir, "rollback")
+}
+
+func getRollbackBinaryDir(installDir string) string {
+	return path.Join(GetRollbackDir(installDir), "bin")
+}
+
+func getRollbackFile(installDir string) string {
+	return path.Join(GetRollbackDir(installDir), "rollback.yaml")
+}
+
+// StoreRollback keeps the currently installed binaries and the
+// current release clone commit to be able to roll back
+// the update from `fromVersion` to `toVersion`.
+// Nothing is stored if no binaries are installed.
+func StoreRollback(installDir string, fromVersion string, toVersion string) error {
+	binDir := hooks.GetBinaryDir(installDir)
+	if !cm.IsDirectory(binDir) {
+		return nil
+	}
+
+	binaries, err := cm.GetFiles(binDir, nil)
+	if err != nil || len(binaries) == 0 {
+		return err
+	}
+
+	gitx := git.NewCtxSanitizedAt(hooks.GetReleaseCloneDir(installDir))
+	commitSHA, err := gitx.Get("rev-parse", git.HEAD)
+	if err != nil {
+		return cm.CombineErrors(cm.Error("Could not get commit of release clone."), err)
+	}
+	_, tag, _ := git.GetVersionAt(gitx, commitSHA)
+
+	rollbackDir := GetRollbackDir(installDir)
+	if err = os.RemoveAll(rollbackDir); err != nil {
+		return err
+	}
+
+	if err = cm.CopyFileOrDirectory(binDir, getRollbackBinaryDir(installDir)); err != nil {
+		return cm.CombineErrors(cm.ErrorF("Could not copy binaries to '%s'.", rollbackDir), err)
+	}
+
+	return cm.StoreYAML(getRollbackFile(installDir),
+		&RollbackInfo{
+			Version:          fromVersion,
+			Tag:              tag,
+			CommitSHA:        commitSHA,
+			UpdatedToVersion: toVersion})
+}
+
+// LoadRollback loads the rollback info if existing.
+func LoadRollback(installDir string) (info RollbackInfo, exists bool, err error) {
+	file := getRollbackFile(installDir)
+	if !cm.IsFile(file) {
+		return
+	}
+
+	err = cm.LoadYAML(file, &info)
+	exists = err == nil && strs.IsNotEmpty(info.CommitSHA)
+
+	return
+}
+
+// RunRollback restores the binaries and the release clone to the previous version
+// stored in `info` and blocks updates to the rolled back version.
+// Replaced binaries are moved to `backupDir`.
+// The stored rollback is removed afterwards.
+func RunRollback(installDir string, info *RollbackInfo, backupDir string) error {
+	binDir := hooks.GetBinaryDir(installDir)
+
+	binaries, err := cm.GetFiles(getRollbackBinaryDir(installDir), nil)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(backupDir, cm.DefaultFileModeDirectory); err != nil {
+		return err
+	}
+
+	for _, binary := range binaries {
+		dest := path.Join(binDir, path.Base(binary))
+
+		if err = cm.CopyFileWithBackup(binary, dest, backupDir, false); err != nil {
+			return cm.CombineErrors(cm.ErrorF("Could not restore binary '%s'.", dest), err)
+		}
+	}
+
+	// Reset the release clone to the previous commit.
+	// The remote branch is not touched.
+	gitx := git.NewCtxSanitizedAt(hooks.GetReleaseCloneDir(installDir))
+	if err = gitx.Check("reset", "--hard", info.CommitSHA); err != nil {
+		return cm.CombineErrors(
+			cm.ErrorF("Could not reset release clone to '%s'.", info.CommitSHA), err)
+	}
+
+	if strs.IsNotEmpty(info.UpdatedToVersion) {
+		if err = BlockUpdateVersion(gitx, info.UpdatedToVersion); err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(GetRollbackDir(installDir))
+}
+
+// BlockUpdateVersion blocks any update to version `ver`.
+func BlockUpdateVersion(gitx *git.Context, ver string) error {
+	if strs.Includes(GetBlockedUpdateVersions(gitx), ver) {
+		return nil
+	}
+
+	return gitx.AddConfig(hooks.GitCKUpdateBlockedVersions, ver, git.GlobalScope)
+}
+
+// GetBlockedUpdateVersions gets all versions which are never considered as updates.
+func GetBlockedUpdateVersions(gitx *git.Context) []string {
+	return gitx.GetConfigAll(hooks.GitCKUpdateBlockedVersions, git.GlobalScope)
+}
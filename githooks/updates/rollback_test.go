@@ -0,0 +1,63 @@
+package updates
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gabyx/githooks/githooks/git"
+	"github.com/gabyx/githooks/githooks/hooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollback(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", path.Join(t.TempDir(), ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	installDir := t.TempDir()
+	binDir := hooks.GetBinaryDir(installDir)
+	require.NoError(t, os.MkdirAll(binDir, 0755))                                            //nolint:mnd
+	require.NoError(t, os.WriteFile(path.Join(binDir, "githooks-cli"), []byte("old"), 0755)) //nolint:mnd
+
+	cloneDir := hooks.GetReleaseCloneDir(installDir)
+	gitx := git.NewCtxSanitizedAt(cloneDir)
+	require.NoError(t, os.MkdirAll(cloneDir, 0755)) //nolint:mnd
+	require.NoError(t, gitx.Check("init", "-q"))
+	require.NoError(t, gitx.Check("config", "user.name", "Test"))
+	require.NoError(t, gitx.Check("config", "user.email", "test@example.com"))
+	require.NoError(t, gitx.Check("commit", "-q", "--allow-empty", "-m", "Old"))
+	require.NoError(t, gitx.Check("tag", "v3.0.0"))
+	oldSHA, err := gitx.Get("rev-parse", "HEAD")
+	require.NoError(t, err)
+
+	_, exists, err := LoadRollback(installDir)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Update...
+	require.NoError(t, StoreRollback(installDir, "3.0.0", "3.1.0"))
+	require.NoError(t, os.WriteFile(path.Join(binDir, "githooks-cli"), []byte("new"), 0755)) //nolint:mnd
+	require.NoError(t, gitx.Check("commit", "-q", "--allow-empty", "-m", "New"))
+
+	info, exists, err := LoadRollback(installDir)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, "v3.0.0", info.Tag)
+	assert.Equal(t, oldSHA, info.CommitSHA)
+
+	// Rollback...
+	require.NoError(t, RunRollback(installDir, &info, path.Join(installDir, "tmp")))
+
+	content, err := os.ReadFile(path.Join(binDir, "githooks-cli"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+
+	sha, err := gitx.Get("rev-parse", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, oldSHA, sha)
+
+	assert.Equal(t, []string{"3.1.0"}, GetBlockedUpdateVersions(gitx))
+	assert.NoDirExists(t, GetRollbackDir(installDir))
+}
@@ -31,6 +31,7 @@ type ReleaseStatus struct {
 	UpdateTag         string           // The update tag.
 	UpdateVersion     *version.Version // The update version.
 	UpdateInfo        []string         // The update info read from the commit.
+	UpdateChannel     UpdateChannel    // The update channel used to determine the update.
 
 	Branch       string
 	RemoteBranch string
@@ -77,7 +78,15 @@ func getNewUpdateCommit(
 	firstSHA string,
 	lastSHA string,
 	usePreRelease bool,
+	channel UpdateChannel,
 ) (commitF string, tagF string, versionF *version.Version, infoF []string, err error) {
+	if channel.Type == CanaryChannel {
+		usePreRelease = true
+	}
+
+	rolloutID := ""
+	blocked := GetBlockedUpdateVersions(gitx)
+
 	// Get all commits in (firstSHA, lastSHA]
 	commits, err := gitx.GetCommits(firstSHA, lastSHA)
 	if err != nil {
@@ -97,7 +106,14 @@ func getNewUpdateCommit(
 			return commitF, tagF, versionF, infoF, err
 		case version == nil || strs.IsEmpty(tag):
 			continue // no version tag on this commit
-		case !usePreRelease && strs.IsNotEmpty(version.Prerelease()):
+		case strs.Includes(blocked, version.String()):
+			// Skipping blocked version (e.g. rolled back).
+			continue
+		case channel.Type == PinnedChannel && version.GreaterThan(channel.PinnedVersion):
+			// Never update beyond the pinned version.
+			continue
+		case !usePreRelease && strs.IsNotEmpty(version.Prerelease()) &&
+			!(channel.Type == PinnedChannel && version.Equal(channel.PinnedVersion)):
 			// Skipping prerelease version
 			continue
 		}
@@ -111,6 +127,30 @@ func getNewUpdateCommit(
 			return commitF, tagF, versionF, infoF, err
 		}
 
+		// Check if this installation is part of the rollout
+		// of this version. Only the stable channel respects rollouts.
+		if channel.Type == StableChannel {
+			percentage, e := getRolloutPercentage(mess)
+			if e != nil {
+				err = cm.CombineErrors(cm.ErrorF("Could not get rollout of version tag '%s'.", tag), e)
+
+				return commitF, tagF, versionF, infoF, err
+			}
+
+			if percentage < 100 && strs.IsEmpty(rolloutID) { //nolint:mnd
+				rolloutID = getUpdateRolloutID(gitx)
+			}
+
+			if percentage < 100 && getRolloutBucket(rolloutID, tag) >= percentage { //nolint:mnd
+				if unskipTrailerRe.MatchString(mess) {
+					// We cannot update beyond this version until it is rolled out to us.
+					break
+				}
+
+				continue
+			}
+		}
+
 		// We have a valid new version on commit 'commit'
 		commitF = commit
 		tagF = tag
@@ -282,8 +322,13 @@ func FetchUpdates(
 		return status, err
 	}
 
+	channel, err := GetUpdateChannel(gitx)
+	if err != nil {
+		return status, err
+	}
+
 	resetRemoteTo := ""
-	status, err = getStatus(gitx, url, DefaultRemote, branch, remoteBranch, usePreRelease)
+	status, err = getStatus(gitx, url, DefaultRemote, branch, remoteBranch, usePreRelease, channel)
 
 	status.IsNewClone = isNewClone
 	if status.IsUpdateAvailable {
@@ -343,7 +388,12 @@ func GetStatus(
 
 	remoteBranch := DefaultRemote + "/" + branch
 
-	return getStatus(gitx, url, DefaultRemote, branch, remoteBranch, skipPrerelease)
+	channel, err := GetUpdateChannel(gitx)
+	if err != nil {
+		return
+	}
+
+	return getStatus(gitx, url, DefaultRemote, branch, remoteBranch, skipPrerelease, channel)
 }
 
 func getStatus(
@@ -352,7 +402,8 @@ func getStatus(
 	remoteName string,
 	branch string,
 	remoteBranch string,
-	usePreRelease bool) (status ReleaseStatus, err error) {
+	usePreRelease bool,
+	channel UpdateChannel) (status ReleaseStatus, err error) {
 	localSHA, err := gitx.Get("rev-parse", branch)
 	if err != nil {
 		return status, err
@@ -376,9 +427,10 @@ func getStatus(
 		// We have a potential update available...
 		// Get the latest update commit in the range (localSHA, remoteSHA]
 		// - Skip prerelease versions
+		// - Skip versions not allowed by the update channel
 		// - also never skip annotated (Git trailers) "non-skip" versions.
 		updateCommit, updateTag, updateVersion, updateInfo, err =
-			getNewUpdateCommit(gitx, localSHA, remoteSHA, usePreRelease)
+			getNewUpdateCommit(gitx, localSHA, remoteSHA, usePreRelease, channel)
 
 		if err != nil {
 			return status, err
@@ -399,6 +451,7 @@ func getStatus(
 		UpdateCommitSHA:   updateCommit,
 		UpdateTag:         updateTag,
 		UpdateInfo:        updateInfo,
+		UpdateChannel:     channel,
 
 		Branch:       branch,
 		RemoteBranch: remoteBranch}
@@ -575,6 +628,10 @@ func FormatUpdateText(
 		versionText += " (Major Update)"
 	}
 
+	if status.UpdateChannel.Type != StableChannel {
+		versionText += strs.Fmt("\nUpdate Channel: '%s'", status.UpdateChannel.String())
+	}
+
 	if status.UpdateInfo != nil {
 		versionText += formatUpdateInfo(status.UpdateInfo)
 	}
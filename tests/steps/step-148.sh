@@ -0,0 +1,115 @@
+#!/usr/bin/env bash
+# Test:
+#   Run CLI: simulate hooks with `git hooks run`
+
+TEST_DIR=$(cd "$(dirname "$0")/.." && pwd)
+# shellcheck disable=SC1091
+. "$TEST_DIR/general.sh"
+
+init_step
+
+accept_all_trust_prompts || exit 1
+
+"$GH_TEST_BIN/githooks-cli" installer "${EXTRA_INSTALL_ARGS[@]}" || exit 1
+
+mkdir -p "$GH_TEST_TMP/test148/server" &&
+    cd "$GH_TEST_TMP/test148/server" &&
+    git init --bare || exit 1
+
+git clone "$GH_TEST_TMP/test148/server" "$GH_TEST_TMP/test148/local" &&
+    cd "$GH_TEST_TMP/test148/local" &&
+    install_hooks_if_not_centralized &&
+    git commit --allow-empty -m "Initial" --no-verify || exit 1
+
+mkdir -p .githooks/pre-commit .githooks/pre-push || exit 1
+
+cat <<EOF >.githooks/pre-commit/test
+#!/bin/bash
+echo "pre-commit: \$*" >>"$GH_TEST_TMP/test148.out"
+EOF
+
+cat <<EOF >.githooks/pre-push/test
+#!/bin/bash
+echo "pre-push: \$*" >>"$GH_TEST_TMP/test148.out"
+while read -r localRef localSHA remoteRef remoteSHA; do
+    echo "stdin: \$localRef \$remoteRef \$remoteSHA" >>"$GH_TEST_TMP/test148.out"
+done
+while read -r localRef localSHA remoteRef remoteSHA; do
+    echo "updates: \$localRef \$remoteRef \$remoteSHA" >>"$GH_TEST_TMP/test148.out"
+done <<<"\$GITHOOKS_PUSH_UPDATES"
+EOF
+
+chmod +x .githooks/pre-commit/test .githooks/pre-push/test &&
+    git hooks trust || exit 1
+
+# A dry run does not execute any hook.
+OUT=$(git hooks run pre-commit --dry-run 2>&1) || {
+    echo "! Dry run failed:"
+    echo "$OUT"
+    exit 1
+}
+
+if ! echo "$OUT" | grep -q "Execution plan for hook 'pre-commit'" ||
+    ! echo "$OUT" | grep -q "gh-self/pre-commit/test"; then
+    echo "! Expected dry run output not found:"
+    echo "$OUT"
+    exit 1
+fi
+
+if [ -f "$GH_TEST_TMP/test148.out" ]; then
+    echo "! Dry run should not have executed the hook"
+    exit 1
+fi
+
+# The arguments are passed to the hooks.
+git hooks run pre-commit a "b c" || exit 1
+
+if ! grep -q "pre-commit: a b c" "$GH_TEST_TMP/test148.out"; then
+    echo "! Hook did not run with the arguments:"
+    cat "$GH_TEST_TMP/test148.out"
+    exit 1
+fi
+
+# The `pre-push` arguments and standard input default to pushing the current branch.
+rm -f "$GH_TEST_TMP/test148.out"
+git hooks run pre-push || exit 1
+
+ZERO="0000000000000000000000000000000000000000"
+if ! grep -q "pre-push: origin $GH_TEST_TMP/test148/server" "$GH_TEST_TMP/test148.out" ||
+    ! grep -q "stdin: refs/heads/main refs/heads/main $ZERO" "$GH_TEST_TMP/test148.out" ||
+    ! grep -q "updates: refs/heads/main refs/heads/main $ZERO" "$GH_TEST_TMP/test148.out"; then
+    echo "! Hook did not get the default push:"
+    cat "$GH_TEST_TMP/test148.out"
+    exit 1
+fi
+
+# The standard input is given by a file or by the standard input.
+rm -f "$GH_TEST_TMP/test148.out"
+SHA=$(git rev-parse HEAD)
+echo "refs/heads/main $SHA refs/heads/feature $ZERO" >"$GH_TEST_TMP/test148.stdin"
+
+git hooks run pre-push --stdin-file "$GH_TEST_TMP/test148.stdin" other url || exit 1
+git hooks run pre-push --stdin-file - other url <"$GH_TEST_TMP/test148.stdin" || exit 1
+
+if [ "$(grep -c "pre-push: other url" "$GH_TEST_TMP/test148.out")" != "2" ] ||
+    [ "$(grep -c "stdin: refs/heads/main refs/heads/feature $ZERO" "$GH_TEST_TMP/test148.out")" != "2" ] ||
+    [ "$(grep -c "updates: refs/heads/main refs/heads/feature $ZERO" "$GH_TEST_TMP/test148.out")" != "2" ]; then
+    echo "! Hook did not get the given standard input:"
+    cat "$GH_TEST_TMP/test148.out"
+    exit 1
+fi
+
+# Failing hooks fail the command.
+echo "exit 3" >>.githooks/pre-commit/test &&
+    git hooks trust || exit 1
+
+if git hooks run pre-commit; then
+    echo "! Failing hook should fail the run"
+    exit 1
+fi
+
+# Unsupported hooks are rejected.
+if git hooks run not-a-hook; then
+    echo "! Unsupported hook name should fail"
+    exit 1
+fi
@@ -0,0 +1,98 @@
+#!/usr/bin/env bash
+# Test:
+#   CI CLI: run hooks over a commit range with `git hooks ci`
+
+TEST_DIR=$(cd "$(dirname "$0")/.." && pwd)
+# shellcheck disable=SC1091
+. "$TEST_DIR/general.sh"
+
+init_step
+
+accept_all_trust_prompts || exit 1
+
+"$GH_TEST_BIN/githooks-cli" installer "${EXTRA_INSTALL_ARGS[@]}" || exit 1
+
+mkdir -p "$GH_TEST_TMP/test149/.githooks/pre-commit" &&
+    cd "$GH_TEST_TMP/test149" &&
+    git init &&
+    install_hooks_if_not_centralized || exit 1
+
+cat <<EOF >.githooks/pre-commit/test
+#!/bin/bash
+echo "run" >>"$GH_TEST_TMP/test149.out"
+for file in \$STAGED_FILES; do
+    echo "staged: \$file" >>"$GH_TEST_TMP/test149.out"
+    if [ "\$file" = "fail.txt" ]; then
+        exit 1
+    fi
+done
+EOF
+
+chmod +x .githooks/pre-commit/test &&
+    git add .githooks &&
+    git commit -m "Hooks" --no-verify &&
+    git tag base || exit 1
+
+echo "A" >a.txt && git add a.txt && git commit -m "A" --no-verify || exit 1
+echo "B" >b.txt && git add b.txt && git commit -m "B" --no-verify || exit 1
+
+# Untrusted hooks are skipped and do not fail the run.
+git hooks ci --from base || exit 1
+
+if [ -f "$GH_TEST_TMP/test149.out" ]; then
+    echo "! Untrusted hook should not have run"
+    exit 1
+fi
+
+git hooks trust || exit 1
+
+# All changed files of the range are passed to one run.
+git hooks ci --from base || exit 1
+
+if [ "$(grep -c "run" "$GH_TEST_TMP/test149.out")" != "1" ] ||
+    ! grep -q "staged: a.txt" "$GH_TEST_TMP/test149.out" ||
+    ! grep -q "staged: b.txt" "$GH_TEST_TMP/test149.out" ||
+    grep -q "staged: .githooks" "$GH_TEST_TMP/test149.out"; then
+    echo "! Hook did not get the changed files of the range:"
+    cat "$GH_TEST_TMP/test149.out"
+    exit 1
+fi
+
+# Each commit is run with its own changed files.
+rm -f "$GH_TEST_TMP/test149.out"
+git hooks ci --from base --to HEAD --per-commit || exit 1
+
+if [ "$(grep -c "run" "$GH_TEST_TMP/test149.out")" != "2" ] ||
+    [ "$(grep -c "staged: " "$GH_TEST_TMP/test149.out")" != "2" ]; then
+    echo "! Hook did not run for each commit:"
+    cat "$GH_TEST_TMP/test149.out"
+    exit 1
+fi
+
+# A failing hook fails the command but all runs are executed.
+echo "F" >fail.txt && git add fail.txt && git commit -m "F" --no-verify || exit 1
+echo "C" >c.txt && git add c.txt && git commit -m "C" --no-verify || exit 1
+
+rm -f "$GH_TEST_TMP/test149.out"
+if git hooks ci --from base --per-commit; then
+    echo "! Failing hook should fail the CI run"
+    exit 1
+fi
+
+if [ "$(grep -c "run" "$GH_TEST_TMP/test149.out")" != "4" ] ||
+    ! grep -q "staged: c.txt" "$GH_TEST_TMP/test149.out"; then
+    echo "! Not all commits have been run:"
+    cat "$GH_TEST_TMP/test149.out"
+    exit 1
+fi
+
+# Only the given range is run.
+rm -f "$GH_TEST_TMP/test149.out"
+git hooks ci --from HEAD~1 || exit 1
+
+if ! grep -q "staged: c.txt" "$GH_TEST_TMP/test149.out" ||
+    grep -q "staged: fail.txt" "$GH_TEST_TMP/test149.out"; then
+    echo "! Hook did not get the changed files of the range:"
+    cat "$GH_TEST_TMP/test149.out"
+    exit 1
+fi
@@ -0,0 +1,125 @@
+#!/usr/bin/env bash
+# Test:
+#   Server mode: untrusted hooks reject pushes and the pushed updates are exported
+
+TEST_DIR=$(cd "$(dirname "$0")/.." && pwd)
+# shellcheck disable=SC1091
+. "$TEST_DIR/general.sh"
+
+init_step
+
+accept_all_trust_prompts || exit 1
+
+git config --global githooks.testingTreatFileProtocolAsRemote "true"
+
+"$GH_TEST_BIN/githooks-cli" installer "${EXTRA_INSTALL_ARGS[@]}" || exit 1
+
+mkdir -p "$GH_TEST_TMP/test150/hooks" &&
+    mkdir -p "$GH_TEST_TMP/test150/server" || exit 1
+
+# Hooks
+cd "$GH_TEST_TMP/test150/hooks" &&
+    git init &&
+    mkdir -p .githooks/pre-receive .githooks/update || exit 1
+
+cat <<EOF >.githooks/pre-receive/test
+#!/bin/bash
+echo "pre-receive" >>"$GH_TEST_TMP/test150.out"
+while read -r old new ref; do
+    echo "stdin: \$ref" >>"$GH_TEST_TMP/test150.out"
+done
+while read -r old new ref; do
+    echo "updates: \$ref" >>"$GH_TEST_TMP/test150.out"
+done <<<"\$GITHOOKS_REF_UPDATES"
+while IFS=\$'\\t' read -r ref file; do
+    echo "changed: \$ref \$file" >>"$GH_TEST_TMP/test150.out"
+done <"\$GITHOOKS_CHANGED_FILES_FILE"
+EOF
+
+cat <<EOF >.githooks/update/test
+#!/bin/bash
+echo "update: \$1" >>"$GH_TEST_TMP/test150.out"
+while read -r old new ref; do
+    echo "updates: \$ref" >>"$GH_TEST_TMP/test150.out"
+done <<<"\$GITHOOKS_REF_UPDATES"
+EOF
+
+chmod +x .githooks/pre-receive/test .githooks/update/test &&
+    git add .githooks &&
+    git commit -m "Hooks" || exit 1
+"$GH_INSTALL_BIN_DIR/githooks-cli" config disable --set || exit 1
+
+# Server
+cd "$GH_TEST_TMP/test150/server" &&
+    git init --bare &&
+    install_hooks_if_not_centralized &&
+    "$GH_INSTALL_BIN_DIR/githooks-cli" shared add file://"$GH_TEST_TMP/test150/hooks" &&
+    "$GH_INSTALL_BIN_DIR/githooks-cli" shared update &&
+    "$GH_INSTALL_BIN_DIR/githooks-cli" config server-mode --enable || exit 1
+
+"$GH_INSTALL_BIN_DIR/githooks-cli" config server-mode --print | grep -q "enabled" || {
+    echo "! Server mode should be enabled"
+    exit 1
+}
+
+# Repo
+git clone "$GH_TEST_TMP/test150/server" "$GH_TEST_TMP/test150/local" &&
+    cd "$GH_TEST_TMP/test150/local" &&
+    "$GH_INSTALL_BIN_DIR/githooks-cli" config disable --set &&
+    mkdir -p dir &&
+    echo "A" >dir/a.txt &&
+    echo "B" >b.txt &&
+    git add dir/a.txt b.txt &&
+    git commit -m "First" || exit 1
+
+# Untrusted hooks reject the push without prompting.
+if git push origin main; then
+    echo "! Push should have been rejected by untrusted hooks"
+    exit 1
+fi
+
+if [ -f "$GH_TEST_TMP/test150.out" ]; then
+    echo "! Untrusted hooks should not have run"
+    exit 1
+fi
+
+if [ -n "$(git -C "$GH_TEST_TMP/test150/server" branch --list main)" ]; then
+    echo "! Rejected push should not have created the branch"
+    exit 1
+fi
+
+# Trusted hooks get the pushed updates.
+cd "$GH_TEST_TMP/test150/server" &&
+    "$GH_INSTALL_BIN_DIR/githooks-cli" trust hooks --all || exit 1
+
+cd "$GH_TEST_TMP/test150/local" &&
+    git push origin main || exit 1
+
+if ! grep -q "pre-receive" "$GH_TEST_TMP/test150.out" ||
+    ! grep -q "stdin: refs/heads/main" "$GH_TEST_TMP/test150.out" ||
+    ! grep -q "update: refs/heads/main" "$GH_TEST_TMP/test150.out" ||
+    [ "$(grep -c "updates: refs/heads/main" "$GH_TEST_TMP/test150.out")" != "2" ] ||
+    ! grep -q "changed: refs/heads/main dir/a.txt" "$GH_TEST_TMP/test150.out" ||
+    ! grep -q "changed: refs/heads/main b.txt" "$GH_TEST_TMP/test150.out"; then
+    echo "! Hooks did not get the pushed updates:"
+    cat "$GH_TEST_TMP/test150.out"
+    exit 1
+fi
+
+# Only the files changed by the push are listed.
+rm -f "$GH_TEST_TMP/test150.out"
+echo "C" >>b.txt &&
+    git commit -a -m "Second" &&
+    git push origin main || exit 1
+
+if ! grep -q "changed: refs/heads/main b.txt" "$GH_TEST_TMP/test150.out" ||
+    grep -q "changed: refs/heads/main dir/a.txt" "$GH_TEST_TMP/test150.out"; then
+    echo "! Hooks did not get the changed files of the push:"
+    cat "$GH_TEST_TMP/test150.out"
+    exit 1
+fi
+
+if [ "$(find "$GH_TEST_TMP/test150/server" -name "*githooks-changed-files*" | wc -l)" != 0 ]; then
+    echo "! The changed files file is not deleted!"
+    exit 1
+fi
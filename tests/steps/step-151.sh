@@ -0,0 +1,114 @@
+#!/usr/bin/env bash
+# Test:
+#   Pre-push: export the pushed references and the pushed files
+
+TEST_DIR=$(cd "$(dirname "$0")/.." && pwd)
+# shellcheck disable=SC1091
+. "$TEST_DIR/general.sh"
+
+init_step
+
+accept_all_trust_prompts || exit 1
+
+"$GH_TEST_BIN/githooks-cli" installer "${EXTRA_INSTALL_ARGS[@]}" || exit 1
+
+mkdir -p "$GH_TEST_TMP/test151/server" &&
+    cd "$GH_TEST_TMP/test151/server" &&
+    git init --bare || exit 1
+
+git clone "$GH_TEST_TMP/test151/server" "$GH_TEST_TMP/test151/local" &&
+    cd "$GH_TEST_TMP/test151/local" &&
+    install_hooks_if_not_centralized &&
+    mkdir -p .githooks/pre-push || exit 1
+
+cat <<EOF >.githooks/pre-push/test
+#!/bin/bash
+echo "pre-push: \$1" >>"$GH_TEST_TMP/test151.out"
+while read -r localRef localSHA remoteRef remoteSHA; do
+    echo "updates: \$localRef \$remoteRef" >>"$GH_TEST_TMP/test151.out"
+done <<<"\$GITHOOKS_PUSH_UPDATES"
+while read -rd \$'\\0' file; do
+    echo "pushed: \$file" >>"$GH_TEST_TMP/test151.out"
+done <"\$GITHOOKS_PUSHED_FILES_FILE"
+EOF
+
+chmod +x .githooks/pre-push/test &&
+    git add .githooks &&
+    git commit -m "Hooks" --no-verify &&
+    git hooks trust || exit 1
+
+mkdir -p dir &&
+    echo "A" >"dir/a b.txt" &&
+    echo "B" >b.txt &&
+    git add . &&
+    git commit -m "First" --no-verify || exit 1
+
+# A new branch pushes all files not on the remote.
+git push origin main || exit 1
+
+if ! grep -q "pre-push: origin" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "updates: refs/heads/main refs/heads/main" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "pushed: dir/a b.txt" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "pushed: b.txt" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "pushed: .githooks/pre-push/test" "$GH_TEST_TMP/test151.out"; then
+    echo "! Hook did not get the pushed references:"
+    cat "$GH_TEST_TMP/test151.out"
+    exit 1
+fi
+
+# Only the files changed since the remote commit are pushed.
+rm -f "$GH_TEST_TMP/test151.out"
+echo "C" >>b.txt &&
+    git commit -a -m "Second" --no-verify &&
+    git push origin main || exit 1
+
+if [ "$(grep -c "pushed: " "$GH_TEST_TMP/test151.out")" != "1" ] ||
+    ! grep -q "pushed: b.txt" "$GH_TEST_TMP/test151.out"; then
+    echo "! Hook did not get the files changed since the remote commit:"
+    cat "$GH_TEST_TMP/test151.out"
+    exit 1
+fi
+
+# A new branch only pushes the files of commits not on the remote.
+rm -f "$GH_TEST_TMP/test151.out"
+git checkout -b feature &&
+    echo "D" >d.txt &&
+    git add d.txt &&
+    git commit -m "Third" --no-verify &&
+    git push origin feature || exit 1
+
+if [ "$(grep -c "pushed: " "$GH_TEST_TMP/test151.out")" != "1" ] ||
+    ! grep -q "updates: refs/heads/feature refs/heads/feature" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "pushed: d.txt" "$GH_TEST_TMP/test151.out"; then
+    echo "! Hook did not get the files of the new branch:"
+    cat "$GH_TEST_TMP/test151.out"
+    exit 1
+fi
+
+# Pushing to an URL uses all remote-tracking branches.
+rm -f "$GH_TEST_TMP/test151.out"
+git push "$GH_TEST_TMP/test151/server" feature:other || exit 1
+
+if ! grep -q "pre-push: $GH_TEST_TMP/test151/server" "$GH_TEST_TMP/test151.out" ||
+    ! grep -q "updates: refs/heads/feature refs/heads/other" "$GH_TEST_TMP/test151.out" ||
+    grep -q "pushed: " "$GH_TEST_TMP/test151.out"; then
+    echo "! Hook did not get the pushed references to the URL:"
+    cat "$GH_TEST_TMP/test151.out"
+    exit 1
+fi
+
+# Deleted references have no pushed files.
+rm -f "$GH_TEST_TMP/test151.out"
+git push origin --delete feature || exit 1
+
+if ! grep -q "updates: (delete) refs/heads/feature" "$GH_TEST_TMP/test151.out" ||
+    grep -q "pushed: " "$GH_TEST_TMP/test151.out"; then
+    echo "! Hook did not get the deleted reference:"
+    cat "$GH_TEST_TMP/test151.out"
+    exit 1
+fi
+
+if [ "$(find "$GH_TEST_TMP/test151/local" -name "*githooks-pushed-files*" | wc -l)" != 0 ]; then
+    echo "! The pushed files file is not deleted!"
+    exit 1
+fi
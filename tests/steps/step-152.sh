@@ -0,0 +1,86 @@
+#!/usr/bin/env bash
+# Test:
+#   Opt-in hooks: run a single `fsmonitor-watchman` hook with its output passed through
+
+TEST_DIR=$(cd "$(dirname "$0")/.." && pwd)
+# shellcheck disable=SC1091
+. "$TEST_DIR/general.sh"
+
+init_step
+
+accept_all_trust_prompts || exit 1
+
+"$GH_TEST_BIN/githooks-cli" installer "${EXTRA_INSTALL_ARGS[@]}" \
+    --maintained-hooks "all, fsmonitor-watchman" || exit 1
+
+mkdir -p "$GH_TEST_TMP/test152/.githooks/fsmonitor-watchman" &&
+    cd "$GH_TEST_TMP/test152" &&
+    git init &&
+    install_hooks_if_not_centralized || exit 1
+
+WRAPPER=$(git rev-parse --git-path hooks/fsmonitor-watchman) &&
+    WRAPPER=$(cd "$(dirname "$WRAPPER")" && pwd)/$(basename "$WRAPPER") || exit 1
+
+if [ ! -f "$WRAPPER" ]; then
+    echo "! Opt-in hook 'fsmonitor-watchman' is not installed"
+    exit 1
+fi
+
+if [ -f "$(git rev-parse --git-path hooks/proc-receive)" ]; then
+    echo "! Opt-in hook 'proc-receive' should not be installed"
+    exit 1
+fi
+
+cat <<EOF >.githooks/fsmonitor-watchman/watch
+#!/bin/bash
+echo "fsmonitor: \$*" >>"$GH_TEST_TMP/test152.out"
+printf 'token-1\\0/\\0'
+EOF
+
+chmod +x .githooks/fsmonitor-watchman/watch &&
+    git hooks trust || exit 1
+
+echo "A" >a.txt &&
+    git add a.txt &&
+    git commit -m "First" || exit 1
+
+# Git only runs the hook if the monitor is configured.
+git status || exit 1
+if [ -f "$GH_TEST_TMP/test152.out" ]; then
+    echo "! Hook should not run without 'core.fsmonitor'"
+    exit 1
+fi
+
+git config core.fsmonitor "$WRAPPER" &&
+    git status || exit 1
+
+if ! grep -q "fsmonitor: 2 " "$GH_TEST_TMP/test152.out"; then
+    echo "! Hook did not run with the protocol version:"
+    cat "$GH_TEST_TMP/test152.out"
+    exit 1
+fi
+
+# The standard output of the hook is passed through unchanged:
+# Git stores the returned token and passes it to the next run.
+git status || exit 1
+if ! grep -q "fsmonitor: 2 token-1" "$GH_TEST_TMP/test152.out"; then
+    echo "! Output of the hook is not passed through to Git:"
+    cat "$GH_TEST_TMP/test152.out"
+    exit 1
+fi
+
+# Only a single hook is allowed.
+cp .githooks/fsmonitor-watchman/watch .githooks/fsmonitor-watchman/watch2 &&
+    git hooks trust || exit 1
+
+OUT=$(git hooks run fsmonitor-watchman 2 token-0 2>&1)
+# shellcheck disable=SC2181
+if [ $? -eq 0 ] || ! echo "$OUT" | grep -q "all but one hook"; then
+    echo "! Several hooks for 'fsmonitor-watchman' should fail:"
+    echo "$OUT"
+    exit 1
+fi
+
+# Ignoring all but one hook works again.
+git hooks ignore add --pattern "fsmonitor-watchman/watch2" || exit 1
+git hooks run fsmonitor-watchman 2 token-0 >/dev/null || exit 1